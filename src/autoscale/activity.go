@@ -1,56 +1,287 @@
 package autoscale
 
-import "fmt"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ActivityKind is the kind of a SchedulerActivity.
+type ActivityKind string
+
+const (
+	// ActivityEvaluated is the kind of activities for actions which left a group's size
+	// unchanged.
+	ActivityEvaluated ActivityKind = "evaluated"
+
+	// ActivityScaled is the kind of activities for actions which changed a group's size.
+	ActivityScaled ActivityKind = "scaled"
+
+	// ActivityFailed is the kind of activities for actions which returned an error, or
+	// which couldn't run.
+	ActivityFailed ActivityKind = "failed"
+
+	// ActivityDisabled is the kind of activities for groups which were disabled.
+	ActivityDisabled ActivityKind = "disabled"
+
+	// ActivityEnabled is the kind of activities for disabled groups which were enabled
+	// again.
+	ActivityEnabled ActivityKind = "enabled"
+
+	// ActivityTimedOut is the kind of activities for actions which didn't finish in
+	// time.
+	ActivityTimedOut ActivityKind = "timed-out"
+)
+
+// OverflowPolicy decides what happens to an activity when a listener's queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops activities which don't fit in the listener's queue.
+	OverflowDrop OverflowPolicy = iota
+
+	// OverflowBlock makes the ActivityManager wait until the listener's queue has room,
+	// which holds up every other listener and the scheduler.
+	OverflowBlock
+
+	// OverflowBuffer keeps activities which don't fit in the listener's queue in memory
+	// until the listener catches up, so nothing is dropped and nothing waits. The backlog
+	// isn't bounded, so it suits listeners of infrequent kinds of activity.
+	OverflowBuffer
+)
+
+// DefaultListenerBuffer is the size of a listener's queue if its options don't set one.
+const DefaultListenerBuffer = 100
+
+var activityDropped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "autoscale",
+		Subsystem: "activity",
+		Name:      "dropped_total",
+		Help:      "Scheduler activities dropped because a listener's queue was full.",
+	},
+	[]string{"listener", "kind"},
+)
+
+func init() {
+	prometheus.MustRegister(activityDropped)
+}
 
 // ActivityListener is an entity interested in listening for SchedulerActivity messages.
 type ActivityListener chan SchedulerActivity
 
+// ListenerOptions configure how activities are delivered to a listener.
+type ListenerOptions struct {
+	// Name identifies the listener in metrics.
+	Name string
+
+	// Kinds are the kinds of activity the listener receives. A listener without kinds
+	// receives every activity.
+	Kinds []ActivityKind
+
+	// Buffer is the size of the listener's queue.
+	Buffer int
+
+	// Overflow is what happens to activities when the queue is full.
+	Overflow OverflowPolicy
+}
+
+// subscription is a listener, the kinds of activity it receives, and the queue
+// activities wait in until the listener takes them.
+type subscription struct {
+	listener ActivityListener
+	name     string
+	kinds    map[ActivityKind]bool
+	overflow OverflowPolicy
+
+	queue   chan SchedulerActivity
+	done    chan struct{}
+	stopped chan struct{}
+
+	// backlog holds activities which didn't fit in the queue of an OverflowBuffer
+	// listener, oldest first. wake tells deliver there is a backlog.
+	mu      sync.Mutex
+	backlog []SchedulerActivity
+	wake    chan struct{}
+}
+
+func newSubscription(ch ActivityListener, opts ListenerOptions) *subscription {
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = DefaultListenerBuffer
+	}
+
+	s := &subscription{
+		listener: ch,
+		name:     opts.Name,
+		kinds:    map[ActivityKind]bool{},
+		overflow: opts.Overflow,
+		queue:    make(chan SchedulerActivity, buffer),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+	}
+
+	for _, k := range opts.Kinds {
+		s.kinds[k] = true
+	}
+
+	return s
+}
+
+func (s *subscription) accepts(kind ActivityKind) bool {
+	return len(s.kinds) == 0 || s.kinds[kind]
+}
+
+// enqueue queues msg for the listener, applying the overflow policy if the queue is
+// full.
+func (s *subscription) enqueue(msg SchedulerActivity) {
+	switch s.overflow {
+	case OverflowBlock:
+		select {
+		case s.queue <- msg:
+		case <-s.done:
+		}
+		return
+	case OverflowBuffer:
+		s.buffer(msg)
+		return
+	}
+
+	select {
+	case s.queue <- msg:
+	case <-s.done:
+	default:
+		activityDropped.WithLabelValues(s.name, string(msg.Kind)).Inc()
+	}
+}
+
+// buffer queues msg, or adds it to the backlog if the queue is full or activities are
+// already waiting in the backlog.
+func (s *subscription) buffer(msg SchedulerActivity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.backlog) == 0 {
+		select {
+		case s.queue <- msg:
+			return
+		default:
+		}
+	}
+
+	s.backlog = append(s.backlog, msg)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// refill moves activities from the backlog to the queue while it has room.
+func (s *subscription) refill() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.backlog) > 0 {
+		select {
+		case s.queue <- s.backlog[0]:
+			s.backlog = s.backlog[1:]
+		default:
+			return
+		}
+	}
+}
+
+// deliver passes queued activities to the listener until the subscription is stopped.
+func (s *subscription) deliver() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case msg := <-s.queue:
+			select {
+			case s.listener <- msg:
+			case <-s.done:
+				return
+			}
+			s.refill()
+		case <-s.wake:
+			s.refill()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// stop ends delivery, and waits until nothing will be sent to the listener.
+func (s *subscription) stop() {
+	close(s.done)
+	<-s.stopped
+}
+
 // ActivityManager collects events from Schedule, and fans them out to multiple listerns.
+// Each listener has its own queue, so a slow listener doesn't hold up the others.
 type ActivityManager struct {
 	activityChan chan SchedulerActivity
-	listeners    []ActivityListener
+
+	mu            sync.Mutex
+	subscriptions []*subscription
 }
 
 // NewActivityManager creates an instance of ActivityManager.
 func NewActivityManager(activityChan chan SchedulerActivity) *ActivityManager {
 	return &ActivityManager{
-		activityChan: activityChan,
-		listeners:    []ActivityListener{},
+		activityChan:  activityChan,
+		subscriptions: []*subscription{},
 	}
 }
 
-// RegisterListener registers ActivityListeners.
-func (a *ActivityManager) RegisterListener(ch ActivityListener) {
-	a.listeners = append(a.listeners, ch)
-}
+// RegisterListener registers an ActivityListener, which receives activities until it
+// is unregistered.
+func (a *ActivityManager) RegisterListener(ch ActivityListener, opts ListenerOptions) {
+	s := newSubscription(ch, opts)
+	go s.deliver()
 
-// Start starts the fanout process.
-func (a *ActivityManager) Start() {
-	for {
-		select {
-		case msg := <-a.activityChan:
-			newListeners := []ActivityListener{}
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-			for _, ch := range a.listeners {
-				if err := a.send(msg, ch); err == nil {
-					newListeners = append(newListeners, ch)
-				}
-			}
+	a.subscriptions = append(a.subscriptions, s)
+}
 
-			a.listeners = newListeners
+// UnregisterListener stops sending activities to an ActivityListener. Activities still
+// queued for it are discarded. Once it returns, the listener may be closed.
+func (a *ActivityManager) UnregisterListener(ch ActivityListener) {
+	a.mu.Lock()
+	subscriptions := []*subscription{}
+	var removed []*subscription
+	for _, s := range a.subscriptions {
+		if s.listener == ch {
+			removed = append(removed, s)
+			continue
 		}
+
+		subscriptions = append(subscriptions, s)
+	}
+	a.subscriptions = subscriptions
+	a.mu.Unlock()
+
+	for _, s := range removed {
+		s.stop()
 	}
 }
 
-func (a *ActivityManager) send(msg SchedulerActivity, l ActivityListener) error {
-	var err error
+// Start starts the fanout process. It returns once the activity channel is closed.
+func (a *ActivityManager) Start() {
+	for msg := range a.activityChan {
+		a.mu.Lock()
+		subscriptions := a.subscriptions
+		a.mu.Unlock()
 
-	defer func() {
-		if x := recover(); x != nil {
-			err = fmt.Errorf("send to closed listener: %v", x)
+		for _, s := range subscriptions {
+			if s.accepts(msg.Kind) {
+				s.enqueue(msg)
+			}
 		}
-	}()
-
-	l <- msg
-	return err
+	}
 }
@@ -2,21 +2,30 @@ package autoscale
 
 import (
 	"testing"
+	"time"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 )
 
+func droppedActivities(t *testing.T, listener string, kind ActivityKind) float64 {
+	var m dto.Metric
+	require.NoError(t, activityDropped.WithLabelValues(listener, string(kind)).Write(&m))
+	return m.GetCounter().GetValue()
+}
+
 func TestActivityManager(t *testing.T) {
 	activityChan := make(chan SchedulerActivity, 1)
 
 	am := NewActivityManager(activityChan)
 
 	l1 := make(chan SchedulerActivity, 1)
-	am.RegisterListener(l1)
+	am.RegisterListener(l1, ListenerOptions{Name: "l1"})
 	l2 := make(chan SchedulerActivity, 1)
-	am.RegisterListener(l2)
+	am.RegisterListener(l2, ListenerOptions{Name: "l2"})
 
 	go am.Start()
+	defer close(activityChan)
 
 	in := SchedulerActivity{ID: "id"}
 	activityChan <- in
@@ -28,9 +37,175 @@ func TestActivityManager(t *testing.T) {
 	out = <-l2
 	require.Equal(t, in, out)
 
+	am.UnregisterListener(l1)
 	close(l1)
 
 	activityChan <- in
 	out = <-l2
 	require.Equal(t, in, out)
 }
+
+func TestActivityManager_Kinds(t *testing.T) {
+	activityChan := make(chan SchedulerActivity, 1)
+
+	am := NewActivityManager(activityChan)
+
+	failures := make(chan SchedulerActivity, 2)
+	am.RegisterListener(failures, ListenerOptions{Kinds: []ActivityKind{ActivityFailed, ActivityTimedOut}})
+	all := make(chan SchedulerActivity, 2)
+	am.RegisterListener(all, ListenerOptions{})
+
+	go am.Start()
+	defer close(activityChan)
+
+	evaluated := SchedulerActivity{ID: "id", Kind: ActivityEvaluated}
+	failed := SchedulerActivity{ID: "id", Kind: ActivityFailed}
+	activityChan <- evaluated
+	activityChan <- failed
+
+	require.Equal(t, evaluated, <-all)
+	require.Equal(t, failed, <-all)
+	require.Equal(t, failed, <-failures)
+	require.Len(t, failures, 0)
+}
+
+func TestActivityManager_SlowListenerDrops(t *testing.T) {
+	activityChan := make(chan SchedulerActivity)
+
+	am := NewActivityManager(activityChan)
+
+	slow := make(chan SchedulerActivity)
+	am.RegisterListener(slow, ListenerOptions{Name: "slow-drop", Buffer: 1})
+	fast := make(chan SchedulerActivity, 10)
+	am.RegisterListener(fast, ListenerOptions{Name: "fast-drop"})
+
+	go am.Start()
+	defer close(activityChan)
+
+	// nothing reads from slow, so at most one activity waits to be delivered and one
+	// is queued. The rest are dropped without holding up the scheduler or fast.
+	for i := 0; i < 5; i++ {
+		activityChan <- SchedulerActivity{ID: "id", Kind: ActivityScaled, Count: i}
+	}
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, i, (<-fast).Count)
+	}
+
+	received := []int{}
+	for done := false; !done; {
+		select {
+		case msg := <-slow:
+			received = append(received, msg.Count)
+		case <-time.After(50 * time.Millisecond):
+			done = true
+		}
+	}
+
+	require.NotEmpty(t, received)
+	require.True(t, len(received) <= 2, "received %v", received)
+	require.Equal(t, 0, received[0])
+	require.Equal(t, float64(5-len(received)), droppedActivities(t, "slow-drop", ActivityScaled))
+	require.Equal(t, float64(0), droppedActivities(t, "fast-drop", ActivityScaled))
+
+	am.UnregisterListener(slow)
+	am.UnregisterListener(fast)
+}
+
+func TestActivityManager_SlowListenerBlocks(t *testing.T) {
+	activityChan := make(chan SchedulerActivity)
+
+	am := NewActivityManager(activityChan)
+
+	slow := make(chan SchedulerActivity)
+	am.RegisterListener(slow, ListenerOptions{Name: "slow-block", Buffer: 1, Overflow: OverflowBlock})
+
+	go am.Start()
+	defer close(activityChan)
+
+	activityChan <- SchedulerActivity{Count: 0}
+	activityChan <- SchedulerActivity{Count: 1}
+
+	sent := make(chan bool)
+	go func() {
+		activityChan <- SchedulerActivity{Count: 2}
+		activityChan <- SchedulerActivity{Count: 3}
+		sent <- true
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("expected the manager to wait for the listener")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, i, (<-slow).Count)
+	}
+	<-sent
+
+	require.Equal(t, float64(0), droppedActivities(t, "slow-block", ""))
+}
+
+func TestActivityManager_SlowListenerBuffers(t *testing.T) {
+	activityChan := make(chan SchedulerActivity)
+
+	am := NewActivityManager(activityChan)
+
+	slow := make(chan SchedulerActivity)
+	am.RegisterListener(slow, ListenerOptions{Name: "slow-buffer", Buffer: 1, Overflow: OverflowBuffer})
+
+	go am.Start()
+	defer close(activityChan)
+
+	sent := make(chan bool)
+	go func() {
+		for i := 0; i < 5; i++ {
+			activityChan <- SchedulerActivity{Kind: ActivityScaled, Count: i}
+		}
+		sent <- true
+	}()
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("expected the manager not to wait for the listener")
+	}
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, i, (<-slow).Count)
+	}
+
+	activityChan <- SchedulerActivity{Kind: ActivityScaled, Count: 5}
+	require.Equal(t, 5, (<-slow).Count)
+
+	require.Equal(t, float64(0), droppedActivities(t, "slow-buffer", ActivityScaled))
+
+	am.UnregisterListener(slow)
+}
+
+func TestActivityManager_UnregisterBlockedListener(t *testing.T) {
+	activityChan := make(chan SchedulerActivity)
+
+	am := NewActivityManager(activityChan)
+
+	gone := make(chan SchedulerActivity)
+	am.RegisterListener(gone, ListenerOptions{Buffer: 1, Overflow: OverflowBlock})
+	other := make(chan SchedulerActivity, 10)
+	am.RegisterListener(other, ListenerOptions{})
+
+	go am.Start()
+	defer close(activityChan)
+
+	for i := 0; i < 3; i++ {
+		activityChan <- SchedulerActivity{Count: i}
+	}
+
+	am.UnregisterListener(gone)
+	close(gone)
+
+	activityChan <- SchedulerActivity{Count: 3}
+	for i := 0; i < 4; i++ {
+		require.Equal(t, i, (<-other).Count)
+	}
+}
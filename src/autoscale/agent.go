@@ -0,0 +1,207 @@
+package autoscale
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// AgentType is how the monitoring agent is installed on droplets.
+type AgentType string
+
+const (
+	// AgentUpstart installs node_exporter as an upstart job.
+	AgentUpstart AgentType = "upstart"
+	// AgentSystemd installs node_exporter as a systemd service.
+	AgentSystemd AgentType = "systemd"
+	// AgentCustom runs a user supplied script to install the agent.
+	AgentCustom AgentType = "custom"
+	// AgentNone doesn't install an agent. The image already runs an exporter.
+	AgentNone AgentType = "none"
+
+	// DefaultAgentVersion is the node_exporter version installed by the built-in agents.
+	DefaultAgentVersion = "0.12.0"
+
+	// DefaultAgentPort is the port prometheus scrapes the agent on.
+	DefaultAgentPort = 9100
+
+	// AgentPortTagPrefix is the prefix of the tag which records the port a droplet's agent
+	// listens on.
+	AgentPortTagPrefix = "autoscale:agent-port:"
+)
+
+var agentVersionRe = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// Agent is the monitoring agent bootstrap for droplets built from a template. The zero
+// value installs DefaultAgentVersion with upstart on DefaultAgentPort.
+type Agent struct {
+	Type    AgentType `json:"type,omitempty"`
+	Version string    `json:"version,omitempty"`
+	Port    int       `json:"port,omitempty"`
+	Script  string    `json:"script,omitempty"`
+}
+
+// WithDefaults returns a copy of the agent with unset fields set to their defaults.
+func (a Agent) WithDefaults() Agent {
+	if a.Type == "" {
+		a.Type = AgentUpstart
+	}
+
+	if a.Version == "" {
+		a.Version = DefaultAgentVersion
+	}
+
+	if a.Port == 0 {
+		a.Port = DefaultAgentPort
+	}
+
+	return a
+}
+
+// Validate returns the agent's invalid fields.
+func (a Agent) Validate() []TemplateFieldError {
+	errs := []TemplateFieldError{}
+	a = a.WithDefaults()
+
+	switch a.Type {
+	case AgentUpstart, AgentSystemd:
+		if !agentVersionRe.MatchString(a.Version) {
+			errs = append(errs, TemplateFieldError{Field: "agent", Detail: fmt.Sprintf("version %q is not valid", a.Version)})
+		}
+	case AgentCustom:
+		if strings.TrimSpace(a.Script) == "" {
+			errs = append(errs, TemplateFieldError{Field: "agent", Detail: "custom agent requires a script"})
+		}
+	case AgentNone:
+	default:
+		errs = append(errs, TemplateFieldError{Field: "agent", Detail: fmt.Sprintf("type %q is not valid", a.Type)})
+	}
+
+	if a.Port < 1 || a.Port > 65535 {
+		errs = append(errs, TemplateFieldError{Field: "agent", Detail: fmt.Sprintf("port %d is not valid", a.Port)})
+	}
+
+	return errs
+}
+
+// Bootstrap returns the script which installs the agent. It returns an empty string if
+// no agent is installed.
+func (a Agent) Bootstrap() (string, error) {
+	a = a.WithDefaults()
+
+	var tmpl *template.Template
+	switch a.Type {
+	case AgentUpstart:
+		tmpl = upstartAgentTemplate
+	case AgentSystemd:
+		tmpl = systemdAgentTemplate
+	case AgentCustom:
+		return a.Script, nil
+	case AgentNone:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown agent type %q", a.Type)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, a); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Value converts an agent to JSON to be stored in the database.
+func (a Agent) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan converts a DB value back into an Agent.
+func (a *Agent) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, a)
+}
+
+func agentPortTag(port int) string {
+	return fmt.Sprintf("%s%d", AgentPortTagPrefix, port)
+}
+
+// agentPortFromTags returns the port a droplet's agent listens on. It returns
+// DefaultAgentPort if the droplet doesn't have an agent port tag.
+func agentPortFromTags(tags []string) int {
+	for _, t := range tags {
+		if !strings.HasPrefix(t, AgentPortTagPrefix) {
+			continue
+		}
+
+		port, err := strconv.Atoi(strings.TrimPrefix(t, AgentPortTagPrefix))
+		if err != nil {
+			continue
+		}
+
+		return port
+	}
+
+	return DefaultAgentPort
+}
+
+const agentInstall = `#!/usr/bin/env bash
+set -e
+
+version={{.Version}}
+name=node_exporter-${version}.linux-amd64
+releases=https://github.com/prometheus/node_exporter/releases/download
+
+# older releases aren't prefixed with v
+curl -f -s -L -o /tmp/${name}.tar.gz ${releases}/v${version}/${name}.tar.gz ||
+  curl -f -s -L -o /tmp/${name}.tar.gz ${releases}/${version}/${name}.tar.gz
+
+# older tarballs contain the binary at their root, newer ones in a ${name} directory
+mkdir -p /opt/${name}
+tar -C /opt/${name} -xzf /tmp/${name}.tar.gz
+binary=$(find /opt/${name} -type f -name node_exporter | head -n 1)
+ln -sf ${binary} /usr/local/bin/node_exporter
+`
+
+var (
+	upstartAgentTemplate = template.Must(template.New("upstart").Parse(agentInstall + `
+cat > /etc/init/node_exporter.conf <<'CONF'
+description "prometheus node exporter"
+start on runlevel [2345]
+stop on runlevel [!2345]
+respawn
+exec /usr/local/bin/node_exporter --web.listen-address=:{{.Port}}
+CONF
+
+start node_exporter
+`))
+
+	systemdAgentTemplate = template.Must(template.New("systemd").Parse(agentInstall + `
+cat > /etc/systemd/system/node_exporter.service <<'CONF'
+[Unit]
+Description=prometheus node exporter
+After=network.target
+
+[Service]
+ExecStart=/usr/local/bin/node_exporter --web.listen-address=:{{.Port}}
+Restart=always
+
+[Install]
+WantedBy=multi-user.target
+CONF
+
+systemctl daemon-reload
+systemctl enable node_exporter
+systemctl start node_exporter
+`))
+)
@@ -0,0 +1,68 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_Bootstrap(t *testing.T) {
+	cases := []struct {
+		name     string
+		agent    Agent
+		contains []string
+	}{
+		{
+			name:     "default",
+			agent:    Agent{},
+			contains: []string{"version=0.12.0", "tar -C /opt/${name} -xzf", "/etc/init/node_exporter.conf", "--web.listen-address=:9100"},
+		},
+		{
+			name:     "systemd",
+			agent:    Agent{Type: AgentSystemd, Version: "0.13.0", Port: 9200},
+			contains: []string{"version=0.13.0", "/etc/systemd/system/node_exporter.service", "--web.listen-address=:9200"},
+		},
+		{
+			name:     "custom",
+			agent:    Agent{Type: AgentCustom, Script: "#!/bin/sh\necho hi"},
+			contains: []string{"echo hi"},
+		},
+	}
+
+	for _, c := range cases {
+		script, err := c.agent.Bootstrap()
+		require.NoError(t, err, c.name)
+		for _, s := range c.contains {
+			assert.Contains(t, script, s, c.name)
+		}
+	}
+
+	script, err := Agent{Type: AgentNone}.Bootstrap()
+	require.NoError(t, err)
+	assert.Empty(t, script)
+}
+
+func TestAgent_Validate(t *testing.T) {
+	cases := []struct {
+		agent   Agent
+		isValid bool
+	}{
+		{agent: Agent{}, isValid: true},
+		{agent: Agent{Type: AgentSystemd, Version: "0.14.0", Port: 9200}, isValid: true},
+		{agent: Agent{Type: AgentNone, Port: 9100}, isValid: true},
+		{agent: Agent{Type: AgentSystemd, Version: "latest"}, isValid: false},
+		{agent: Agent{Type: AgentCustom}, isValid: false},
+		{agent: Agent{Type: "runit"}, isValid: false},
+		{agent: Agent{Port: 70000}, isValid: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.isValid, len(c.agent.Validate()) == 0, "%#v", c.agent)
+	}
+}
+
+func TestAgentPortFromTags(t *testing.T) {
+	assert.Equal(t, 9200, agentPortFromTags([]string{"as", agentPortTag(9200)}))
+	assert.Equal(t, DefaultAgentPort, agentPortFromTags([]string{"as"}))
+}
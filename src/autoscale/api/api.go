@@ -3,11 +3,13 @@ package api
 import (
 	"autoscale/gen"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"pkg/ctxutil"
 	"pkg/echologger"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -19,12 +21,17 @@ import (
 	"github.com/labstack/echo/engine"
 	"github.com/labstack/echo/engine/standard"
 	"github.com/labstack/echo/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/satori/go.uuid"
 )
 
 var (
 	// WebPassword is the web token used for api auth.
 	WebPassword string
+
+	// schedulerRequestTimeout is how long requests wait for the scheduler to accept
+	// them.
+	schedulerRequestTimeout = 5 * time.Second
 )
 
 type errorWrapper struct {
@@ -80,10 +87,11 @@ func errorHandler(err error, c echo.Context) {
 
 // API is the autoscale API.
 type API struct {
-	Mux    http.Handler
-	repo   autoscale.Repository
-	ctx    context.Context
-	notify *autoscale.Notify
+	Mux       http.Handler
+	repo      autoscale.Repository
+	ctx       context.Context
+	notify    *autoscale.Notify
+	scheduler *autoscale.SchedulerStatus
 
 	templateResourceFactory    func() Resource
 	groupResourceFactory       func() Resource
@@ -93,17 +101,18 @@ type API struct {
 }
 
 // New creates an instance of API.
-func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notify) *API {
+func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notify, scheduler *autoscale.SchedulerStatus) *API {
 	e := echo.New()
 
 	std := standard.WithConfig(engine.Config{})
 	std.SetHandler(e)
 
 	a := &API{
-		Mux:    std,
-		repo:   repo,
-		ctx:    ctx,
-		notify: notify,
+		Mux:       std,
+		repo:      repo,
+		ctx:       ctx,
+		notify:    notify,
+		scheduler: scheduler,
 
 		templateResourceFactory: func() Resource {
 			return &templateResource{repo: repo}
@@ -127,12 +136,22 @@ func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notif
 
 	log := ctxutil.LogFromContext(ctx)
 
-	e.Use(middleware.BasicAuth(func(username, password string) bool {
+	basicAuth := middleware.BasicAuth(func(username, password string) bool {
 		if username == "autoscale" && password == WebPassword {
 			return true
 		}
 		return false
-	}))
+	})
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := basicAuth(next)
+		return func(c echo.Context) error {
+			if validStreamToken(c) {
+				return next(c)
+			}
+
+			return h(c)
+		}
+	})
 
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -166,15 +185,31 @@ func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notif
 	g.Get("/templates/:id", a.getTemplate)
 	g.Get("/templates", a.listTemplates)
 	g.Post("/templates", a.createTemplate)
+	g.Put("/templates/:id", a.updateTemplate)
 	g.Delete("/templates/:id", a.deleteTemplate)
+	g.Get("/templates/:id/versions", a.listTemplateVersions)
 	g.Get("/groups", a.listGroups)
 	g.Get("/groups/:id", a.getGroup)
 	g.Get("/groups/:id/time_series", a.getGroupTimeSeries)
 	g.Post("/groups", a.createGroup)
 	g.Delete("/groups/:id", a.deleteGroup)
 	g.Put("/groups/:id", a.updateGroup)
+	g.Post("/groups/:id/scale", a.scaleGroup)
+	g.Post("/groups/:id/check", a.checkGroup)
+	g.Post("/groups/:id/refreshes", a.createRefresh)
+	g.Get("/groups/:id/refreshes/:refreshID", a.getRefresh)
+	g.Post("/groups/:id/refreshes/:refreshID/pause", a.updateRefresh(autoscale.RefreshPaused))
+	g.Post("/groups/:id/refreshes/:refreshID/resume", a.updateRefresh(autoscale.RefreshRunning))
+	g.Post("/groups/:id/refreshes/:refreshID/cancel", a.updateRefresh(autoscale.RefreshCancelled))
+	g.Post("/groups/:id/refreshes/:refreshID/rollback", a.updateRefresh(autoscale.RefreshRollingBack))
+	g.Post("/groups/:id/resources", a.attachResource)
+	g.Delete("/groups/:id/resources/:resourceID", a.detachResource)
+	g.Put("/groups/:id/resources/:resourceID/protection", a.protectResource)
 	g.Get("/user_config", a.userConfig)
 	g.Get("/group_configs", a.groupConfig)
+	g.Get("/events", a.listEvents)
+	g.Get("/events/stream", standard.WrapHandler(a.eventStream()))
+	g.Post("/stream_tokens", a.createStreamToken)
 	g.Get("/notifications", standard.WrapHandler(a.notificationSocket()))
 
 	e.Get("/", func(c echo.Context) error {
@@ -189,10 +224,11 @@ func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notif
 				AssetInfo: gen.AssetInfo,
 				Prefix:    "static"}))
 	e.Get("/dashboard*", standard.WrapHandler(assetHandler))
+	e.Get("/metrics", standard.WrapHandler(prometheus.Handler()))
 
 	e.SetHTTPErrorHandler(errorHandler)
 
-	go hub.run()
+	hubOnce.Do(func() { go hub.run() })
 	go func() {
 		for notif := range notify.NotificationListener {
 			j, err := json.Marshal(&notif)
@@ -200,8 +236,7 @@ func New(ctx context.Context, repo autoscale.Repository, notify *autoscale.Notif
 				log.WithError(err).Error("unable to marshal notification")
 			}
 
-			msg := string(j)
-			hub.broadcastMessage(msg)
+			hub.broadcast <- socketMessage{id: notif.ID, groupID: notif.GroupID, data: j}
 		}
 	}()
 
@@ -250,6 +285,24 @@ func (a *API) createTemplate(c echo.Context) error {
 	return buildResponse(c, resp)
 }
 
+func (a *API) updateTemplate(c echo.Context) error {
+	id := c.Param("id")
+	var wrapper templateWrapper
+	if err := c.Bind(&wrapper); err != nil {
+		return err
+	}
+
+	t := wrapper.Template
+	t.ID = id
+
+	resp, err := a.templateResourceFactory().Update(c, t)
+	if err != nil {
+		return err
+	}
+
+	return buildResponse(c, resp)
+}
+
 func (a *API) deleteTemplate(c echo.Context) error {
 	id := c.Param("id")
 	resp, err := a.templateResourceFactory().Delete(c, id)
@@ -260,6 +313,25 @@ func (a *API) deleteTemplate(c echo.Context) error {
 	return buildResponse(c, resp)
 }
 
+func (a *API) listTemplateVersions(c echo.Context) error {
+	id := c.Param("id")
+
+	if _, err := a.repo.GetTemplate(c, id); err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	versions, err := a.repo.ListTemplateVersions(c, id)
+	if err != nil {
+		return err
+	}
+
+	return buildResponse(c, newResponse(templateVersionsWrapper{Versions: versions}, http.StatusOK))
+}
+
 func (a *API) listGroups(c echo.Context) error {
 	resp, err := a.groupResourceFactory().FindAll(c)
 	if err != nil {
@@ -324,6 +396,278 @@ func (a *API) updateGroup(c echo.Context) error {
 	return buildResponse(c, resp)
 }
 
+func (a *API) scaleGroup(c echo.Context) error {
+	id := c.Param("id")
+	var ugr autoscale.UpdateGroupRequest
+	if err := c.Bind(&ugr); err != nil {
+		return err
+	}
+
+	if ugr.BaseSize == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "base_size is required")
+	}
+
+	if *ugr.BaseSize < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "base_size must be greater than or equal to 0")
+	}
+
+	group, err := a.repo.GetGroup(c, id)
+	if err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	size := group.Policy.Clamp(*ugr.BaseSize)
+	if err := a.sendRequest(autoscale.ResizeGroupRequest(id, size)); err != nil {
+		return err
+	}
+
+	result := groupRequestWrapper{
+		GroupRequest: groupRequestResult{GroupID: id, Action: "resize", Size: size},
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusAccepted))
+}
+
+// sendRequest passes a request to the scheduler. It gives up if the scheduler doesn't
+// take the request within schedulerRequestTimeout, rather than holding the API request
+// open.
+func (a *API) sendRequest(req autoscale.GroupRequest) error {
+	select {
+	case a.scheduler.Request <- req:
+		return nil
+	case <-time.After(schedulerRequestTimeout):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "scheduler is busy, try again later")
+	}
+}
+
+func (a *API) checkGroup(c echo.Context) error {
+	id := c.Param("id")
+
+	if _, err := a.repo.GetGroup(c, id); err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	if err := a.sendRequest(autoscale.CheckGroupRequest(id)); err != nil {
+		return err
+	}
+
+	result := groupRequestWrapper{
+		GroupRequest: groupRequestResult{GroupID: id, Action: "check"},
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusAccepted))
+}
+
+func (a *API) createRefresh(c echo.Context) error {
+	id := c.Param("id")
+
+	var req createRefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	refresh, err := autoscale.StartRefresh(c, a.repo, id, req.MinHealthyPercent)
+	if err != nil {
+		switch err {
+		case autoscale.ObjectMissingErr:
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		case autoscale.ErrRefreshInProgress:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+
+		return err
+	}
+
+	if err := a.sendRequest(autoscale.RefreshGroupRequest(id, refresh.ID)); err != nil {
+		return err
+	}
+
+	return buildResponse(c, newResponse(refreshWrapper{Refresh: *refresh}, http.StatusAccepted))
+}
+
+func (a *API) getRefresh(c echo.Context) error {
+	refresh, err := a.findRefresh(c)
+	if err != nil {
+		return err
+	}
+
+	if refresh == nil {
+		return buildResponse(c, newResponse(nil, http.StatusNotFound))
+	}
+
+	return buildResponse(c, newResponse(refreshWrapper{Refresh: *refresh}, http.StatusOK))
+}
+
+func (a *API) updateRefresh(state autoscale.RefreshState) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		refresh, err := a.findRefresh(c)
+		if err != nil {
+			return err
+		}
+
+		if refresh == nil {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		if err := refresh.Transition(c, a.repo, state); err != nil {
+			if err == autoscale.ErrInvalidRefreshTransition {
+				return echo.NewHTTPError(http.StatusConflict, err.Error())
+			}
+
+			return err
+		}
+
+		if state == autoscale.RefreshRollingBack {
+			group, err := a.repo.GetGroup(c, refresh.GroupID)
+			if err != nil {
+				return err
+			}
+
+			group.TemplateID = refresh.PreviousTemplateID
+			group.TemplateVersion = refresh.PreviousTemplateVersion
+			if err := a.repo.SaveGroup(c, *group); err != nil {
+				return err
+			}
+		}
+
+		if state.IsActive() {
+			if err := a.sendRequest(autoscale.RefreshGroupRequest(refresh.GroupID, refresh.ID)); err != nil {
+				return err
+			}
+		}
+
+		return buildResponse(c, newResponse(refreshWrapper{Refresh: *refresh}, http.StatusAccepted))
+	}
+}
+
+// findRefresh returns the refresh identified by the request. It returns nil if the
+// refresh doesn't exist or doesn't belong to the group in the request.
+func (a *API) findRefresh(c echo.Context) (*autoscale.Refresh, error) {
+	refresh, err := a.repo.GetRefresh(c, c.Param("refreshID"))
+	if err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if refresh.GroupID != c.Param("id") {
+		return nil, nil
+	}
+
+	return refresh, nil
+}
+
+func (a *API) attachResource(c echo.Context) error {
+	id := c.Param("id")
+
+	var req attachResourceRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if req.ResourceID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "resource_id is required")
+	}
+
+	if _, err := a.repo.GetGroup(c, id); err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	if err := a.sendRequest(autoscale.AttachGroupRequest(id, req.ResourceID, req.AdjustCount)); err != nil {
+		return err
+	}
+
+	result := groupRequestWrapper{
+		GroupRequest: groupRequestResult{GroupID: id, Action: "attach", ResourceID: req.ResourceID},
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusAccepted))
+}
+
+func (a *API) detachResource(c echo.Context) error {
+	id := c.Param("id")
+	resourceID := c.Param("resourceID")
+	adjustCount := c.QueryParam("adjust_count") == "true"
+
+	if _, err := a.repo.GetGroup(c, id); err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	if err := a.sendRequest(autoscale.DetachGroupRequest(id, resourceID, adjustCount)); err != nil {
+		return err
+	}
+
+	result := groupRequestWrapper{
+		GroupRequest: groupRequestResult{GroupID: id, Action: "detach", ResourceID: resourceID},
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusAccepted))
+}
+
+func (a *API) protectResource(c echo.Context) error {
+	id := c.Param("id")
+	resourceID := c.Param("resourceID")
+
+	var req resourceProtectionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	group, err := a.repo.GetGroup(c, id)
+	if err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	rm, err := group.Resource()
+	if err != nil {
+		return err
+	}
+
+	if err := rm.Protect(c, resourceID, req.Protected); err != nil {
+		if err == autoscale.ErrResourceNotInGroup {
+			return buildResponse(c, newResponse(nil, http.StatusNotFound))
+		}
+
+		return err
+	}
+
+	allocations, err := rm.Allocated()
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		if allocation.ID == resourceID {
+			allocation.Protected = req.Protected
+			return buildResponse(c, newResponse(resourceWrapper{Resource: allocation}, http.StatusOK))
+		}
+	}
+
+	return buildResponse(c, newResponse(nil, http.StatusNotFound))
+}
+
 func (a *API) userConfig(c echo.Context) error {
 	resp, err := a.userConfigResourceFactory().FindAll(c)
 	if err != nil {
@@ -352,8 +696,107 @@ func (a *API) getGroupTimeSeries(c echo.Context) error {
 	return buildResponse(c, resp)
 }
 
+// listEvents lists stored notifications, newest first. They can be filtered by group,
+// by comma separated event types, and by an RFC 3339 time range with since and until.
+// Pages are requested by passing the nextCursor of the previous page as cursor. Cursors
+// which are no longer stored are answered with 410 Gone.
+func (a *API) listEvents(c echo.Context) error {
+	q := autoscale.EventQuery{
+		GroupID: c.QueryParam("group"),
+		Before:  c.QueryParam("cursor"),
+	}
+
+	if types := c.QueryParam("type"); types != "" {
+		q.Events = strings.Split(types, ",")
+	}
+
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if v := c.QueryParam(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, p.name+" must be an RFC 3339 time")
+			}
+
+			*p.t = t
+		}
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > autoscale.MaxEventLimit {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				fmt.Sprintf("limit must be between 1 and %d", autoscale.MaxEventLimit))
+		}
+
+		q.Limit = limit
+	}
+
+	events, err := a.repo.ListEvents(c, q)
+	if err != nil {
+		if status, ok := eventCursorStatus(err); ok {
+			return echo.NewHTTPError(status, err.Error())
+		}
+
+		return err
+	}
+
+	result := eventsWrapper{Events: events}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = autoscale.DefaultEventLimit
+	}
+
+	if len(events) == limit {
+		result.Meta.NextCursor = events[len(events)-1].ID
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusOK))
+}
+
+// replayEvents returns the notifications stored after the one identified by since, up
+// to MaxEventLimit of them. It returns true as well if more were stored.
+func (a *API) replayEvents(since string) ([]autoscale.Notification, bool, error) {
+	notifs, err := a.repo.ListEvents(a.ctx, autoscale.EventQuery{After: since, Limit: autoscale.MaxEventLimit})
+	if err != nil || len(notifs) < autoscale.MaxEventLimit {
+		return notifs, false, err
+	}
+
+	more, err := a.repo.ListEvents(a.ctx, autoscale.EventQuery{After: notifs[len(notifs)-1].ID, Limit: 1})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return notifs, len(more) > 0, nil
+}
+
+// eventCursorStatus returns the status of responses to requests with an event cursor
+// the repository rejected. Cursors which aren't stored events are gone, so clients know
+// to start over rather than retry.
+func eventCursorStatus(err error) (int, bool) {
+	switch err {
+	case autoscale.ErrInvalidEventCursor:
+		return http.StatusBadRequest, true
+	case autoscale.ErrUnknownEventCursor:
+		return http.StatusGone, true
+	default:
+		return 0, false
+	}
+}
+
 func (a *API) notificationSocket() http.HandlerFunc {
-	return serveWs
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveWs(w, r, a.replayEvents)
+	}
+}
+
+func (a *API) eventStream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveEventStream(w, r, a.replayEvents)
+	}
 }
 
 // func (a *API) notificationSocket() websocket.Handler {
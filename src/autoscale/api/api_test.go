@@ -2,14 +2,27 @@ package api
 
 import (
 	"autoscale"
+	"bufio"
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"pkg/do"
+	domocks "pkg/do/mocks"
+	"pkg/doclient"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/digitalocean/godo"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -18,6 +31,9 @@ import (
 )
 
 type apiTestMocks struct {
+	repo                *autoscale.MockRepository
+	notify              *autoscale.Notify
+	schedulerStatus     *autoscale.SchedulerStatus
 	templateResource    *MockResource
 	groupResource       *MockResource
 	userConfigResource  *MockResource
@@ -29,9 +45,15 @@ func withAPITest(t *testing.T, fn apiTestFn) {
 	ctx := context.Background()
 	repo := &autoscale.MockRepository{}
 	notify := autoscale.NewNotify(ctx, repo)
-	api := New(ctx, repo, notify)
+	schedulerStatus := &autoscale.SchedulerStatus{
+		Request: make(chan autoscale.GroupRequest, 10),
+	}
+	api := New(ctx, repo, notify, schedulerStatus)
 
 	mocks := &apiTestMocks{
+		repo:                repo,
+		notify:              notify,
+		schedulerStatus:     schedulerStatus,
 		templateResource:    &MockResource{},
 		groupResource:       &MockResource{},
 		userConfigResource:  &MockResource{},
@@ -379,3 +401,908 @@ func TestRouteRedirectsToDashboard(t *testing.T) {
 		require.Equal(t, "/dashboard/", res.Header.Get("Location"))
 	})
 }
+
+func TestScaleGroup(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		policy, err := autoscale.NewValuePolicy(autoscale.ValuePolicyScale(1, 10, 0.8, 2, 0.2, 1))
+		require.NoError(t, err)
+
+		group := &autoscale.Group{ID: "abc", Policy: policy}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/scale"
+
+		var buf bytes.Buffer
+		_, err = buf.WriteString(`{"base_size": 20}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		var wrapper groupRequestWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, 10, wrapper.GroupRequest.Size)
+
+		req := <-mocks.schedulerStatus.Request
+		require.Equal(t, "abc", req.ID)
+	})
+}
+
+func TestScaleGroup_MissingBaseSize(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		u.Path = "/api/groups/abc/scale"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"size": 2}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Len(t, mocks.schedulerStatus.Request, 0)
+	})
+}
+
+func TestCheckGroup_SchedulerBusy(t *testing.T) {
+	ogTimeout := schedulerRequestTimeout
+	defer func() { schedulerRequestTimeout = ogTimeout }()
+	schedulerRequestTimeout = 10 * time.Millisecond
+
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		for i := 0; i < cap(mocks.schedulerStatus.Request); i++ {
+			mocks.schedulerStatus.Request <- autoscale.CheckGroupRequest("other")
+		}
+
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/check"
+
+		res, err := doRequest("POST", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	})
+}
+
+func TestScaleMissingGroup(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(nil, autoscale.ObjectMissingErr)
+
+		u.Path = "/api/groups/abc/scale"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"base_size": 2}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.Len(t, mocks.schedulerStatus.Request, 0)
+	})
+}
+
+func TestCheckGroup(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/check"
+
+		res, err := doRequest("POST", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		req := <-mocks.schedulerStatus.Request
+		require.Equal(t, "abc", req.ID)
+	})
+}
+
+func TestProtectResource(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		rm := &autoscale.MockResourceManager{}
+		rm.On("Protect", mock.Anything, "12345", true).Return(nil)
+		rm.On("Allocated").Return([]autoscale.ResourceAllocation{
+			{ID: "12345", Name: "abc-1"},
+		}, nil)
+
+		autoscale.ResourceManagerFactory = func(g *autoscale.Group) (autoscale.ResourceManager, error) {
+			return rm, nil
+		}
+
+		u.Path = "/api/groups/abc/resources/12345/protection"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"protected": true}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("PUT", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var wrapper resourceWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, "12345", wrapper.Resource.ID)
+		require.True(t, wrapper.Resource.Protected)
+
+		assert.True(t, rm.AssertExpectations(t))
+	})
+}
+
+func TestProtectResourceNotInGroup(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/resources/12345/protection"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"protected": true}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("PUT", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestAttachResource(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/resources"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"resource_id": "12345", "adjust_count": true}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		var wrapper groupRequestWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, "attach", wrapper.GroupRequest.Action)
+		require.Equal(t, "12345", wrapper.GroupRequest.ResourceID)
+
+		req := <-mocks.schedulerStatus.Request
+		require.Equal(t, "abc", req.ID)
+	})
+}
+
+func TestDetachResource(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc"}
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+
+		u.Path = "/api/groups/abc/resources/12345"
+		u.RawQuery = "adjust_count=true"
+
+		res, err := doRequest("DELETE", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		req := <-mocks.schedulerStatus.Request
+		require.Equal(t, "abc", req.ID)
+
+		ga := &autoscale.MockGroupAction{}
+		ga.On("Detach", mock.Anything, "abc", "12345", true).Return(&autoscale.ActionStatus{})
+		req.Run(ctx, ga)
+		assert.True(t, ga.AssertExpectations(t))
+	})
+}
+
+func TestCreateRefresh(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		group := &autoscale.Group{ID: "abc", TemplateID: "new"}
+		mocks.repo.On("ListUnfinishedRefreshes", mock.Anything).Return([]autoscale.Refresh{}, nil)
+		mocks.repo.On("GetGroup", mock.Anything, "abc").Return(group, nil)
+		mocks.repo.On("GetTemplateVersion", mock.Anything, "new", 0).
+			Return(&autoscale.Template{ID: "new", Version: 3}, nil)
+		mocks.repo.On("CreateRefresh", mock.Anything, mock.AnythingOfType("autoscale.Refresh")).
+			Return(func(ctx context.Context, r autoscale.Refresh) *autoscale.Refresh {
+				r.ID = "refresh-id"
+				return &r
+			}, nil)
+
+		u.Path = "/api/groups/abc/refreshes"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"min_healthy_percent": 50}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		var wrapper refreshWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, "refresh-id", wrapper.Refresh.ID)
+		require.Equal(t, 50, wrapper.Refresh.MinHealthyPercent)
+		require.Equal(t, 3, wrapper.Refresh.TemplateVersion)
+
+		req := <-mocks.schedulerStatus.Request
+		require.Equal(t, "abc", req.ID)
+	})
+}
+
+func TestCreateRefreshInProgress(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		refreshes := []autoscale.Refresh{{ID: "refresh-id", GroupID: "abc", State: autoscale.RefreshPaused}}
+		mocks.repo.On("ListUnfinishedRefreshes", mock.Anything).Return(refreshes, nil)
+
+		u.Path = "/api/groups/abc/refreshes"
+
+		var buf bytes.Buffer
+		_, err := buf.WriteString(`{"min_healthy_percent": 50}`)
+		require.NoError(t, err)
+
+		res, err := doRequest("POST", u.String(), &buf)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+		require.Len(t, mocks.schedulerStatus.Request, 0)
+	})
+}
+
+func TestPauseRefresh(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		refresh := &autoscale.Refresh{ID: "refresh-id", GroupID: "abc", State: autoscale.RefreshRunning}
+		mocks.repo.On("GetRefresh", mock.Anything, "refresh-id").Return(refresh, nil)
+		mocks.repo.On("UpdateRefreshState", mock.Anything, "refresh-id", autoscale.RefreshPaused).Return(nil)
+
+		u.Path = "/api/groups/abc/refreshes/refresh-id/pause"
+
+		res, err := doRequest("POST", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+		require.Len(t, mocks.schedulerStatus.Request, 0)
+
+		u.Path = "/api/groups/other/refreshes/refresh-id/pause"
+
+		res, err = doRequest("POST", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestListTemplateVersions(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		versions := []autoscale.Template{
+			{ID: "1", Version: 2},
+			{ID: "1", Version: 1},
+		}
+		mocks.repo.On("GetTemplate", mock.Anything, "1").Return(&autoscale.Template{ID: "1", Version: 2}, nil)
+		mocks.repo.On("ListTemplateVersions", mock.Anything, "1").Return(versions, nil)
+
+		u.Path = "/api/templates/1/versions"
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var wrapper templateVersionsWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, versions, wrapper.Versions)
+	})
+}
+
+func TestTemplateResourceDeleteInUse(t *testing.T) {
+	ctx := context.Background()
+	repo := &autoscale.MockRepository{}
+	repo.On("DeleteTemplate", ctx, "1").Return(autoscale.ErrTemplateInUse)
+
+	r := &templateResource{repo: repo}
+	_, err := r.Delete(ctx, "1")
+
+	he, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusConflict, he.Code)
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		tmpl := autoscale.Template{
+			ID:     "1",
+			Name:   "a-template",
+			Region: "dev0",
+			Size:   "1gb",
+			Image:  "ubuntu-14-04-x64",
+		}
+
+		updated := tmpl
+		updated.Version = 2
+
+		resp := newResponse(templateWrapper{Template: updated}, http.StatusOK)
+		mocks.templateResource.On("Update", mock.Anything, tmpl).Return(resp, nil)
+
+		u.Path = "/api/templates/1"
+
+		body := bytes.NewBufferString(`{
+    "template":{
+      "name": "a-template",
+      "region": "dev0",
+      "size": "1gb",
+      "image": "ubuntu-14-04-x64"
+    }
+  }`)
+
+		res, err := doRequest("PUT", u.String(), body)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var wrapper templateWrapper
+		err = json.NewDecoder(res.Body).Decode(&wrapper)
+		require.NoError(t, err)
+		require.Equal(t, 2, wrapper.Template.Version)
+	})
+}
+
+func TestTemplateResourceUpdateInvalid(t *testing.T) {
+	ctx := context.Background()
+	tmpl := autoscale.Template{ID: "1", Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64"}
+
+	repo := &autoscale.MockRepository{}
+	repo.On("GetTemplate", ctx, "1").Return(&tmpl, nil)
+
+	rs := &domocks.RegionsService{}
+	rs.On("List").Return(do.Regions{
+		{Region: &godo.Region{Slug: "nyc1", Sizes: []string{"512mb"}, Available: true}},
+	}, nil)
+	ss := &domocks.SizesService{}
+	ss.On("List").Return(do.Sizes{
+		{Size: &godo.Size{Slug: "512mb", Available: true}},
+		{Size: &godo.Size{Slug: "1gb", Available: true}},
+	}, nil)
+	ks := &domocks.KeysService{}
+	ks.On("List").Return(do.SSHKeys{}, nil)
+	as := &domocks.AccountService{}
+	as.On("Get").Return(&do.Account{Account: &godo.Account{UUID: "1"}}, nil)
+	is := &domocks.ImagesService{}
+	is.On("ListUser", false).Return(do.Images{}, nil)
+	is.On("GetBySlug", "ubuntu-14-04-x64").Return(&do.Image{Image: &godo.Image{Regions: []string{"nyc1"}}}, nil)
+
+	origFactory := autoscale.DOClientFactory
+	defer func() { autoscale.DOClientFactory = origFactory }()
+	autoscale.DOClientFactory = func() *doclient.Client {
+		return &doclient.Client{
+			RegionsService:  rs,
+			SizesService:    ss,
+			KeysService:     ks,
+			AccountsService: as,
+			ImagesService:   is,
+		}
+	}
+
+	r := &templateResource{repo: repo}
+	resp, err := r.Update(ctx, tmpl)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+
+	wrapper, ok := resp.Result().(jsonAPIErrorsWrapper)
+	require.True(t, ok)
+	require.Len(t, wrapper.Errors, 1)
+	require.Equal(t, "/template/size", wrapper.Errors[0].Source.Pointer)
+
+	repo.AssertNotCalled(t, "UpdateTemplate", mock.Anything, mock.Anything)
+}
+
+func TestListEvents(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		since := time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)
+		q := autoscale.EventQuery{
+			GroupID: "abc",
+			Events:  []string{autoscale.EventError, autoscale.EventDisabled},
+			Since:   since,
+			Before:  "event-3",
+			Limit:   2,
+		}
+
+		events := []autoscale.Notification{
+			{ID: "event-2", GroupID: "abc", Event: autoscale.EventError, IsError: true, Message: "boom"},
+			{ID: "event-1", GroupID: "abc", Event: autoscale.EventDisabled, Kind: string(autoscale.ActivityDisabled)},
+		}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return(events, nil)
+
+		u.Path = "/api/events"
+		u.RawQuery = url.Values{
+			"group":  {"abc"},
+			"type":   {"error,disabled"},
+			"since":  {since.Format(time.RFC3339)},
+			"cursor": {"event-3"},
+			"limit":  {"2"},
+		}.Encode()
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result struct {
+			Events []autoscale.Notification `json:"events"`
+			Meta   struct {
+				NextCursor string `json:"nextCursor"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.Equal(t, events, result.Events)
+		require.Equal(t, "event-1", result.Meta.NextCursor)
+	})
+}
+
+func TestListEvents_LastPage(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		events := []autoscale.Notification{{ID: "event-1", GroupID: "abc", Event: autoscale.EventScaleUp}}
+		mocks.repo.On("ListEvents", mock.Anything, autoscale.EventQuery{}).Return(events, nil)
+
+		u.Path = "/api/events"
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.Equal(t, map[string]interface{}{}, result["meta"])
+	})
+}
+
+func TestListEvents_InvalidParams(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		u.Path = "/api/events"
+
+		for _, query := range []string{"since=yesterday", "until=1", "limit=0", "limit=1000"} {
+			u.RawQuery = query
+
+			res, err := doRequest("GET", u.String(), nil)
+			require.NoError(t, err)
+			res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+		}
+	})
+}
+
+func TestNotificationSocket_Replay(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		replayed := []autoscale.Notification{
+			{ID: "event-2", GroupID: "abc", Event: autoscale.EventError, IsError: true, Message: "boom"},
+			{ID: "event-3", GroupID: "abc", Event: autoscale.EventScaleUp, Delta: 1, Count: 2},
+		}
+		q := autoscale.EventQuery{After: "event-1", Limit: autoscale.MaxEventLimit}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return(replayed, nil)
+
+		u.Scheme = "ws"
+		u.Path = "/api/notifications"
+		u.RawQuery = "since=event-1"
+
+		header := http.Header{}
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("autoscale:token")))
+
+		ws, _, err := websocket.DefaultDialer.Dial(u.String(), header)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		readEvent := func() autoscale.Notification {
+			ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+			var n autoscale.Notification
+			require.NoError(t, ws.ReadJSON(&n))
+			return n
+		}
+
+		require.Equal(t, "event-2", readEvent().ID)
+		require.Equal(t, "event-3", readEvent().ID)
+
+		// event-3 was broadcast while the client was catching up, so it isn't sent again.
+		mocks.notify.NotificationListener <- replayed[1]
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-4", GroupID: "abc", Event: autoscale.EventScaleDown}
+
+		require.Equal(t, "event-4", readEvent().ID)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		u.Path = "/metrics"
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+
+		defer res.Body.Close()
+
+		require.Equal(t, 200, res.StatusCode)
+
+		b, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(b), "go_goroutines")
+	})
+}
+
+func dialNotifications(u *url.URL, query string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	u.Scheme = "ws"
+	u.Path = "/api/notifications"
+	u.RawQuery = query
+
+	return websocket.DefaultDialer.Dial(u.String(), header)
+}
+
+func basicAuthHeader() http.Header {
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("autoscale:token")))
+	return header
+}
+
+// createStreamToken requests a stream token from the API.
+func createStreamToken(t *testing.T, u url.URL) string {
+	u.Scheme = "http"
+	u.Path = "/api/stream_tokens"
+	u.RawQuery = ""
+
+	res, err := doRequest("POST", u.String(), nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var st streamTokenWrapper
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&st))
+	require.True(t, st.ExpiresAt.After(time.Now()))
+
+	return st.Token
+}
+
+func TestCheckStreamToken(t *testing.T) {
+	now := time.Now()
+	token := newStreamToken(now.Add(time.Minute))
+
+	require.True(t, checkStreamToken(token, now))
+	require.False(t, checkStreamToken(token, now.Add(time.Minute)))
+	require.False(t, checkStreamToken(newStreamToken(now.Add(time.Hour))[:20]+"0", now))
+	require.False(t, checkStreamToken("", now))
+	require.False(t, checkStreamToken("token", now))
+
+	forged := strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + token[strings.Index(token, "."):]
+	require.False(t, checkStreamToken(forged, now))
+}
+
+func TestNotificationSocket_Auth(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		_, res, err := dialNotifications(u, "", nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		_, res, err = dialNotifications(u, "token=wrong", nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		_, res, err = dialNotifications(u, "token=token", nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode, "the web password is not a stream token")
+
+		token := createStreamToken(t, *u)
+
+		ws, _, err := dialNotifications(u, "token="+url.QueryEscape(token), nil)
+		require.NoError(t, err)
+		ws.Close()
+
+		u.Scheme = "http"
+		u.Path = "/api/groups"
+		u.RawQuery = "token=" + url.QueryEscape(token)
+		res, err = http.Get(u.String())
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode, "tokens are only accepted by the socket")
+	})
+}
+
+func TestNotificationSocket_Origin(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		ogOrigins := AllowedOrigins
+		defer func() { AllowedOrigins = ogOrigins }()
+		AllowedOrigins = []string{"https://dashboard.example.com"}
+
+		cases := []struct {
+			origin string
+			ok     bool
+		}{
+			{origin: "http://" + u.Host, ok: true},
+			{origin: "https://dashboard.example.com", ok: true},
+			{origin: "https://evil.example.com", ok: false},
+		}
+
+		for _, c := range cases {
+			header := basicAuthHeader()
+			header.Set("Origin", c.origin)
+
+			ws, res, err := dialNotifications(u, "", header)
+			if !c.ok {
+				require.Error(t, err, c.origin)
+				require.Equal(t, http.StatusForbidden, res.StatusCode, c.origin)
+				continue
+			}
+
+			require.NoError(t, err, c.origin)
+			ws.Close()
+		}
+	})
+}
+
+func TestNotificationSocket_Subscribe(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		ws, _, err := dialNotifications(u, "group=abc", basicAuthHeader())
+		require.NoError(t, err)
+		defer ws.Close()
+
+		read := func(v interface{}) {
+			ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+			require.NoError(t, ws.ReadJSON(v))
+		}
+
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-1", GroupID: "def"}
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-2", GroupID: "abc"}
+
+		var n autoscale.Notification
+		read(&n)
+		require.Equal(t, "event-2", n.ID)
+
+		require.NoError(t, ws.WriteJSON(socketRequest{Type: "subscribe", Groups: []string{"def"}}))
+
+		var reply socketRequest
+		read(&reply)
+		require.Equal(t, socketRequest{Type: "subscriptions", Groups: []string{"abc", "def"}}, reply)
+
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-3", GroupID: "def"}
+		read(&n)
+		require.Equal(t, "event-3", n.ID)
+
+		require.NoError(t, ws.WriteJSON(socketRequest{Type: "unsubscribe", Groups: []string{"def"}}))
+		read(&reply)
+		require.Equal(t, []string{"abc"}, reply.Groups)
+
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-4", GroupID: "def"}
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-5", GroupID: "abc"}
+		read(&n)
+		require.Equal(t, "event-5", n.ID)
+	})
+}
+
+func TestSocketHub_DisconnectsSlowClients(t *testing.T) {
+	h := socketHub{clients: map[*client]bool{}}
+
+	slow := &client{send: make(chan socketMessage, 1), groups: map[string]bool{}}
+	other := &client{send: make(chan socketMessage, 2), groups: map[string]bool{}}
+	elsewhere := &client{send: make(chan socketMessage, 1), groups: map[string]bool{"def": true}}
+	h.clients[slow] = true
+	h.clients[other] = true
+	h.clients[elsewhere] = true
+
+	h.broadcastMessage(socketMessage{id: "event-1", groupID: "abc"})
+	h.broadcastMessage(socketMessage{id: "event-2", groupID: "abc"})
+
+	require.False(t, h.clients[slow])
+	require.True(t, h.clients[other])
+	require.True(t, h.clients[elsewhere])
+
+	require.Equal(t, "event-1", (<-slow.send).id)
+	_, ok := <-slow.send
+	require.False(t, ok, "slow client's channel is closed")
+
+	require.Len(t, other.send, 2)
+	require.Len(t, elsewhere.send, 0)
+}
+
+// readStreamEvent reads the next server-sent event, skipping comments.
+func readStreamEvent(t *testing.T, r *bufio.Reader) (string, autoscale.Notification) {
+	var id string
+	var n autoscale.Notification
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if id != "" {
+				return id, n
+			}
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &n))
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		replayed := []autoscale.Notification{
+			{ID: "event-2", GroupID: "abc", Event: autoscale.EventScaleUp, Delta: 1, Count: 2},
+			{ID: "event-3", GroupID: "def", Event: autoscale.EventScaleUp, Delta: 1, Count: 4},
+		}
+		q := autoscale.EventQuery{After: "event-1", Limit: autoscale.MaxEventLimit}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return(replayed, nil)
+
+		u.Path = "/api/events/stream"
+		u.RawQuery = "group=abc"
+
+		req, err := http.NewRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		req.SetBasicAuth("autoscale", "token")
+		req.Header.Set("Last-Event-ID", "event-1")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		r := bufio.NewReader(res.Body)
+
+		id, n := readStreamEvent(t, r)
+		require.Equal(t, "event-2", id)
+		require.Equal(t, 2, n.Count)
+
+		// event-2 was already replayed, and event-4 is for another group.
+		mocks.notify.NotificationListener <- replayed[0]
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-4", GroupID: "def"}
+		mocks.notify.NotificationListener <- autoscale.Notification{ID: "event-5", GroupID: "abc", Event: autoscale.EventScaleDown}
+
+		id, n = readStreamEvent(t, r)
+		require.Equal(t, "event-5", id)
+		require.Equal(t, autoscale.EventScaleDown, n.Event)
+	})
+}
+
+func TestEventStream_Auth(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		u.Path = "/api/events/stream"
+
+		res, err := http.Get(u.String())
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		u.RawQuery = "token=token"
+		res, err = http.Get(u.String())
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		token := createStreamToken(t, *u)
+		u.RawQuery = "token=" + url.QueryEscape(token)
+		res, err = http.Get(u.String())
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
+
+func TestEventStream_ReplayError(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		q := autoscale.EventQuery{After: "event-1", Limit: autoscale.MaxEventLimit}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return(nil, fmt.Errorf("boom"))
+
+		u.Path = "/api/events/stream"
+		u.RawQuery = "since=event-1"
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
+
+func TestEventStream_Truncated(t *testing.T) {
+	withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+		replayed := []autoscale.Notification{}
+		for i := 0; i < autoscale.MaxEventLimit; i++ {
+			replayed = append(replayed, autoscale.Notification{ID: fmt.Sprintf("event-%d", i+2), GroupID: "abc"})
+		}
+		last := replayed[len(replayed)-1].ID
+
+		q := autoscale.EventQuery{After: "event-1", Limit: autoscale.MaxEventLimit}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return(replayed, nil)
+		q = autoscale.EventQuery{After: last, Limit: 1}
+		mocks.repo.On("ListEvents", mock.Anything, q).Return([]autoscale.Notification{{ID: "event-next"}}, nil)
+
+		u.Path = "/api/events/stream"
+		u.RawQuery = "since=event-1"
+
+		res, err := doRequest("GET", u.String(), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		r := bufio.NewReader(res.Body)
+		for i := 0; i < autoscale.MaxEventLimit; i++ {
+			id, _ := readStreamEvent(t, r)
+			require.Equal(t, replayed[i].ID, id)
+		}
+
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "event: truncated\n", line)
+
+		line, err = r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("data: {\"type\":\"truncated\",\"lastID\":%q}\n", last), line)
+	})
+}
+
+func TestEventCursorErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{err: autoscale.ErrInvalidEventCursor, status: http.StatusBadRequest},
+		{err: autoscale.ErrUnknownEventCursor, status: http.StatusGone},
+	}
+
+	for _, c := range cases {
+		withAPITest(t, func(ctx context.Context, mocks *apiTestMocks, u *url.URL) {
+			mocks.repo.On("ListEvents", mock.Anything, autoscale.EventQuery{Before: "event-1"}).Return(nil, c.err)
+			mocks.repo.On("ListEvents", mock.Anything, autoscale.EventQuery{After: "event-1", Limit: autoscale.MaxEventLimit}).Return(nil, c.err)
+
+			u.Path = "/api/events"
+			u.RawQuery = "cursor=event-1"
+			res, err := doRequest("GET", u.String(), nil)
+			require.NoError(t, err)
+			res.Body.Close()
+			require.Equal(t, c.status, res.StatusCode, "list")
+
+			u.Path = "/api/events/stream"
+			u.RawQuery = "since=event-1"
+			res, err = doRequest("GET", u.String(), nil)
+			require.NoError(t, err)
+			res.Body.Close()
+			require.Equal(t, c.status, res.StatusCode, "stream")
+
+			_, res, err = dialNotifications(u, "since=event-1", basicAuthHeader())
+			require.Error(t, err)
+			require.Equal(t, c.status, res.StatusCode, "socket")
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"autoscale"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// serveEventStream streams notifications to a client as server-sent events, for
+// clients which can't use websockets. If the request has a Last-Event-ID header or a
+// since parameter with the ID of a notification, the notifications stored after it are
+// sent first, followed by a truncated event if there were more than could be replayed.
+// A group parameter with comma separated group IDs limits the stream to those groups.
+func serveEventStream(w http.ResponseWriter, r *http.Request, replay replayFn) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	c := newClient(r)
+	hub.register <- c
+	defer func() {
+		hub.unregister <- c
+	}()
+
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.URL.Query().Get("since")
+	}
+
+	var notifs []autoscale.Notification
+	var notice *replayNotice
+	if since != "" {
+		var err error
+		notifs, notice, err = c.catchUp(since, replay)
+		if err != nil {
+			replayError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, notif := range notifs {
+		j, err := json.Marshal(&notif)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		if err := writeEvent(w, notif.ID, j); err != nil {
+			return
+		}
+	}
+
+	if notice != nil {
+		j, err := json.Marshal(notice)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", socketTruncated, j); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			if !ok {
+				return
+			}
+			if c.sent(msg) {
+				continue
+			}
+			if err := writeEvent(w, msg.id, msg.data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a notification as a server-sent event. Its ID lets a client resume
+// the stream with Last-Event-ID after reconnecting.
+func writeEvent(w http.ResponseWriter, id string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", id, data)
+	return err
+}
@@ -22,3 +22,7 @@ func (j *jsonAPIError) GetName() string {
 }
 
 type jsonAPIErrors []*jsonAPIError
+
+type jsonAPIErrorsWrapper struct {
+	Errors jsonAPIErrors `json:"errors"`
+}
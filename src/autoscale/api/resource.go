@@ -4,7 +4,10 @@ import (
 	"autoscale"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/labstack/echo"
+	"github.com/manyminds/api2go"
 	"golang.org/x/net/context"
 )
 
@@ -53,6 +56,10 @@ type templatesWrapper struct {
 	Templates []autoscale.Template `json:"templates"`
 }
 
+type templateVersionsWrapper struct {
+	Versions []autoscale.Template `json:"templateVersions"`
+}
+
 type groupWrapper struct {
 	Group autoscale.Group `json:"group"`
 }
@@ -69,6 +76,47 @@ type groupConfigWrapper struct {
 	GroupConfig autoscale.GroupConfig `json:"groupConfigs"`
 }
 
+type groupRequestWrapper struct {
+	GroupRequest groupRequestResult `json:"groupRequest"`
+}
+
+type resourceWrapper struct {
+	Resource autoscale.ResourceAllocation `json:"resource"`
+}
+
+type refreshWrapper struct {
+	Refresh autoscale.Refresh `json:"refresh"`
+}
+
+type createRefreshRequest struct {
+	MinHealthyPercent int `json:"min_healthy_percent"`
+}
+
+type attachResourceRequest struct {
+	ResourceID  string `json:"resource_id"`
+	AdjustCount bool   `json:"adjust_count"`
+}
+
+type resourceProtectionRequest struct {
+	Protected bool `json:"protected"`
+}
+
+type groupRequestResult struct {
+	GroupID    string `json:"groupID"`
+	Action     string `json:"action"`
+	Size       int    `json:"size,omitempty"`
+	ResourceID string `json:"resourceID,omitempty"`
+}
+
+type eventsWrapper struct {
+	Events []autoscale.Notification `json:"events"`
+	Meta   eventsMeta               `json:"meta"`
+}
+
+type eventsMeta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
 type timeSeriesWrapper struct {
 	Values []autoscale.TimeSeries `json:"timeseries_values"`
 }
@@ -108,6 +156,10 @@ func (r *templateResource) Create(c context.Context, obj interface{}) (Response,
 
 func (r *templateResource) Delete(c context.Context, id string) (Response, error) {
 	if err := r.repo.DeleteTemplate(c, id); err != nil {
+		if err == autoscale.ErrTemplateInUse {
+			return nil, echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+
 		return newResponse(nil, http.StatusNotFound), nil
 	}
 
@@ -115,7 +167,53 @@ func (r *templateResource) Delete(c context.Context, id string) (Response, error
 }
 
 func (r *templateResource) Update(c context.Context, obj interface{}) (Response, error) {
-	return newResponse(nil, http.StatusNotImplemented), nil
+	in, ok := obj.(autoscale.Template)
+	if !ok {
+		return newResponse(nil, http.StatusBadRequest), nil
+	}
+
+	if _, err := r.repo.GetTemplate(c, in.ID); err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return newResponse(nil, http.StatusNotFound), nil
+		}
+
+		return newResponse(nil, http.StatusInternalServerError), nil
+	}
+
+	fieldErrs, err := autoscale.ValidateTemplate(c, autoscale.DOClientFactory(), in)
+	if err != nil {
+		return newResponse(nil, http.StatusInternalServerError), nil
+	}
+
+	if len(fieldErrs) > 0 {
+		return newResponse(templateErrors(fieldErrs), http.StatusUnprocessableEntity), nil
+	}
+
+	template, err := r.repo.UpdateTemplate(c, in)
+	if err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return newResponse(nil, http.StatusNotFound), nil
+		}
+
+		return newResponse(nil, http.StatusInternalServerError), nil
+	}
+
+	return newResponse(templateWrapper{Template: *template}, http.StatusOK), nil
+}
+
+// templateErrors converts template field errors to JSON API errors.
+func templateErrors(fieldErrs []autoscale.TemplateFieldError) jsonAPIErrorsWrapper {
+	errs := jsonAPIErrors{}
+	for _, fe := range fieldErrs {
+		errs = append(errs, &jsonAPIError{
+			Status: strconv.Itoa(http.StatusUnprocessableEntity),
+			Title:  "Invalid Attribute",
+			Detail: fe.Error(),
+			Source: &api2go.ErrorSource{Pointer: "/template/" + fe.Field},
+		})
+	}
+
+	return jsonAPIErrorsWrapper{Errors: errs}
 }
 
 func (r *templateResource) FindAll(c context.Context) (Response, error) {
@@ -181,7 +279,20 @@ func (r *groupResource) Update(c context.Context, obj interface{}) (Response, er
 		return newResponse(nil, http.StatusBadRequest), nil
 	}
 
-	err := r.repo.SaveGroup(c, in)
+	// sink secrets are redacted when groups are read, so unchanged ones are sent back
+	// redacted.
+	stored, err := r.repo.GetGroup(c, in.ID)
+	if err != nil {
+		if err == autoscale.ObjectMissingErr {
+			return newResponse(nil, http.StatusNotFound), nil
+		}
+
+		return newResponse(nil, http.StatusInternalServerError), nil
+	}
+
+	in.NotificationSinks = in.NotificationSinks.Restore(stored.NotificationSinks)
+
+	err = r.repo.SaveGroup(c, in)
 	if err != nil {
 		return newResponse(nil, http.StatusInternalServerError), nil
 	}
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"autoscale"
+	"encoding/json"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -12,26 +18,200 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 1024 * 1024
+	maxMessageSize = 4096
+
+	// sendBufferSize is how many notifications can wait for a client. Clients which fall
+	// further behind are disconnected.
+	sendBufferSize = 64
 )
 
 var (
-	hub = socketHub{
+	// AllowedOrigins are the origins, besides the API's own, which may open notification
+	// sockets from a browser.
+	AllowedOrigins []string
+
+	hubOnce sync.Once
+	hub     = socketHub{
 		clients:    map[*client]bool{},
 		register:   make(chan *client),
 		unregister: make(chan *client),
+		broadcast:  make(chan socketMessage),
 	}
 
 	upgrader = websocket.Upgrader{
-		ReadBufferSize:  maxMessageSize,
-		WriteBufferSize: maxMessageSize,
-		CheckOrigin:     func(r *http.Request) bool { return true },
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin,
+	}
+)
+
+// checkOrigin allows requests without an origin, which don't come from browsers, and
+// requests from the API's own host or an allowed origin.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
 	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// socketMessage is a notification encoded for websocket clients.
+type socketMessage struct {
+	id      string
+	groupID string
+	data    []byte
+}
+
+// socketRequest is a message from a websocket client. Subscribing to groups limits the
+// notifications the client receives to those groups, and unsubscribing from all of
+// them restores every group's notifications. The client is sent its subscriptions in
+// reply.
+type socketRequest struct {
+	Type   string   `json:"type"`
+	Groups []string `json:"groups"`
+}
+
+const (
+	socketSubscribe     = "subscribe"
+	socketUnsubscribe   = "unsubscribe"
+	socketSubscriptions = "subscriptions"
+	socketTruncated     = "truncated"
 )
 
+// replayNotice tells a client catching up that more notifications were stored than
+// were replayed. The client can catch up on the rest from LastID.
+type replayNotice struct {
+	Type   string `json:"type"`
+	LastID string `json:"lastID"`
+}
+
+// replayFn returns the notifications stored after the one identified by since, and
+// true if more were stored than it returned.
+type replayFn func(since string) ([]autoscale.Notification, bool, error)
+
 type client struct {
 	ws   *websocket.Conn
-	send chan []byte
+	send chan socketMessage
+
+	// reply holds a message for the client from the read pump, which can't write to the
+	// connection itself.
+	reply chan []byte
+
+	// replayed holds the IDs of notifications sent while catching up, so they aren't
+	// sent again if they were also broadcast.
+	replayed map[string]bool
+
+	mu     sync.Mutex
+	groups map[string]bool
+}
+
+// subscribe adds groups to the ones the client receives notifications for.
+func (c *client) subscribe(groups ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, g := range groups {
+		c.groups[g] = true
+	}
+}
+
+// unsubscribe removes groups from the ones the client receives notifications for.
+func (c *client) unsubscribe(groups ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, g := range groups {
+		delete(c.groups, g)
+	}
+}
+
+// wants returns true if the client receives notifications for the group. A client
+// without subscriptions receives every group's notifications.
+func (c *client) wants(groupID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.groups) == 0 || c.groups[groupID]
+}
+
+// subscriptions returns the groups the client receives notifications for.
+func (c *client) subscriptions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	groups := []string{}
+	for g := range c.groups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	return groups
+}
+
+// readPump handles subscription requests and pongs until the connection fails, then
+// unregisters the client.
+func (c *client) readPump() {
+	defer func() {
+		hub.unregister <- c
+		c.ws.Close()
+	}()
+
+	c.ws.SetReadLimit(maxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		_, message, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req socketRequest
+		if err := json.Unmarshal(message, &req); err != nil {
+			log.Printf("invalid websocket request: %v", err)
+			continue
+		}
+
+		switch req.Type {
+		case socketSubscribe:
+			c.subscribe(req.Groups...)
+		case socketUnsubscribe:
+			c.unsubscribe(req.Groups...)
+		default:
+			log.Printf("unknown websocket request %q", req.Type)
+			continue
+		}
+
+		j, err := json.Marshal(socketRequest{Type: socketSubscriptions, Groups: c.subscriptions()})
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		select {
+		case c.reply <- j:
+		default:
+		}
+	}
 }
 
 func (c *client) writePump() {
@@ -49,7 +229,14 @@ func (c *client) writePump() {
 				c.write(websocket.CloseMessage, []byte{})
 				return
 			}
-			if err := c.write(websocket.TextMessage, message); err != nil {
+			if c.sent(message) {
+				continue
+			}
+			if err := c.write(websocket.TextMessage, message.data); err != nil {
+				return
+			}
+		case reply := <-c.reply:
+			if err := c.write(websocket.TextMessage, reply); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -69,6 +256,7 @@ type socketHub struct {
 	clients    map[*client]bool
 	register   chan *client
 	unregister chan *client
+	broadcast  chan socketMessage
 }
 
 func (h *socketHub) run() {
@@ -81,39 +269,164 @@ func (h *socketHub) run() {
 				delete(h.clients, c)
 				close(c.send)
 			}
+		case msg := <-h.broadcast:
+			h.broadcastMessage(msg)
 		}
 	}
 }
 
-func (h *socketHub) broadcastMessage(msg string) {
+// broadcastMessage sends msg to the clients subscribed to its group. Clients whose
+// buffers are full are disconnected rather than holding up the others.
+func (h *socketHub) broadcastMessage(msg socketMessage) {
 	for c := range h.clients {
+		if !c.wants(msg.groupID) {
+			continue
+		}
+
 		select {
-		case c.send <- []byte(msg):
+		case c.send <- msg:
 		default:
+			log.Println("disconnecting slow websocket client")
 			close(c.send)
 			delete(h.clients, c)
 		}
 	}
 }
 
-func serveWs(w http.ResponseWriter, r *http.Request) {
+// newClient creates a client for a request, subscribed to the groups in its group
+// parameter.
+func newClient(r *http.Request) *client {
+	c := &client{
+		send:     make(chan socketMessage, sendBufferSize),
+		replayed: map[string]bool{},
+		groups:   map[string]bool{},
+	}
+
+	if groups := r.URL.Query().Get("group"); groups != "" {
+		c.subscribe(strings.Split(groups, ",")...)
+	}
+
+	return c
+}
+
+// serveWs streams notifications to a websocket client. If the request has a since
+// parameter with the ID of a notification, the notifications stored after it are sent
+// first. A group parameter with comma separated group IDs subscribes the client to
+// those groups.
+func serveWs(w http.ResponseWriter, r *http.Request, replay replayFn) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", 405)
 		return
 	}
 
+	c := newClient(r)
+	c.reply = make(chan []byte, 1)
+
+	// the client is registered before catching up so nothing broadcast meanwhile is
+	// missed, and catches up before upgrading so a bad since can still be answered.
+	hub.register <- c
+
+	var notifs []autoscale.Notification
+	var notice *replayNotice
+	if since := r.URL.Query().Get("since"); since != "" {
+		var err error
+		notifs, notice, err = c.catchUp(since, replay)
+		if err != nil {
+			hub.unregister <- c
+			replayError(w, err)
+			return
+		}
+	}
+
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
+		hub.unregister <- c
 		return
 	}
+	c.ws = ws
 
-	c := &client{
-		send: make(chan []byte, maxMessageSize),
-		ws:   ws,
+	if err := c.replay(notifs, notice); err != nil {
+		log.Println(err)
+		hub.unregister <- c
+		ws.Close()
+		return
 	}
 
-	hub.register <- c
-
+	go c.readPump()
 	c.writePump()
 }
+
+// replay sends the notifications the client caught up on, followed by notice if the
+// replay was truncated.
+func (c *client) replay(notifs []autoscale.Notification, notice *replayNotice) error {
+	for _, notif := range notifs {
+		j, err := json.Marshal(&notif)
+		if err != nil {
+			return err
+		}
+
+		if err := c.write(websocket.TextMessage, j); err != nil {
+			return err
+		}
+	}
+
+	if notice == nil {
+		return nil
+	}
+
+	j, err := json.Marshal(notice)
+	if err != nil {
+		return err
+	}
+
+	return c.write(websocket.TextMessage, j)
+}
+
+// catchUp returns the notifications stored after since for the client's groups, and
+// remembers them so they aren't sent twice. If more notifications were stored than
+// were replayed, it returns a notice for the client as well.
+func (c *client) catchUp(since string, replay replayFn) ([]autoscale.Notification, *replayNotice, error) {
+	notifs, truncated, err := replay(since)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := []autoscale.Notification{}
+	for _, notif := range notifs {
+		if !c.wants(notif.GroupID) {
+			continue
+		}
+
+		wanted = append(wanted, notif)
+		c.replayed[notif.ID] = true
+	}
+
+	var notice *replayNotice
+	if truncated && len(notifs) > 0 {
+		notice = &replayNotice{Type: socketTruncated, LastID: notifs[len(notifs)-1].ID}
+	}
+
+	return wanted, notice, nil
+}
+
+// replayError answers a request whose notifications couldn't be replayed.
+func replayError(w http.ResponseWriter, err error) {
+	if status, ok := eventCursorStatus(err); ok {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	log.Println(err)
+	http.Error(w, "unable to load events", http.StatusInternalServerError)
+}
+
+// sent returns true if msg was already sent while catching up.
+func (c *client) sent(msg socketMessage) bool {
+	if c.replayed[msg.id] {
+		delete(c.replayed, msg.id)
+		return true
+	}
+
+	return false
+}
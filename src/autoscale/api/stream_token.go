@@ -0,0 +1,90 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// streamTokenTTL is how long a stream token can be used to open a notification socket
+// or event stream. Streams which are already open aren't closed when it expires.
+var streamTokenTTL = 10 * time.Minute
+
+// streamTokenKey signs stream tokens. It is created when the process starts, so tokens
+// don't survive restarts.
+var streamTokenKey = newStreamTokenKey()
+
+func newStreamTokenKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("unable to create stream token key: %v", err))
+	}
+
+	return key
+}
+
+type streamTokenWrapper struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// newStreamToken creates a token which expires at expiresAt. Tokens are the expiry time
+// and its signature, so they don't need to be stored.
+func newStreamToken(expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + "." + signStreamToken(expiry)
+}
+
+func signStreamToken(expiry string) string {
+	mac := hmac.New(sha256.New, streamTokenKey)
+	mac.Write([]byte(expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkStreamToken returns true if token was created by newStreamToken and hasn't
+// expired at now.
+func checkStreamToken(token string, now time.Time) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || now.Unix() >= expiry {
+		return false
+	}
+
+	return hmac.Equal([]byte(parts[1]), []byte(signStreamToken(parts[0])))
+}
+
+// validStreamToken returns true if the request is for the notification socket or event
+// stream, and has a stream token in its token parameter. Browsers can't set headers on
+// websocket or EventSource requests, so streams can't always use basic auth.
+func validStreamToken(c echo.Context) bool {
+	switch c.Request().URL().Path() {
+	case "/api/notifications", "/api/events/stream":
+	default:
+		return false
+	}
+
+	return checkStreamToken(c.QueryParam("token"), time.Now())
+}
+
+// createStreamToken issues a token for opening a notification socket or event stream.
+func (a *API) createStreamToken(c echo.Context) error {
+	expiresAt := time.Now().Add(streamTokenTTL).UTC().Truncate(time.Second)
+	result := streamTokenWrapper{
+		Token:     newStreamToken(expiresAt),
+		ExpiresAt: expiresAt,
+	}
+
+	return buildResponse(c, newResponse(result, http.StatusCreated))
+}
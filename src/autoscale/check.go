@@ -2,6 +2,7 @@ package autoscale
 
 import (
 	"pkg/ctxutil"
+	"strconv"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -24,6 +25,7 @@ func NewCheck(repo Repository) *Check {
 // Scale cales the group identified by gruopID.
 func (c *Check) Scale(ctx context.Context, groupID string) *ActionStatus {
 	log := ctxutil.LogFromContext(ctx).WithField("group-id", groupID)
+	started := time.Now()
 
 	as := &ActionStatus{
 		Done: make(chan bool, 1),
@@ -45,6 +47,8 @@ func (c *Check) Scale(ctx context.Context, groupID string) *ActionStatus {
 		return as
 	}
 
+	defer c.reconcile(ctx, group, resource, as)
+
 	value, err := group.MetricsValue(ctx)
 	if err != nil {
 		as.Err = err
@@ -60,42 +64,442 @@ func (c *Check) Scale(ctx context.Context, groupID string) *ActionStatus {
 
 	newCount := policy.CalculateSize(count, value)
 
+	threshold, comparison := policy.Trigger(value)
+	as.Decision = &Decision{
+		Reason:      DecisionPolicy,
+		PolicyType:  group.PolicyType,
+		Metric:      group.MetricType,
+		MetricValue: value,
+		Threshold:   threshold,
+		Comparison:  comparison,
+	}
+
 	delta := newCount - count
+	if delta == 0 {
+		as.Decision.recordOperation(nil, started)
+		as.Count = count
+		return as
+	}
 
-	changed, err := resource.Scale(ctx, *group, delta, c.repo)
+	op, err := c.runOperation(ctx, group, resource, delta)
+	as.Decision.recordOperation(op, started)
 	if err != nil {
 		as.Err = err
 		return as
 	}
 
-	if changed {
-		log.WithFields(logrus.Fields{
-			"metric":       group.MetricType,
-			"metric-value": value,
-			"new-count":    newCount,
-			"delta":        delta,
-		}).Info("group change status")
+	log.WithFields(logrus.Fields{
+		"metric":       group.MetricType,
+		"metric-value": value,
+		"new-count":    newCount,
+		"delta":        op.ActualDelta,
+		"operation-id": op.ID,
+	}).Info("group change status")
 
-		if err := group.MetricNotify(); err != nil {
-			log.WithError(err).Error("notifying metric of current config")
+	as.Delta = op.ActualDelta
+	as.Count = count + op.ActualDelta
+	return as
+}
+
+// Resize scales the group identified by groupID to size. The size is limited by the
+// group's policy.
+func (c *Check) Resize(ctx context.Context, groupID string, size int) *ActionStatus {
+	log := ctxutil.LogFromContext(ctx).WithField("group-id", groupID)
+	started := time.Now()
+
+	as := &ActionStatus{
+		Done: make(chan bool, 1),
+	}
+
+	defer func() {
+		as.Done <- true
+	}()
+
+	group, err := c.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	defer c.reconcile(ctx, group, resource, as)
+
+	count, err := resource.Count()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	newCount := group.Policy.Clamp(size)
+
+	as.Decision = &Decision{
+		Reason:        DecisionResize,
+		PolicyType:    group.PolicyType,
+		RequestedSize: size,
+	}
+
+	delta := newCount - count
+	if delta == 0 {
+		as.Decision.recordOperation(nil, started)
+		as.Count = count
+		return as
+	}
+
+	op, err := c.runOperation(ctx, group, resource, delta)
+	as.Decision.recordOperation(op, started)
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	log.WithFields(logrus.Fields{
+		"requested-size": size,
+		"new-count":      newCount,
+		"delta":          op.ActualDelta,
+		"operation-id":   op.ID,
+	}).Info("group resized")
+
+	as.Delta = op.ActualDelta
+	as.Count = count + op.ActualDelta
+	return as
+}
+
+// Attach adopts the resource identified by resourceID into the group identified by
+// groupID. If adjustCount is false, another resource is removed once it is attached so
+// the size of the group doesn't change. Adopted droplets of a group with a load balancer
+// are added to it like the droplets a scale up creates, and get DNS records once they
+// are. They don't get the group's template tag, so a refresh replaces them.
+func (c *Check) Attach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus {
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"group-id":    groupID,
+		"resource-id": resourceID,
+	})
+	started := time.Now()
+
+	as := &ActionStatus{
+		Done: make(chan bool, 1),
+	}
+
+	defer func() {
+		as.Done <- true
+	}()
+
+	group, err := c.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	defer c.reconcile(ctx, group, resource, as)
+
+	count, err := resource.Count()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	as.Decision = &Decision{Reason: DecisionAttach, ResourceID: resourceID}
+
+	// the resource is attached before anything is removed to make room for it, so a
+	// failed attach doesn't shrink the group.
+	log.Info("attaching resource")
+	if err := resource.Attach(ctx, resourceID); err != nil {
+		as.Err = err
+		return as
+	}
+
+	existing := count
+	count++
+	as.Decision.recordOperation(nil, started)
+	c.recordMembershipChange(ctx, groupID, 1, count, as.Decision)
+
+	if group.LoadBalancerID != "" {
+		c.registerAttached(ctx, group, resourceID)
+	}
+
+	if !adjustCount && existing > 0 {
+		op := NewOperation(group.ID, -1)
+		if id, err := strconv.Atoi(resourceID); err == nil {
+			op.Keep = []int{id}
+		}
+
+		op, err := c.runPlannedOperation(ctx, group, resource, op)
+		as.Decision.recordOperation(op, started)
+		if err != nil {
 			as.Err = err
+			as.Count = count
 			return as
 		}
 
-		wup := policy.WarmUpPeriod()
-		log.WithField("warm-up-duration", wup).Info("waiting for new service to warm up")
-		time.Sleep(wup)
-		log.Info("new service has warmed up")
+		as.Delta = op.ActualDelta
+		count += op.ActualDelta
+	}
+
+	if err := group.MetricNotify(); err != nil {
+		log.WithError(err).Error("notifying metric of current config")
+		as.Err = err
+	}
+
+	as.Count = count
+	return as
+}
+
+// Detach removes the resource identified by resourceID from the group identified by
+// groupID without deleting it. If adjustCount is false, a replacement resource is
+// created so the size of the group doesn't change.
+func (c *Check) Detach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus {
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"group-id":    groupID,
+		"resource-id": resourceID,
+	})
+	started := time.Now()
+
+	as := &ActionStatus{
+		Done: make(chan bool, 1),
+	}
+
+	defer func() {
+		as.Done <- true
+	}()
+
+	group, err := c.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	defer c.reconcile(ctx, group, resource, as)
+
+	count, err := resource.Count()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	as.Decision = &Decision{Reason: DecisionDetach, ResourceID: resourceID}
+
+	log.Info("detaching resource")
+	if err := resource.Detach(ctx, resourceID); err != nil {
+		as.Err = err
+		return as
+	}
+
+	count--
+	as.Decision.recordOperation(nil, started)
+	c.recordMembershipChange(ctx, groupID, -1, count, as.Decision)
+
+	if err := group.MetricNotify(); err != nil {
+		log.WithError(err).Error("notifying metric of current config")
+		as.Err = err
+		as.Count = count
+		return as
+	}
+
+	if !adjustCount {
+		op, err := c.runOperation(ctx, group, resource, 1)
+		as.Decision.recordOperation(op, started)
+		if err != nil {
+			as.Err = err
+			as.Count = count
+			return as
+		}
+
+		as.Delta = op.ActualDelta
+		count += op.ActualDelta
 	}
 
-	as.Delta = delta
-	as.Count = newCount
+	as.Count = count
 	return as
 }
 
+// Refresh replaces the resources in the group identified by groupID in batches, as
+// described by the refresh identified by refreshID. Each batch creates new resources,
+// waits for them to warm up, be added to the group's load balancer and become healthy,
+// then removes the resources they replace. The refresh state is checked between batches so it can be paused, cancelled
+// or rolled back while it runs.
+func (c *Check) Refresh(ctx context.Context, groupID, refreshID string) *ActionStatus {
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"group-id":   groupID,
+		"refresh-id": refreshID,
+	})
+
+	as := &ActionStatus{
+		Done: make(chan bool, 1),
+	}
+
+	defer func() {
+		as.Done <- true
+	}()
+
+	group, err := c.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		as.Err = err
+		return as
+	}
+
+	defer c.reconcile(ctx, group, resource, as)
+
+	for {
+		refresh, err := c.repo.GetRefresh(ctx, refreshID)
+		if err != nil {
+			as.Err = err
+			return as
+		}
+
+		if !refresh.State.IsActive() {
+			log.WithField("state", refresh.State).Info("refresh is not active")
+			break
+		}
+
+		done, err := c.refreshBatch(ctx, group, resource, refresh)
+		if err != nil {
+			log.WithError(err).Error("refresh failed")
+			if failErr := refresh.Fail(ctx, c.repo, err); failErr != nil {
+				log.WithError(failErr).Error("unable to mark refresh as failed")
+			}
+
+			as.Err = err
+			break
+		}
+
+		if done {
+			state := RefreshDone
+			if refresh.State == RefreshRollingBack {
+				state = RefreshRolledBack
+			}
+
+			log.WithField("state", state).Info("refresh complete")
+			if err := refresh.Transition(ctx, c.repo, state); err != nil {
+				as.Err = err
+			}
+
+			break
+		}
+	}
+
+	count, err := resource.Count()
+	if err != nil && as.Err == nil {
+		as.Err = err
+	}
+
+	as.Count = count
+	return as
+}
+
+// refreshBatch replaces one batch of resources for a refresh. It returns true if there
+// are no resources left to replace.
+func (c *Check) refreshBatch(ctx context.Context, group *Group, resource ResourceManager, refresh *Refresh) (bool, error) {
+	templateID, templateVersion := refresh.TargetTemplate()
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"group-id":         group.ID,
+		"refresh-id":       refresh.ID,
+		"template-id":      templateID,
+		"template-version": templateVersion,
+	})
+
+	allocations, err := resource.Allocated()
+	if err != nil {
+		return false, err
+	}
+
+	outdated := []string{}
+	for _, a := range allocations {
+		if (a.TemplateID != templateID || a.TemplateVersion != templateVersion) && !a.Protected {
+			outdated = append(outdated, a.ID)
+		}
+	}
+
+	if len(outdated) == 0 {
+		return true, nil
+	}
+
+	byN := refresh.BatchSize
+	if byN > len(outdated) {
+		byN = len(outdated)
+	}
+
+	log.WithFields(logrus.Fields{
+		"outdated": len(outdated),
+		"by-n":     byN,
+	}).Info("replacing batch of resources")
+
+	g := *group
+	g.TemplateID = templateID
+	g.TemplateVersion = templateVersion
+
+	op, err := c.runOperation(ctx, &g, resource, byN)
+	if err != nil {
+		return false, err
+	}
+
+	created := []string{}
+	for _, id := range op.DropletIDs {
+		created = append(created, strconv.Itoa(id))
+	}
+
+	healthy := false
+	if err = c.waitForRegistration(ctx, &g, resource, op); err == nil {
+		healthy, err = resource.Healthy(ctx, created)
+	}
+
+	if err == nil && (!healthy || op.ActualDelta < byN) {
+		err = ErrUnhealthyResources
+	}
+
+	if err != nil {
+		if op.State == OperationRegistering {
+			c.failOperation(ctx, op, err)
+		}
+
+		log.WithError(err).Error("removing new resources from failed batch")
+		if removeErr := resource.Remove(ctx, created); removeErr != nil {
+			log.WithError(removeErr).Error("unable to remove new resources")
+		}
+
+		return false, err
+	}
+
+	if err := resource.Remove(ctx, outdated[:byN]); err != nil {
+		return false, err
+	}
+
+	if err := group.MetricNotify(); err != nil {
+		return false, err
+	}
+
+	refresh.ReplacedIDs = append(refresh.ReplacedIDs, outdated[:byN]...)
+	refresh.CreatedIDs = append(refresh.CreatedIDs, created...)
+
+	return false, c.repo.SaveRefreshProgress(ctx, *refresh)
+}
+
 // Disable the group identified by groupID.
 func (c *Check) Disable(ctx context.Context, groupID string) *ActionStatus {
 	log := ctxutil.LogFromContext(ctx).WithField("group-id", groupID)
+	started := time.Now()
 
 	as := &ActionStatus{
 		Done: make(chan bool, 1),
@@ -117,6 +521,8 @@ func (c *Check) Disable(ctx context.Context, groupID string) *ActionStatus {
 		return as
 	}
 
+	defer c.reconcile(ctx, group, resource, as)
+
 	count, err := resource.Count()
 	if err != nil {
 		as.Err = err
@@ -125,20 +531,222 @@ func (c *Check) Disable(ctx context.Context, groupID string) *ActionStatus {
 
 	log.Info("disabling group by removing all resources")
 
+	as.Decision = &Decision{Reason: DecisionDisable, PolicyType: group.PolicyType}
+
+	if count > 0 {
+		op := NewOperation(group.ID, 0-count)
+		op.Teardown = true
+
+		op, err := c.runPlannedOperation(ctx, group, resource, op)
+		as.Decision.recordOperation(op, started)
+		if err != nil {
+			as.Err = err
+			return as
+		}
+
+		as.Delta = op.ActualDelta
+	}
+
 	if err := group.Disable(ctx); err != nil {
 		as.Err = err
 		return as
 	}
 
-	toRemove := 0 - count
-	_, err = resource.Scale(ctx, *group, toRemove, c.repo)
+	as.Count = count + as.Delta
+
+	return as
+}
+
+// reconcile makes sure the group's DNS records and floating IP point at healthy
+// resources. Each floating IP reassignment is recorded as an activity. Failures are
+// logged but don't fail the action.
+func (c *Check) reconcile(ctx context.Context, group *Group, resource ResourceManager, as *ActionStatus) {
+	log := ctxutil.LogFromContext(ctx).WithField("group-id", group.ID)
+
+	if group.LoadBalancerID != "" {
+		c.advanceRegistrations(ctx, group, resource)
+	}
+
+	if group.DNS.IsEnabled() {
+		if err := resource.ReconcileDNS(ctx); err != nil {
+			log.WithError(err).WithField("domain", group.DNS.Domain).Error("unable to reconcile dns records")
+		}
+	}
+
+	if group.FloatingIP == "" {
+		return
+	}
+
+	changes, err := resource.ReconcileFloatingIP(ctx)
 	if err != nil {
-		as.Err = err
-		return as
+		log.WithError(err).WithField("floating-ip", group.FloatingIP).Error("unable to reconcile floating ip")
 	}
 
-	as.Delta = toRemove
-	as.Count = 0
+	for _, change := range changes {
+		as.Activities = append(as.Activities, SchedulerActivity{
+			ID:      group.ID,
+			Kind:    ActivityFloatingIPReassigned,
+			Message: change.String(),
+			Count:   as.Count,
+		})
+	}
+}
 
-	return as
+// advanceRegistrations runs a round of health checks for the group's operations which
+// are adding droplets to its load balancer.
+func (c *Check) advanceRegistrations(ctx context.Context, group *Group, resource ResourceManager) {
+	log := ctxutil.LogFromContext(ctx).WithField("group-id", group.ID)
+
+	ops, err := c.repo.ListUnfinishedOperations(ctx)
+	if err != nil {
+		log.WithError(err).Error("unable to list unfinished operations")
+		return
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		if op.GroupID != group.ID || op.State != OperationRegistering {
+			continue
+		}
+
+		if err := resource.Resume(ctx, *group, op, c.repo); err != nil {
+			log.WithError(err).WithField("operation-id", op.ID).Error("unable to add droplets to load balancer")
+		}
+	}
+}
+
+// waitForRegistration runs rounds of health checks for an operation which is adding
+// droplets to the group's load balancer until it is done. Refreshes can't check the
+// health of a batch before its droplets are behind the load balancer.
+func (c *Check) waitForRegistration(ctx context.Context, group *Group, resource ResourceManager, op *Operation) error {
+	for op.State == OperationRegistering {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lbRegistrationDelay):
+		}
+
+		if err := resource.Resume(ctx, *group, op, c.repo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerAttached records an operation which adds an adopted droplet to the group's
+// load balancer. It is advanced by later checks like the operations of scale ups.
+// Failures are logged but don't fail the attach.
+func (c *Check) registerAttached(ctx context.Context, group *Group, resourceID string) {
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"group-id":    group.ID,
+		"resource-id": resourceID,
+	})
+
+	id, err := strconv.Atoi(resourceID)
+	if err != nil {
+		return
+	}
+
+	planned := NewOperation(group.ID, 1)
+	planned.AddDropletID(id)
+
+	op, err := c.repo.CreateOperation(ctx, *planned)
+	if err != nil {
+		log.WithError(err).Error("unable to record operation")
+		return
+	}
+
+	if err := op.Transition(ctx, c.repo, OperationRegistering); err != nil {
+		log.WithError(err).Error("unable to start adding droplet to load balancer")
+	}
+}
+
+// runOperation records an operation for a group and runs it to completion.
+func (c *Check) runOperation(ctx context.Context, group *Group, resource ResourceManager, delta int) (*Operation, error) {
+	return c.runPlannedOperation(ctx, group, resource, NewOperation(group.ID, delta))
+}
+
+// runPlannedOperation records and runs an operation which was planned by the caller.
+func (c *Check) runPlannedOperation(ctx context.Context, group *Group, resource ResourceManager, planned *Operation) (*Operation, error) {
+	log := ctxutil.LogFromContext(ctx).WithField("group-id", group.ID)
+
+	op, err := c.repo.CreateOperation(ctx, *planned)
+	if err != nil {
+		log.WithError(err).Error("unable to record operation")
+		return nil, err
+	}
+
+	log = log.WithField("operation-id", op.ID)
+
+	changed, err := resource.Scale(ctx, *group, op, c.repo)
+	if err != nil {
+		c.failOperation(ctx, op, err)
+		return nil, err
+	}
+
+	// operations adding droplets to a load balancer are finished by later checks.
+	registering := op.State == OperationRegistering
+
+	if op.ActualDelta != 0 {
+		if !registering {
+			if err := op.Transition(ctx, c.repo, OperationNotifyingMetrics); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := group.MetricNotify(); err != nil {
+			log.WithError(err).Error("notifying metric of current config")
+			c.failOperation(ctx, op, err)
+			return nil, err
+		}
+	}
+
+	if changed {
+		if !registering {
+			if err := op.Transition(ctx, c.repo, OperationWarming); err != nil {
+				return nil, err
+			}
+		}
+
+		wup := group.Policy.WarmUpPeriod()
+		log.WithField("warm-up-duration", wup).Info("waiting for new service to warm up")
+		time.Sleep(wup)
+		log.Info("new service has warmed up")
+	}
+
+	if registering {
+		return op, nil
+	}
+
+	if err := op.Transition(ctx, c.repo, OperationDone); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// recordMembershipChange records a resource being attached to or detached from a group
+// in the group's history.
+func (c *Check) recordMembershipChange(ctx context.Context, groupID string, delta, total int, decision *Decision) {
+	gs := GroupStatus{
+		GroupID:   groupID,
+		Kind:      ActivityScaled,
+		Delta:     delta,
+		Total:     total,
+		Decision:  decision,
+		CreatedAt: time.Now(),
+	}
+
+	if err := c.repo.AddGroupStatus(ctx, gs); err != nil {
+		log := ctxutil.LogFromContext(ctx).WithField("group-id", groupID)
+		log.WithError(err).Error("unable to add group status")
+	}
+}
+
+func (c *Check) failOperation(ctx context.Context, op *Operation, err error) {
+	if opErr := op.Fail(ctx, c.repo, err); opErr != nil {
+		log := ctxutil.LogFromContext(ctx).WithField("operation-id", op.ID)
+		log.WithError(opErr).Error("unable to mark operation as failed")
+	}
 }
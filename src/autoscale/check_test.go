@@ -62,6 +62,7 @@ func TestCheckScale(t *testing.T) {
 			Policy:     policy,
 		}
 		repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+		expectOperations(repo)
 
 		metricPath := filepath.Join(tmpPath, group.Name)
 		err = ioutil.WriteFile(metricPath, []byte(c.currentLoad), 0600)
@@ -117,6 +118,7 @@ func TestCheckDisable(t *testing.T) {
 		Metric:     metric,
 	}
 	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	expectOperations(repo)
 
 	check := NewCheck(repo)
 	as := check.Disable(ctx, "id")
@@ -125,3 +127,362 @@ func TestCheckDisable(t *testing.T) {
 	assert.Equal(t, -3, as.Delta)
 	assert.Equal(t, 0, as.Count)
 }
+
+func TestCheckScale_RecordsOperation(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	ogDefaultConfig := DefaultConfig
+	defer func() {
+		ResourceManagerFactory = ogFactory
+		DefaultConfig = ogDefaultConfig
+	}()
+
+	tmpPath, err := ioutil.TempDir("", "autoscaler")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpPath)
+
+	DefaultConfig[OptionFileLoadPath] = tmpPath
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		r := NewLocalResource(ctx)
+		r.(*LocalResource).count = 3
+		return r, nil
+	}
+
+	policy, err := NewValuePolicy(ValuePolicyScale(
+		1, 10, 0.8, 2, 0.2, 1,
+	))
+	require.NoError(t, err)
+
+	group := &Group{
+		ID:         "id",
+		Name:       "test-group",
+		MetricType: "load",
+		PolicyType: "value",
+		Policy:     policy,
+	}
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	expectOperations(repo)
+
+	err = ioutil.WriteFile(filepath.Join(tmpPath, group.Name), []byte("0.9"), 0600)
+	require.NoError(t, err)
+
+	check := NewCheck(repo)
+	as := check.Scale(ctx, "id")
+	require.NoError(t, as.Err)
+
+	states := []OperationState{}
+	for _, call := range repo.Calls {
+		if call.Method == "SaveOperation" {
+			op := call.Arguments.Get(1).(Operation)
+			states = append(states, op.State)
+		}
+	}
+
+	expected := []OperationState{
+		OperationNotifyingMetrics,
+		OperationWarming,
+		OperationDone,
+	}
+	assert.Equal(t, expected, states)
+
+	require.NotNil(t, as.Decision)
+	assert.Equal(t, DecisionPolicy, as.Decision.Reason)
+	assert.Equal(t, "value", as.Decision.PolicyType)
+	assert.Equal(t, "load", as.Decision.Metric)
+	assert.Equal(t, 0.9, as.Decision.MetricValue)
+	assert.Equal(t, 0.8, as.Decision.Threshold)
+	assert.Equal(t, ">=", as.Decision.Comparison)
+	assert.True(t, as.Decision.Duration > 0)
+}
+
+func expectOperations(repo *MockRepository) {
+	repo.On("CreateOperation", mock.Anything, mock.AnythingOfType("autoscale.Operation")).
+		Return(func(ctx context.Context, op Operation) *Operation {
+			op.ID = "operation-id"
+			return &op
+		}, nil)
+	repo.On("SaveOperation", mock.Anything, mock.AnythingOfType("autoscale.Operation")).Return(nil)
+}
+
+func TestCheckAttach(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	r := NewLocalResource(ctx)
+	r.(*LocalResource).count = 3
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return r, nil
+	}
+
+	policy, err := NewValuePolicy(ValuePolicyScale(1, 10, 0.8, 2, 0.2, 1))
+	require.NoError(t, err)
+
+	group := &Group{ID: "id", Name: "test-group", MetricType: "load", Policy: policy}
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	repo.On("AddGroupStatus", mock.Anything, mock.MatchedBy(func(gs GroupStatus) bool {
+		return gs.Delta == 1 && gs.Total == 4 &&
+			gs.Decision.Reason == DecisionAttach && gs.Decision.ResourceID == "12345"
+	})).Return(nil)
+	expectOperations(repo)
+
+	check := NewCheck(repo)
+	as := check.Attach(ctx, "id", "12345", false)
+	require.NoError(t, as.Err)
+
+	require.Equal(t, -1, as.Delta)
+	require.Equal(t, 3, as.Count)
+	assert.True(t, repo.AssertExpectations(t))
+}
+
+func TestCheckAttach_LoadBalancer(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	rm := &MockResourceManager{}
+	rm.On("Count").Return(0, nil)
+	rm.On("Attach", ctx, "12345").Return(nil)
+	rm.On("Allocated").Return([]ResourceAllocation{{ID: "12345"}}, nil)
+	rm.On("Resume", ctx, mock.AnythingOfType("autoscale.Group"), mock.AnythingOfType("*autoscale.Operation"), mock.Anything).Return(nil)
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return rm, nil
+	}
+
+	group := &Group{ID: "id", Name: "test-group", MetricType: "load", LoadBalancerID: "lb-1"}
+
+	var registering Operation
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	repo.On("AddGroupStatus", mock.Anything, mock.AnythingOfType("autoscale.GroupStatus")).Return(nil)
+	repo.On("CreateOperation", mock.Anything, mock.AnythingOfType("autoscale.Operation")).
+		Return(func(ctx context.Context, op Operation) *Operation {
+			op.ID = "operation-id"
+			return &op
+		}, nil)
+	repo.On("SaveOperation", mock.Anything, mock.AnythingOfType("autoscale.Operation")).
+		Run(func(args mock.Arguments) { registering = args.Get(1).(Operation) }).Return(nil)
+	repo.On("ListUnfinishedOperations", mock.Anything).
+		Return(func(ctx context.Context) []Operation { return []Operation{registering} }, nil)
+
+	as := NewCheck(repo).Attach(ctx, "id", "12345", true)
+	require.NoError(t, as.Err)
+
+	require.Equal(t, OperationRegistering, registering.State)
+	require.Equal(t, DropletIDs{12345}, registering.DropletIDs)
+	rm.AssertNumberOfCalls(t, "Resume", 1)
+}
+
+func TestCheckAttach_FailureKeepsGroup(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	ctx := context.Background()
+
+	rm := &MockResourceManager{}
+	rm.On("Count").Return(3, nil)
+	rm.On("Attach", ctx, "12345").Return(ErrResourceInGroup)
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return rm, nil
+	}
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(&Group{ID: "id", Name: "test-group"}, nil)
+
+	check := NewCheck(repo)
+	as := check.Attach(ctx, "id", "12345", false)
+	require.Equal(t, ErrResourceInGroup, as.Err)
+	require.Equal(t, 0, as.Delta)
+
+	rm.AssertNotCalled(t, "Scale", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "CreateOperation", mock.Anything, mock.Anything)
+}
+
+func TestCheckDetach(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	r := NewLocalResource(ctx)
+	r.(*LocalResource).count = 3
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return r, nil
+	}
+
+	group := &Group{ID: "id", Name: "test-group", MetricType: "load"}
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	repo.On("AddGroupStatus", mock.Anything, mock.MatchedBy(func(gs GroupStatus) bool {
+		return gs.Delta == -1 && gs.Total == 2 && gs.Decision.Reason == DecisionDetach
+	})).Return(nil)
+
+	check := NewCheck(repo)
+	as := check.Detach(ctx, "id", "2", true)
+	require.NoError(t, as.Err)
+	require.Equal(t, 0, as.Delta)
+	require.Equal(t, 2, as.Count)
+
+	as = check.Detach(ctx, "id", "9", true)
+	require.Equal(t, ErrResourceNotInGroup, as.Err)
+
+	assert.True(t, repo.AssertExpectations(t))
+}
+
+func TestCheckRefresh(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	r := NewLocalResource(ctx)
+	r.(*LocalResource).count = 3
+	r.(*LocalResource).templateID = "tmpl"
+	r.(*LocalResource).templateVersion = 1
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return r, nil
+	}
+
+	policy, err := NewValuePolicy(ValuePolicyScale(1, 10, 0.8, 2, 0.2, 1))
+	require.NoError(t, err)
+
+	group := &Group{ID: "id", Name: "test-group", MetricType: "load", TemplateID: "tmpl", Policy: policy}
+
+	refresh, err := NewRefresh("id", Template{ID: "tmpl", Version: 2}, Template{ID: "tmpl", Version: 1}, 3, 50)
+	require.NoError(t, err)
+	refresh.ID = "refresh-id"
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	repo.On("GetRefresh", mock.Anything, "refresh-id").Return(refresh, nil)
+	repo.On("SaveRefreshProgress", mock.Anything, mock.AnythingOfType("autoscale.Refresh")).Return(nil)
+	repo.On("UpdateRefreshState", mock.Anything, "refresh-id", RefreshDone).Return(nil)
+	expectOperations(repo)
+
+	check := NewCheck(repo)
+	as := check.Refresh(ctx, "id", "refresh-id")
+	require.NoError(t, as.Err)
+
+	require.Equal(t, 3, as.Count)
+	require.Equal(t, RefreshDone, refresh.State)
+	require.Equal(t, ResourceIDs{"1"}, refresh.ReplacedIDs)
+
+	allocations, err := r.Allocated()
+	require.NoError(t, err)
+	for _, a := range allocations {
+		require.Equal(t, 2, a.TemplateVersion)
+	}
+
+	assert.True(t, repo.AssertExpectations(t))
+}
+
+func TestCheckRefresh_LoadBalancer(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	defer func() {
+		ResourceManagerFactory = ogFactory
+	}()
+
+	delay := lbRegistrationDelay
+	lbRegistrationDelay = 0
+	defer func() { lbRegistrationDelay = delay }()
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	policy, err := NewValuePolicy(ValuePolicyScale(1, 10, 0.8, 2, 0.2, 0))
+	require.NoError(t, err)
+
+	group := &Group{ID: "id", Name: "test-group", MetricType: "load", TemplateID: "tmpl", LoadBalancerID: "lb-1", Policy: policy}
+
+	refresh, err := NewRefresh("id", Template{ID: "tmpl", Version: 2}, Template{ID: "tmpl", Version: 1}, 1, 0)
+	require.NoError(t, err)
+	refresh.ID = "refresh-id"
+
+	rm := &MockResourceManager{}
+	rm.On("Allocated").Return([]ResourceAllocation{{ID: "1", TemplateID: "tmpl", TemplateVersion: 1}}, nil).Once()
+	rm.On("Allocated").Return([]ResourceAllocation{{ID: "2", TemplateID: "tmpl", TemplateVersion: 2}}, nil)
+	rm.On("Scale", ctx, mock.AnythingOfType("autoscale.Group"), mock.AnythingOfType("*autoscale.Operation"), mock.Anything).
+		Return(func(ctx context.Context, g Group, op *Operation, repo Repository) bool {
+			op.AddDropletID(2)
+			op.ActualDelta = 1
+			op.State = OperationRegistering
+			return true
+		}, nil)
+
+	// the droplet is added to the load balancer on the second round of checks.
+	rm.On("Resume", ctx, mock.AnythingOfType("autoscale.Group"), mock.AnythingOfType("*autoscale.Operation"), mock.Anything).
+		Return(nil).Once()
+	rm.On("Resume", ctx, mock.AnythingOfType("autoscale.Group"), mock.AnythingOfType("*autoscale.Operation"), mock.Anything).
+		Return(func(ctx context.Context, g Group, op *Operation, repo Repository) error {
+			return op.Transition(ctx, repo, OperationDone)
+		}).Once()
+	rm.On("Healthy", ctx, []string{"2"}).Return(true, nil)
+	rm.On("Remove", ctx, []string{"1"}).Return(nil)
+	rm.On("Count").Return(1, nil)
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return rm, nil
+	}
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "id").Return(group, nil)
+	repo.On("GetRefresh", mock.Anything, "refresh-id").Return(refresh, nil)
+	repo.On("SaveRefreshProgress", mock.Anything, mock.AnythingOfType("autoscale.Refresh")).Return(nil)
+	repo.On("UpdateRefreshState", mock.Anything, "refresh-id", RefreshDone).Return(nil)
+	repo.On("ListUnfinishedOperations", mock.Anything).Return([]Operation{}, nil)
+	expectOperations(repo)
+
+	as := NewCheck(repo).Refresh(ctx, "id", "refresh-id")
+	require.NoError(t, as.Err)
+
+	require.Equal(t, RefreshDone, refresh.State)
+	require.Equal(t, ResourceIDs{"1"}, refresh.ReplacedIDs)
+	require.Equal(t, ResourceIDs{"2"}, refresh.CreatedIDs)
+
+	rm.AssertNumberOfCalls(t, "Resume", 2)
+	assert.True(t, rm.AssertExpectations(t))
+}
+
+func TestCheckAdvanceRegistrations(t *testing.T) {
+	ctx := context.Background()
+
+	group := &Group{ID: "abc", LoadBalancerID: "lb-1"}
+
+	repo := &MockRepository{}
+	repo.On("ListUnfinishedOperations", ctx).Return([]Operation{
+		{ID: "1", GroupID: "abc", State: OperationRegistering},
+		{ID: "2", GroupID: "abc", State: OperationWarming},
+		{ID: "3", GroupID: "other", State: OperationRegistering},
+	}, nil)
+
+	rm := &MockResourceManager{}
+	rm.On("Resume", ctx, *group, mock.AnythingOfType("*autoscale.Operation"), repo).Return(nil)
+
+	NewCheck(repo).advanceRegistrations(ctx, group, rm)
+
+	rm.AssertNumberOfCalls(t, "Resume", 1)
+	require.Equal(t, "1", rm.Calls[0].Arguments.Get(2).(*Operation).ID)
+}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/net/context"
 
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -33,6 +34,9 @@ type Specification struct {
 	UseMemoryResources     bool   `envconfig:"use_memory_resources" default:"false"`
 	WebPassword            string `envconfig:"web_password" required:"true"`
 	Tag                    string `envconfig:"tag" default:"autoscale"`
+	NotificationSinks      string `envconfig:"notification_sinks"`
+	AllowedOrigins         string `envconfig:"allowed_origins"`
+	UsePrivateNetworking   bool   `envconfig:"use_private_networking" default:"false"`
 }
 
 func main() {
@@ -54,6 +58,7 @@ func main() {
 	}
 
 	autoscale.BaseTag = s.Tag
+	autoscale.UsePrivateNetworking = s.UsePrivateNetworking
 
 	if s.RegisterDefaultMetrics && s.RegisterOfflineMetrics {
 		log.Fatal("can't specify offline and default metrics at the same time")
@@ -73,13 +78,26 @@ func main() {
 		log.WithError(err).Fatal("unable to initialize repository")
 	}
 
-	notify, err := initScheduler(ctx, repo, log)
+	log.Info("resuming unfinished operations")
+	if err := autoscale.ResumeOperations(ctx, repo); err != nil {
+		log.WithError(err).Fatal("unable to resume unfinished operations")
+	}
+
+	notify, schedulerStatus, err := initScheduler(ctx, s, repo, log)
 	if err != nil {
 		log.WithError(err).Fatal("unable to initialize scheduler")
 	}
 
+	log.Info("resuming unfinished refreshes")
+	if err := autoscale.ResumeRefreshes(ctx, repo, schedulerStatus); err != nil {
+		log.WithError(err).Fatal("unable to resume unfinished refreshes")
+	}
+
 	api.WebPassword = s.WebPassword
-	a := api.New(ctx, repo, notify)
+	if s.AllowedOrigins != "" {
+		api.AllowedOrigins = strings.Split(s.AllowedOrigins, ",")
+	}
+	a := api.New(ctx, repo, notify, schedulerStatus)
 
 	log.WithFields(logrus.Fields{
 		"http-addr": s.HTTPAddr,
@@ -130,11 +148,11 @@ func initRepository(ctx context.Context, s Specification, log *logrus.Entry) (au
 	return repo, nil
 }
 
-func initScheduler(ctx context.Context, repo autoscale.Repository, log *logrus.Entry) (*autoscale.Notify, error) {
+func initScheduler(ctx context.Context, s Specification, repo autoscale.Repository, log *logrus.Entry) (*autoscale.Notify, *autoscale.SchedulerStatus, error) {
 	monitor, err := autoscale.NewMonitor(ctx, repo)
 	if err != nil {
 		log.WithError(err).Error("unable to setup group monitor")
-		return nil, err
+		return nil, nil, err
 	}
 
 	groupCheck := autoscale.NewCheck(repo)
@@ -145,18 +163,36 @@ func initScheduler(ctx context.Context, repo autoscale.Repository, log *logrus.E
 	dbStatus := autoscale.NewStatus(ctx, repo)
 	notify := autoscale.NewNotify(ctx, repo)
 
+	sinks, err := autoscale.ParseSinkConfigs(s.NotificationSinks)
+	if err != nil {
+		log.WithError(err).Error("unable to read notification sinks")
+		return nil, nil, err
+	}
+
+	if err := notify.AddSinks(sinks); err != nil {
+		log.WithError(err).Error("unable to setup notification sinks")
+		return nil, nil, err
+	}
+
 	log.Info("starting group monitor")
 	go monitor.Start(schedulerStatus)
 	log.Info("starting scheduler")
 	go scheduler.Start()
-	log.Info("starting activity manager")
-	go activityManager.Start()
 	log.Info("starting logging status to db")
-	activityManager.RegisterListener(dbStatus.ActivityListener)
+	activityManager.RegisterListener(dbStatus.ActivityListener, autoscale.ListenerOptions{
+		Name:  "status",
+		Kinds: autoscale.StatusActivityKinds,
+	})
 	go dbStatus.Start()
 	log.Info("starting notification manager")
-	activityManager.RegisterListener(notify.ActivityListener)
+	activityManager.RegisterListener(notify.ActivityListener, autoscale.ListenerOptions{
+		Name:     "notify",
+		Kinds:    autoscale.NotifyActivityKinds,
+		Overflow: autoscale.OverflowBuffer,
+	})
 	go notify.Start()
+	log.Info("starting activity manager")
+	go activityManager.Start()
 
-	return notify, nil
+	return notify, schedulerStatus, nil
 }
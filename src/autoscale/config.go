@@ -18,6 +18,9 @@ var (
 	// ErrDisabledGroup is return if the group is disabled.
 	ErrDisabledGroup = fmt.Errorf("group is disabled")
 
+	// ErrGroupBusy is returned if the group already has an action running.
+	ErrGroupBusy = fmt.Errorf("group has an action running")
+
 	// ScheduleReenqueueTimeout is how long to wait when reenqueuing a check.
 	ScheduleReenqueueTimeout = 10 * time.Second
 
@@ -34,7 +37,15 @@ var (
 		doClient := DOClientFactory()
 		tagName := tagNameFn(g.Name)
 		log := logrus.WithField("group-id", g.ID)
-		return NewDropletResource(doClient, tagName, log)
+		r, err := NewDropletResource(doClient, tagName, log)
+		if err != nil {
+			return nil, err
+		}
+
+		r.loadBalancerID = g.LoadBalancerID
+		r.floatingIP = g.FloatingIP
+		r.dns = g.DNS
+		return r, nil
 	}
 
 	// DOClientFactory createsa  do client.
@@ -54,10 +65,58 @@ var (
 
 	nameRe = regexp.MustCompile(`^\w[A-Za-z0-9\-]*$`)
 
-	prometheusAgentPort = 9100
-
 	// BaseTag is the tag applied to all droplets created by autoscale.
 	BaseTag = "autoscale"
+
+	// ProtectedTag is the tag applied to droplets which are protected from scale in.
+	ProtectedTag = "autoscale:protected"
+
+	// ErrResourceNotInGroup is returned when a resource doesn't belong to a group.
+	ErrResourceNotInGroup = fmt.Errorf("resource is not part of group")
+
+	// TemplateTagPrefix is the prefix of the tag which records the template a droplet was built from.
+	TemplateTagPrefix = "autoscale:template:"
+
+	// VolumesTag is the tag applied to droplets which have volumes created by autoscale.
+	VolumesTag = "autoscale:volumes"
+
+	// volumeDeleteAttempts is the amount of times deleting a volume is attempted while it
+	// is detached from a deleted droplet.
+	volumeDeleteAttempts = 12
+
+	// volumeDeleteDelay is the delay between attempts to delete a volume.
+	volumeDeleteDelay = 5 * time.Second
+
+	// ErrTemplateInUse is returned when deleting a template which groups are using.
+	ErrTemplateInUse = fmt.Errorf("template is used by one or more groups")
+
+	// ErrRefreshInProgress is returned when a group already has an unfinished refresh.
+	ErrRefreshInProgress = fmt.Errorf("group has a refresh in progress")
+
+	// ErrInvalidRefreshTransition is returned when a refresh can't move to the requested state.
+	ErrInvalidRefreshTransition = fmt.Errorf("refresh can't move to requested state")
+
+	// ErrUnhealthyResources is returned when new resources are not healthy after warming up.
+	ErrUnhealthyResources = fmt.Errorf("resources are not healthy")
+
+	// ErrResourceInGroup is returned when attaching a resource which already belongs to a group.
+	ErrResourceInGroup = fmt.Errorf("resource is already part of a group")
+
+	// UsePrivateNetworking is true if autoscale can reach droplets over their private
+	// network, so load balancer health checks use their private addresses.
+	UsePrivateNetworking = false
+
+	// lbRegistrationTimeout is how long new droplets are health checked before they are
+	// given up on and not added to the group's load balancer.
+	lbRegistrationTimeout = 10 * time.Minute
+
+	// lbRegistrationDelay is the delay between the rounds of health checks a refresh runs
+	// while it waits for a batch's droplets to be added to the group's load balancer.
+	lbRegistrationDelay = 10 * time.Second
+
+	// lbDrainDelay is how long droplets are left to drain after they are removed from the
+	// group's load balancer and before they are deleted.
+	lbDrainDelay = 30 * time.Second
 )
 
 func defaultTagName(groupName string) string {
@@ -1,5 +1,32 @@
 import Ember from 'ember';
 
+function parseVars(value) {
+  var vars = {};
+  (value || "").split("\n").forEach((line) => {
+    var i = line.indexOf("=");
+    if (i > 0) {
+      vars[line.slice(0, i).trim()] = line.slice(i + 1).trim();
+    }
+  });
+  return vars;
+}
+
+function parseRegions(value) {
+  var regions = [];
+  (value || "").split("\n").forEach((line) => {
+    var parts = line.trim().split(":");
+    if (parts[0]) {
+      regions.push({
+        region: parts[0],
+        weight: parseInt(parts[1] || 0, 10),
+        min: parseInt(parts[2] || 0, 10),
+        max: parseInt(parts[3] || 0, 10)
+      });
+    }
+  });
+  return regions;
+}
+
 export default Ember.Component.extend({
 
   // form items
@@ -8,6 +35,27 @@ export default Ember.Component.extend({
   metricType: "load",
   metric: {},
   policyType: "value",
+
+  // user data variables, one key=value per line
+  vars: "",
+
+  // group user data, merged after the template's user data
+  userData: "",
+
+  // regions, one region[:weight[:min[:max]]] per line
+  regions: "",
+
+  // id of the load balancer new droplets are added to
+  loadBalancerID: "",
+
+  // floating ip kept assigned to a healthy droplet
+  floatingIP: "",
+
+  // domain, record name and mode of the group's dns records
+  dnsDomain: "",
+  dnsName: "",
+  dnsMode: "droplet",
+
   policy: {
     min_size: 1,
     max_size: 10,
@@ -31,6 +79,16 @@ export default Ember.Component.extend({
         name: this.name,
         baseName: this.baseName,
         templateID: this.template.id,
+        vars: parseVars(this.vars),
+        userData: this.userData,
+        regions: parseRegions(this.regions),
+        loadBalancerID: this.loadBalancerID,
+        floatingIP: this.floatingIP,
+        dns: {
+          domain: this.dnsDomain,
+          name: this.dnsDomain ? this.dnsName : "",
+          mode: this.dnsDomain ? this.dnsMode : ""
+        },
         metricType: this.metricType,
         metric: this.metric,
         policyType: this.policyType,
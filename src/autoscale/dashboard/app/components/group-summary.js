@@ -1,4 +1,16 @@
 import Ember from 'ember';
 
 export default Ember.Component.extend({
+  regionRows: Ember.computed('group.regions', 'group.regionCounts', function() {
+    var counts = this.get('group.regionCounts') || {};
+    return (this.get('group.regions') || []).map((r) => {
+      return {
+        region: r.region,
+        weight: r.weight || 1,
+        min: r.min,
+        max: r.max || "-",
+        count: counts[r.region] || 0
+      };
+    });
+  })
 });
@@ -43,11 +43,21 @@ export default Ember.Component.extend({
       var createdAtUTC = new Date(createdAt.getUTCFullYear(), createdAt.getUTCMonth(), createdAt.getUTCDate(), createdAt.getUTCHours(), createdAt.getUTCMinutes(), createdAt.getUTCSeconds());
       var dateStr = createdAtUTC.toLocaleString('en-US', { hour12: false });
 
-      var action = "grew";
-      if (notif.delta < 0) {
-        action = "shrank"
+      var msg;
+      if (notif.kind !== "scaled") {
+        msg = `${notif.name}: ${notif.message}`;
+      } else {
+        var action = "grew";
+        if (notif.delta < 0) {
+          action = "shrank"
+        }
+        msg = `${notif.name} ${action} to ${notif.count}`;
+
+        var decision = notif.decision;
+        if (decision && decision.reason === "policy" && decision.comparison) {
+          msg += ` (${decision.metric} ${decision.metricValue} ${decision.comparison} ${decision.threshold})`;
+        }
       }
-      const msg = `${notif.name} ${action} to ${notif.count}`;
 
       list.push({ id: notif.groupID, msg: msg });
     }
@@ -1,5 +1,9 @@
 import Ember from 'ember';
 
+function splitList(value) {
+  return (value || "").split(",").map((item) => item.trim()).filter((item) => item.length > 0);
+}
+
 export default Ember.Component.extend({
   init() {
     this._super(...arguments);
@@ -13,7 +17,31 @@ export default Ember.Component.extend({
   name: "template-1",
   size: "4gb",
   image: "ubuntu-14-04-x64",
+
+  // id of a snapshot to use instead of image
+  snapshot: null,
   userData: null,
+  privateNetworking: false,
+  ipv6: false,
+  monitoring: false,
+  backups: false,
+
+  // comma separated list of sizes to try when the size is unavailable
+  fallbackSizes: "",
+
+  // comma separated list of extra droplet tags
+  tags: "",
+
+  // comma separated list of volume sizes in gigabytes
+  volumes: "",
+
+  // monitoring agent bootstrap
+  agentTypes: ["upstart", "systemd", "custom", "none"],
+  agentType: "upstart",
+  agentVersion: "0.12.0",
+  agentPort: 9100,
+  agentScript: null,
+  isCustomAgent: Ember.computed.equal('agentType', 'custom'),
 
   actions: {
     submit: function() {
@@ -21,9 +49,24 @@ export default Ember.Component.extend({
         name: this.name,
         region: this.region,
         size: this.size,
-        image: this.image,
+        fallbackSizes: splitList(this.fallbackSizes),
+        image: this.snapshot ? String(this.snapshot) : this.image,
         sshKeys: this.sshKeys,
-        userData: this.userData
+        userData: this.userData,
+        privateNetworking: this.privateNetworking,
+        ipv6: this.ipv6,
+        monitoring: this.monitoring,
+        backups: this.backups,
+        tags: splitList(this.tags),
+        volumes: splitList(this.volumes).map((size) => {
+          return { sizeGigabytes: parseInt(size, 10) };
+        }),
+        agent: {
+          type: this.agentType,
+          version: this.agentVersion,
+          port: parseInt(this.agentPort, 10),
+          script: this.agentType === "custom" ? this.agentScript : undefined
+        }
       };
 
       const promise = this.get("onCreate")(createRequest);
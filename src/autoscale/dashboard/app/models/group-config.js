@@ -5,5 +5,8 @@ import { hasMany } from 'ember-data/relationships';
 export default Model.extend({
   policies: attr(),
   metrics: attr(),
-  templates: attr()
+  templates: attr(),
+  loadBalancers: attr(),
+  floatingIPs: attr(),
+  domains: attr()
 });
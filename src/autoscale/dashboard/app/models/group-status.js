@@ -5,5 +5,6 @@ export default Fragment.extend({
   groupID: attr(),
   delta: attr(),
   total: attr(),
+  decision: attr(),
   createdAt: attr('date')
 });
@@ -6,6 +6,14 @@ export default Model.extend({
   name: attr(),
   baseName: attr(),
   templateID: attr(),
+  templateVersion: attr('number'),
+  vars: attr(),
+  userData: attr(),
+  regions: attr(),
+  regionCounts: attr(),
+  loadBalancerID: attr(),
+  floatingIP: attr(),
+  dns: attr(),
   metricType: attr(),
   metric: attr(),
   policyType: attr(),
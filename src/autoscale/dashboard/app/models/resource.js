@@ -4,5 +4,10 @@ import Fragment from 'model-fragments/fragment';
 export default Fragment.extend({
   address: attr('string'),
   name: attr('string'),
+  protected: attr('boolean'),
+  templateID: attr('string'),
+  templateVersion: attr('number'),
+  region: attr('string'),
+  size: attr('string'),
   createdAt: attr('date')
 });
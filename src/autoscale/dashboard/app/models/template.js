@@ -5,7 +5,16 @@ export default Model.extend({
   name: attr(),
   region: attr(),
   size: attr(),
+  fallbackSizes: attr(),
   image: attr(),
   sshKeys: attr(),
-  userData: attr()
+  userData: attr(),
+  version: attr('number'),
+  privateNetworking: attr('boolean'),
+  ipv6: attr('boolean'),
+  monitoring: attr('boolean'),
+  backups: attr('boolean'),
+  tags: attr(),
+  volumes: attr(),
+  agent: attr()
 });
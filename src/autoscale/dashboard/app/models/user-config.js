@@ -4,5 +4,6 @@ import attr from 'ember-data/attr';
 export default Model.extend({
   regions: attr(),
   sizes: attr(),
-  keys: attr()
+  keys: attr(),
+  snapshots: attr()
 });
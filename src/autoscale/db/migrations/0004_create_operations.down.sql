@@ -0,0 +1,3 @@
+DROP INDEX operations_state_idx;
+DROP INDEX operations_group_id_idx;
+DROP TABLE operations;
\ No newline at end of file
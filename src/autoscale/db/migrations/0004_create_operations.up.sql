@@ -0,0 +1,14 @@
+CREATE TABLE operations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id UUID references groups(id),
+  state text not null,
+  requested_delta integer,
+  actual_delta integer,
+  droplet_ids jsonb,
+  errors jsonb,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone
+);
+
+CREATE INDEX operations_group_id_idx on operations(group_id,created_at);
+CREATE INDEX operations_state_idx on operations(state);
\ No newline at end of file
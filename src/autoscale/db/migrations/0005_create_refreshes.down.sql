@@ -0,0 +1,3 @@
+DROP INDEX refreshes_state_idx;
+DROP INDEX refreshes_group_id_idx;
+DROP TABLE refreshes;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE refreshes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id UUID references groups(id),
+  state text not null,
+  template_id UUID references templates(id),
+  previous_template_id text,
+  min_healthy_percent integer,
+  batch_size integer,
+  replaced_ids jsonb,
+  created_ids jsonb,
+  errors jsonb,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone
+);
+
+CREATE INDEX refreshes_group_id_idx on refreshes(group_id,created_at);
+CREATE INDEX refreshes_state_idx on refreshes(state);
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE refreshes DROP COLUMN previous_template_version;
+ALTER TABLE refreshes DROP COLUMN template_version;
+ALTER TABLE refreshes ADD CONSTRAINT refreshes_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id);
+
+ALTER TABLE groups DROP COLUMN template_version;
+
+DROP TABLE template_versions;
+
+ALTER TABLE templates DROP COLUMN version;
\ No newline at end of file
@@ -0,0 +1,23 @@
+ALTER TABLE templates ADD COLUMN version integer not null default 1;
+
+CREATE TABLE template_versions (
+  template_id UUID references templates(id) ON DELETE CASCADE,
+  version integer not null,
+  region text,
+  size text,
+  image text,
+  ssh_keys jsonb,
+  user_data text,
+  created_at timestamp with time zone,
+  PRIMARY KEY (template_id, version)
+);
+
+INSERT INTO template_versions
+  (template_id, version, region, size, image, ssh_keys, user_data, created_at)
+  SELECT id, version, region, size, image, ssh_keys, user_data, now() FROM templates;
+
+ALTER TABLE groups ADD COLUMN template_version integer not null default 0;
+
+ALTER TABLE refreshes DROP CONSTRAINT refreshes_template_id_fkey;
+ALTER TABLE refreshes ADD COLUMN template_version integer not null default 0;
+ALTER TABLE refreshes ADD COLUMN previous_template_version integer not null default 0;
\ No newline at end of file
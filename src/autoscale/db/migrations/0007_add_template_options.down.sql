@@ -0,0 +1,13 @@
+ALTER TABLE template_versions DROP COLUMN volumes;
+ALTER TABLE template_versions DROP COLUMN tags;
+ALTER TABLE template_versions DROP COLUMN backups;
+ALTER TABLE template_versions DROP COLUMN monitoring;
+ALTER TABLE template_versions DROP COLUMN ipv6;
+ALTER TABLE template_versions DROP COLUMN private_networking;
+
+ALTER TABLE templates DROP COLUMN volumes;
+ALTER TABLE templates DROP COLUMN tags;
+ALTER TABLE templates DROP COLUMN backups;
+ALTER TABLE templates DROP COLUMN monitoring;
+ALTER TABLE templates DROP COLUMN ipv6;
+ALTER TABLE templates DROP COLUMN private_networking;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE templates ADD COLUMN private_networking boolean not null default false;
+ALTER TABLE templates ADD COLUMN ipv6 boolean not null default false;
+ALTER TABLE templates ADD COLUMN monitoring boolean not null default false;
+ALTER TABLE templates ADD COLUMN backups boolean not null default false;
+ALTER TABLE templates ADD COLUMN tags jsonb not null default '[]';
+ALTER TABLE templates ADD COLUMN volumes jsonb not null default '[]';
+
+ALTER TABLE template_versions ADD COLUMN private_networking boolean not null default false;
+ALTER TABLE template_versions ADD COLUMN ipv6 boolean not null default false;
+ALTER TABLE template_versions ADD COLUMN monitoring boolean not null default false;
+ALTER TABLE template_versions ADD COLUMN backups boolean not null default false;
+ALTER TABLE template_versions ADD COLUMN tags jsonb not null default '[]';
+ALTER TABLE template_versions ADD COLUMN volumes jsonb not null default '[]';
\ No newline at end of file
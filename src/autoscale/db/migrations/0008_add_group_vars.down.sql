@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN vars;
\ No newline at end of file
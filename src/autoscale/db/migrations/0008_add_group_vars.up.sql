@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN vars jsonb not null default '{}';
\ No newline at end of file
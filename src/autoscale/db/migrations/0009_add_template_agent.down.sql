@@ -0,0 +1,2 @@
+ALTER TABLE template_versions DROP COLUMN agent;
+ALTER TABLE templates DROP COLUMN agent;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE templates ADD COLUMN agent jsonb not null default '{}';
+ALTER TABLE template_versions ADD COLUMN agent jsonb not null default '{}';
\ No newline at end of file
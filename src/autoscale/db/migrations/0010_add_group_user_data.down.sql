@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN user_data;
\ No newline at end of file
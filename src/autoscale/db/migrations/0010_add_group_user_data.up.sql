@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN user_data text not null default '';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN regions;
\ No newline at end of file
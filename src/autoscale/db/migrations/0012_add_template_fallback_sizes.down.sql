@@ -0,0 +1,2 @@
+ALTER TABLE template_versions DROP COLUMN fallback_sizes;
+ALTER TABLE templates DROP COLUMN fallback_sizes;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE templates ADD COLUMN fallback_sizes jsonb not null default '[]';
+ALTER TABLE template_versions ADD COLUMN fallback_sizes jsonb not null default '[]';
\ No newline at end of file
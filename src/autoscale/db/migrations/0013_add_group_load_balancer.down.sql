@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN load_balancer_id;
\ No newline at end of file
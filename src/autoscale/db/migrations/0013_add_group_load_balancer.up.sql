@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN load_balancer_id text not null default '';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN floating_ip;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN floating_ip text not null default '';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE groups DROP COLUMN dns;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE dead_letters;
+ALTER TABLE groups DROP COLUMN notification_sinks;
\ No newline at end of file
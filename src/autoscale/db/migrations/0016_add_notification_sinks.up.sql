@@ -0,0 +1,14 @@
+ALTER TABLE groups ADD COLUMN notification_sinks jsonb not null default '[]';
+
+CREATE TABLE dead_letters (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  sink text not null,
+  group_id text,
+  notification_id text,
+  payload jsonb,
+  error text,
+  attempts integer,
+  created_at timestamp with time zone
+);
+
+CREATE INDEX dead_letters_created_at_idx on dead_letters(created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE group_status DROP COLUMN decision;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE group_status ADD COLUMN decision jsonb;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX events_created_at_idx;
+DROP INDEX events_group_id_idx;
+DROP TABLE events;
\ No newline at end of file
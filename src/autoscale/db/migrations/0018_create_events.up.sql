@@ -0,0 +1,17 @@
+CREATE TABLE events (
+  seq bigserial PRIMARY KEY,
+  id uuid UNIQUE NOT NULL,
+  group_id uuid,
+  name text,
+  event text,
+  action text,
+  delta integer,
+  count integer,
+  message text,
+  is_error boolean,
+  decision jsonb,
+  created_at timestamp with time zone
+);
+
+CREATE INDEX events_group_id_idx on events(group_id,seq);
+CREATE INDEX events_created_at_idx on events(created_at);
\ No newline at end of file
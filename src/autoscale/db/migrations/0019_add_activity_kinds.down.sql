@@ -0,0 +1,3 @@
+ALTER TABLE events RENAME COLUMN kind TO action;
+ALTER TABLE group_status DROP COLUMN error;
+ALTER TABLE group_status DROP COLUMN kind;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE group_status ADD COLUMN kind text not null default 'scaled';
+ALTER TABLE group_status ADD COLUMN error text not null default '';
+ALTER TABLE events RENAME COLUMN action TO kind;
\ No newline at end of file
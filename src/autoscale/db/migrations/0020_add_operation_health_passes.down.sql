@@ -0,0 +1 @@
+ALTER TABLE operations DROP COLUMN health_passes;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE operations ADD COLUMN health_passes jsonb not null default '{}';
\ No newline at end of file
@@ -0,0 +1,106 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// DecisionPolicy is the reason of decisions made by a group's policy.
+	DecisionPolicy = "policy"
+
+	// DecisionResize is the reason of decisions requested by a resize.
+	DecisionResize = "resize"
+
+	// DecisionAttach is the reason of decisions to attach a resource.
+	DecisionAttach = "attach"
+
+	// DecisionDetach is the reason of decisions to detach a resource.
+	DecisionDetach = "detach"
+
+	// DecisionDisable is the reason of decisions to remove all of a disabled group's
+	// resources.
+	DecisionDisable = "disable"
+)
+
+// Decision records why an action changed, or didn't change, the size of a group.
+// Policy decisions have the metric value and the threshold it crossed. Comparison is
+// ">=" or "<=", and is empty if the value was between the policy's thresholds.
+type Decision struct {
+	Reason        string        `json:"reason"`
+	PolicyType    string        `json:"policyType,omitempty"`
+	Metric        string        `json:"metric,omitempty"`
+	MetricValue   float64       `json:"metricValue,omitempty"`
+	Threshold     float64       `json:"threshold,omitempty"`
+	Comparison    string        `json:"comparison,omitempty"`
+	RequestedSize int           `json:"requestedSize,omitempty"`
+	ResourceID    string        `json:"resourceID,omitempty"`
+	Created       []int         `json:"created,omitempty"`
+	Deleted       []int         `json:"deleted,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// String describes the decision, e.g. "load 1.2 ≥ 0.8".
+func (d *Decision) String() string {
+	switch d.Reason {
+	case DecisionPolicy:
+		comparison := map[string]string{">=": "≥", "<=": "≤"}[d.Comparison]
+		if comparison == "" {
+			return fmt.Sprintf("%s %s", d.Metric, formatDecisionValue(d.MetricValue))
+		}
+
+		return fmt.Sprintf("%s %s %s %s", d.Metric, formatDecisionValue(d.MetricValue), comparison,
+			formatDecisionValue(d.Threshold))
+	case DecisionResize:
+		return fmt.Sprintf("resized to %d", d.RequestedSize)
+	case DecisionAttach:
+		return fmt.Sprintf("attached %s", d.ResourceID)
+	case DecisionDetach:
+		return fmt.Sprintf("detached %s", d.ResourceID)
+	case DecisionDisable:
+		return "group disabled"
+	default:
+		return d.Reason
+	}
+}
+
+// recordOperation adds the droplets an operation created or deleted to the decision,
+// and how long the action has taken since started. op is nil if the operation failed.
+func (d *Decision) recordOperation(op *Operation, started time.Time) {
+	d.Duration = time.Since(started)
+
+	if op == nil {
+		return
+	}
+
+	ids := append([]int{}, op.DropletIDs...)
+	if op.ActualDelta > 0 {
+		d.Created = append(d.Created, ids...)
+	} else if op.ActualDelta < 0 {
+		d.Deleted = append(d.Deleted, ids...)
+	}
+}
+
+// Value converts a decision to JSON to be stored in the database.
+func (d Decision) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan converts a DB value back into a Decision.
+func (d *Decision) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, d)
+}
+
+func formatDecisionValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,47 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecision_String(t *testing.T) {
+	cases := []struct {
+		d        Decision
+		expected string
+	}{
+		{d: Decision{Reason: DecisionPolicy, Metric: "load", MetricValue: 1.2, Threshold: 0.8, Comparison: ">="}, expected: "load 1.2 ≥ 0.8"},
+		{d: Decision{Reason: DecisionPolicy, Metric: "load", MetricValue: 0.123456, Threshold: 0.2, Comparison: "<="}, expected: "load 0.12 ≤ 0.2"},
+		{d: Decision{Reason: DecisionPolicy, Metric: "load", MetricValue: 0.5}, expected: "load 0.5"},
+		{d: Decision{Reason: DecisionResize, RequestedSize: 5}, expected: "resized to 5"},
+		{d: Decision{Reason: DecisionAttach, ResourceID: "12345"}, expected: "attached 12345"},
+		{d: Decision{Reason: DecisionDisable}, expected: "group disabled"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, c.d.String())
+	}
+}
+
+func TestDecision_RecordOperation(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+
+	d := &Decision{Reason: DecisionPolicy}
+	d.recordOperation(&Operation{ActualDelta: 2, DropletIDs: DropletIDs{1, 2}}, started)
+	assert.Equal(t, []int{1, 2}, d.Created)
+	assert.Empty(t, d.Deleted)
+	assert.True(t, d.Duration >= time.Minute)
+
+	d = &Decision{Reason: DecisionDisable}
+	d.recordOperation(&Operation{ActualDelta: -1, DropletIDs: DropletIDs{3}}, started)
+	assert.Empty(t, d.Created)
+	assert.Equal(t, []int{3}, d.Deleted)
+
+	d = &Decision{Reason: DecisionResize}
+	d.recordOperation(nil, started)
+	assert.Empty(t, d.Created)
+	assert.Empty(t, d.Deleted)
+	assert.True(t, d.Duration >= time.Minute)
+}
@@ -0,0 +1,277 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"pkg/do"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/digitalocean/godo"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DNSModeDroplet maintains one A record per droplet, named after the droplet.
+	DNSModeDroplet = "droplet"
+
+	// DNSModeRoundRobin maintains a set of A records under one name, one per droplet.
+	DNSModeRoundRobin = "round-robin"
+
+	// dnsMarkerPrefix starts the data of the TXT records marking A records as a group's.
+	dnsMarkerPrefix = "autoscale-owner="
+)
+
+// GroupDNS configures the A records a group maintains in a DigitalOcean domain. In
+// droplet mode, each droplet gets a record named after it, under Name if it is set. In
+// round-robin mode, every droplet gets a record named Name. A group without a domain
+// maintains no records.
+type GroupDNS struct {
+	Domain string `json:"domain"`
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`
+}
+
+// IsEnabled returns true if the group maintains DNS records.
+func (d GroupDNS) IsEnabled() bool {
+	return d.Domain != ""
+}
+
+// IsValid returns if the DNS configuration is valid or not.
+func (d GroupDNS) IsValid() bool {
+	if !d.IsEnabled() {
+		return d.Mode == "" && d.Name == ""
+	}
+
+	switch d.Mode {
+	case DNSModeDroplet:
+		return true
+	case DNSModeRoundRobin:
+		return d.Name != ""
+	default:
+		return false
+	}
+}
+
+// Value converts the DNS configuration to JSON to be stored in the database.
+func (d GroupDNS) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan converts a DB value back into GroupDNS.
+func (d *GroupDNS) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, d)
+}
+
+// recordName returns the name of the record for the droplet named dropletName.
+func (d GroupDNS) recordName(dropletName string) string {
+	if d.Mode == DNSModeRoundRobin {
+		return d.Name
+	}
+
+	name := strings.ToLower(dropletName)
+	if d.Name != "" && d.Name != "@" {
+		name = fmt.Sprintf("%s.%s", name, d.Name)
+	}
+
+	return name
+}
+
+// ReconcileDNS makes sure the group's DNS records point at its healthy droplets.
+func (r *DropletResource) ReconcileDNS(ctx context.Context) error {
+	if !r.dns.IsEnabled() {
+		return nil
+	}
+
+	return r.reconcileDNS(nil)
+}
+
+// releaseDNS removes the DNS records of droplets which are about to be deleted or leave
+// the group.
+func (r *DropletResource) releaseDNS(ids []int) error {
+	if !r.dns.IsEnabled() || len(ids) == 0 {
+		return nil
+	}
+
+	exclude := map[int]bool{}
+	for _, id := range ids {
+		exclude[id] = true
+	}
+
+	return r.reconcileDNS(exclude)
+}
+
+// reconcileDNS creates records for the group's healthy droplets and deletes records which
+// point at droplets which are unhealthy, excluded or no longer exist. Droplets are
+// healthy if they are active and have a public address. Droplets of a group with a load
+// balancer must also be behind it.
+//
+// Each A record the group creates gets a TXT record with the same name marking it as the
+// group's, and only marked records are ever deleted. Records the group didn't create are
+// left alone, even if they point at one of its droplets.
+func (r *DropletResource) reconcileDNS(exclude map[int]bool) error {
+	droplets, err := r.doClient.DropletsService.ListByTag(r.tag)
+	if err != nil {
+		return err
+	}
+
+	var lb *do.LoadBalancer
+	if r.loadBalancerID != "" {
+		if lb, err = r.doClient.LoadBalancersService.Get(r.loadBalancerID); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.doClient.DomainsService.Records(r.dns.Domain)
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]bool{}
+	for _, d := range droplets {
+		if exclude[d.ID] || d.Status != "active" {
+			continue
+		}
+
+		if lb != nil && !isLoadBalanced(lb, d) {
+			continue
+		}
+
+		address, err := d.PublicIPv4()
+		if err != nil || address == "" {
+			continue
+		}
+
+		desired[dnsRecordKey(r.dns.recordName(d.Name), address)] = true
+	}
+
+	// markers are the group's ownership records, by the key of the A record they mark.
+	markers := map[string][]do.DomainRecord{}
+	for _, record := range records {
+		if address, ok := r.markedAddress(record); ok {
+			key := dnsRecordKey(record.Name, address)
+			markers[key] = append(markers[key], record)
+		}
+	}
+
+	log := r.log.WithField("domain", r.dns.Domain)
+
+	existing := map[string]bool{}
+	for _, record := range records {
+		if record.Type != "A" {
+			continue
+		}
+
+		key := dnsRecordKey(record.Name, record.Data)
+		if len(markers[key]) == 0 {
+			// records the group doesn't own still count, so they aren't duplicated.
+			if desired[key] {
+				existing[key] = true
+			}
+			continue
+		}
+
+		if desired[key] && !existing[key] {
+			existing[key] = true
+			continue
+		}
+
+		if err := r.deleteDNSRecord(log, record); err != nil {
+			return err
+		}
+	}
+
+	for key, marks := range markers {
+		keep := existing[key]
+		for _, record := range marks {
+			if keep {
+				keep = false
+				continue
+			}
+
+			if err := r.deleteDNSRecord(log, record); err != nil {
+				return err
+			}
+		}
+	}
+
+	missing := []string{}
+	for key := range desired {
+		if !existing[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+
+	for _, key := range missing {
+		parts := strings.SplitN(key, " ", 2)
+		log.WithFields(logrus.Fields{
+			"name":    parts[0],
+			"address": parts[1],
+		}).Info("creating dns record")
+
+		drer := &godo.DomainRecordEditRequest{
+			Type: "A",
+			Name: parts[0],
+			Data: parts[1],
+		}
+
+		if _, err := r.doClient.DomainsService.CreateRecord(r.dns.Domain, drer); err != nil {
+			return err
+		}
+
+		drer = &godo.DomainRecordEditRequest{
+			Type: "TXT",
+			Name: parts[0],
+			Data: r.dnsMarker(parts[1]),
+		}
+
+		if _, err := r.doClient.DomainsService.CreateRecord(r.dns.Domain, drer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DropletResource) deleteDNSRecord(log *logrus.Entry, record do.DomainRecord) error {
+	log.WithFields(logrus.Fields{
+		"record-id": record.ID,
+		"type":      record.Type,
+		"name":      record.Name,
+		"data":      record.Data,
+	}).Info("deleting dns record")
+
+	return r.doClient.DomainsService.DeleteRecord(r.dns.Domain, record.ID)
+}
+
+// dnsMarker returns the data of the TXT record marking the group's A record for address.
+func (r *DropletResource) dnsMarker(address string) string {
+	return fmt.Sprintf("%s%s,address=%s", dnsMarkerPrefix, r.tag, address)
+}
+
+// markedAddress returns the address of the A record a TXT record marks as the group's.
+func (r *DropletResource) markedAddress(record do.DomainRecord) (string, bool) {
+	if record.Type != "TXT" {
+		return "", false
+	}
+
+	prefix := dnsMarkerPrefix + r.tag + ",address="
+	if !strings.HasPrefix(record.Data, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(record.Data, prefix), true
+}
+
+func dnsRecordKey(name, address string) string {
+	return name + " " + address
+}
@@ -0,0 +1,190 @@
+package autoscale
+
+import (
+	"pkg/do"
+	"pkg/do/mocks"
+	"pkg/doclient"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func testDNSRecord(id int, recordType, name, data string) do.DomainRecord {
+	return do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: id, Type: recordType, Name: name, Data: data}}
+}
+
+func testDNSMarker(id int, name, tag, address string) do.DomainRecord {
+	return testDNSRecord(id, "TXT", name, dnsMarkerPrefix+tag+",address="+address)
+}
+
+func testDNSResource(ds *mocks.DropletsService, doms *mocks.DomainsService, dns GroupDNS) *DropletResource {
+	r := testDropletResource(&doclient.Client{
+		DropletsService: ds,
+		DomainsService:  doms,
+	})
+	r.dns = dns
+
+	return r
+}
+
+func TestGroupDNS_IsValid(t *testing.T) {
+	cases := []struct {
+		dns   GroupDNS
+		valid bool
+	}{
+		{dns: GroupDNS{}, valid: true},
+		{dns: GroupDNS{Domain: "example.com", Mode: DNSModeDroplet}, valid: true},
+		{dns: GroupDNS{Domain: "example.com", Name: "nodes", Mode: DNSModeDroplet}, valid: true},
+		{dns: GroupDNS{Domain: "example.com", Name: "www", Mode: DNSModeRoundRobin}, valid: true},
+		{dns: GroupDNS{Domain: "example.com", Mode: DNSModeRoundRobin}, valid: false},
+		{dns: GroupDNS{Domain: "example.com", Mode: "weighted"}, valid: false},
+		{dns: GroupDNS{Domain: "example.com"}, valid: false},
+		{dns: GroupDNS{Name: "www", Mode: DNSModeRoundRobin}, valid: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.valid, c.dns.IsValid(), "%#v", c.dns)
+	}
+}
+
+func TestDropletResource_ReconcileDNSRoundRobin(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		testDroplet(1, "active", "10.0.0.1"),
+		testDroplet(2, "active", "10.0.0.2"),
+		testDroplet(3, "new", "10.0.0.3"),
+	}, nil)
+
+	doms := &mocks.DomainsService{}
+	doms.On("Records", "example.com").Return(do.DomainRecords{
+		testDNSRecord(10, "A", "www", "10.0.0.1"),
+		testDNSMarker(20, "www", "as-group", "10.0.0.1"),
+		testDNSRecord(11, "A", "www", "10.0.0.9"),
+		testDNSMarker(21, "www", "as-group", "10.0.0.9"),
+		testDNSRecord(12, "A", "www", "10.0.0.1"),
+		testDNSRecord(13, "CNAME", "www", "example.com."),
+		testDNSRecord(14, "A", "api", "10.0.0.5"),
+		testDNSRecord(15, "A", "@", "10.0.0.5"),
+		testDNSRecord(16, "A", "www", "10.0.0.6"),
+		testDNSMarker(22, "www", "as-group", "10.0.0.8"),
+	}, nil)
+	doms.On("DeleteRecord", "example.com", 11).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 21).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 12).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 22).Return(nil).Once()
+	doms.On("CreateRecord", "example.com", &godo.DomainRecordEditRequest{Type: "A", Name: "www", Data: "10.0.0.2"}).
+		Return(&do.DomainRecord{}, nil).Once()
+	doms.On("CreateRecord", "example.com", &godo.DomainRecordEditRequest{Type: "TXT", Name: "www", Data: "autoscale-owner=as-group,address=10.0.0.2"}).
+		Return(&do.DomainRecord{}, nil).Once()
+
+	r := testDNSResource(ds, doms, GroupDNS{Domain: "example.com", Name: "www", Mode: DNSModeRoundRobin})
+
+	require.NoError(t, r.ReconcileDNS(ctx))
+	assert.True(t, doms.AssertExpectations(t))
+	doms.AssertNumberOfCalls(t, "DeleteRecord", 4)
+}
+
+func TestDropletResource_ReconcileDNSKeepsUnownedRecords(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		testDroplet(1, "off", "10.0.0.1"),
+		testDroplet(2, "active", "10.0.0.2"),
+	}, nil)
+
+	doms := &mocks.DomainsService{}
+	doms.On("Records", "example.com").Return(do.DomainRecords{
+		testDNSRecord(10, "A", "@", "10.0.0.1"),
+		testDNSRecord(11, "A", "@", "10.0.0.2"),
+	}, nil)
+
+	r := testDNSResource(ds, doms, GroupDNS{Domain: "example.com", Name: "@", Mode: DNSModeRoundRobin})
+
+	require.NoError(t, r.ReconcileDNS(ctx))
+	doms.AssertNotCalled(t, "DeleteRecord", mock.Anything, mock.Anything)
+	doms.AssertNotCalled(t, "CreateRecord", mock.Anything, mock.Anything)
+}
+
+func TestDropletResource_ReconcileDNSDroplet(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		testDroplet(1, "active", "10.0.0.1"),
+		testDroplet(2, "active", "10.0.0.2"),
+	}, nil)
+
+	doms := &mocks.DomainsService{}
+	doms.On("Records", "example.com").Return(do.DomainRecords{
+		testDNSRecord(10, "A", "as-1.nodes", "10.0.0.1"),
+		testDNSMarker(20, "as-1.nodes", "as-group", "10.0.0.1"),
+		testDNSRecord(11, "A", "as-2.nodes", "10.0.0.8"),
+		testDNSMarker(21, "as-2.nodes", "as-group", "10.0.0.8"),
+		testDNSRecord(12, "A", "as-gone1.nodes", "10.0.0.7"),
+		testDNSMarker(22, "as-gone1.nodes", "as-group", "10.0.0.7"),
+		testDNSRecord(13, "A", "as-xxxxx.nodes", "10.0.0.3"),
+		testDNSMarker(23, "as-xxxxx.nodes", "as-other", "10.0.0.3"),
+		testDNSRecord(14, "A", "db.nodes", "10.0.0.4"),
+		testDNSRecord(15, "A", "as-1", "10.0.0.1"),
+	}, nil)
+	doms.On("DeleteRecord", "example.com", 11).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 21).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 12).Return(nil).Once()
+	doms.On("DeleteRecord", "example.com", 22).Return(nil).Once()
+	doms.On("CreateRecord", "example.com", &godo.DomainRecordEditRequest{Type: "A", Name: "as-2.nodes", Data: "10.0.0.2"}).
+		Return(&do.DomainRecord{}, nil).Once()
+	doms.On("CreateRecord", "example.com", &godo.DomainRecordEditRequest{Type: "TXT", Name: "as-2.nodes", Data: "autoscale-owner=as-group,address=10.0.0.2"}).
+		Return(&do.DomainRecord{}, nil).Once()
+
+	r := testDNSResource(ds, doms, GroupDNS{Domain: "example.com", Name: "nodes", Mode: DNSModeDroplet})
+
+	require.NoError(t, r.ReconcileDNS(ctx))
+	assert.True(t, doms.AssertExpectations(t))
+	doms.AssertNumberOfCalls(t, "DeleteRecord", 4)
+}
+
+func TestDropletResource_ScaleDownRemovesDNSRecords(t *testing.T) {
+	ctx := context.Background()
+
+	calls := []string{}
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		testDroplet(1, "active", "10.0.0.1", ProtectedTag),
+		testDroplet(2, "active", "10.0.0.2"),
+	}, nil)
+	ds.On("Delete", 2).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "delete-droplet")
+	})
+
+	doms := &mocks.DomainsService{}
+	doms.On("Records", "example.com").Return(do.DomainRecords{
+		testDNSRecord(10, "A", "www", "10.0.0.1"),
+		testDNSMarker(20, "www", "as-group", "10.0.0.1"),
+		testDNSRecord(11, "A", "www", "10.0.0.2"),
+		testDNSMarker(21, "www", "as-group", "10.0.0.2"),
+	}, nil)
+	doms.On("DeleteRecord", "example.com", 11).Return(nil).Once().Run(func(mock.Arguments) {
+		calls = append(calls, "delete-record")
+	})
+	doms.On("DeleteRecord", "example.com", 21).Return(nil).Once()
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := testDNSResource(ds, doms, GroupDNS{Domain: "example.com", Name: "www", Mode: DNSModeRoundRobin})
+
+	op := NewOperation("abc", -1)
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+	require.Equal(t, []string{"delete-record", "delete-droplet"}, calls)
+}
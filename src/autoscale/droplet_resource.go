@@ -2,11 +2,13 @@ package autoscale
 
 import (
 	"fmt"
-	"pkg/cloudinit"
+	"net/http"
+	"pkg/do"
 	"pkg/doclient"
 	"pkg/util/rand"
 	"pkg/util/shuffle"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,20 +25,26 @@ var (
 
 // dropletConfig is a configurtion for building a droplet.
 type dropletConfig struct {
-	doc       *doclient.Client
-	wg        *sync.WaitGroup
-	log       *logrus.Entry
-	groupName string
-	template  *Template
-	tag       string
-	userData  string
+	doc      *doclient.Client
+	log      *logrus.Entry
+	name     string
+	region   string
+	template *Template
+	userData string
 }
 
 // DropletResource watches Droplets.
 type DropletResource struct {
-	doClient *doclient.Client
-	tag      string
-	log      *logrus.Entry
+	doClient       *doclient.Client
+	tag            string
+	log            *logrus.Entry
+	loadBalancerID string
+	floatingIP     string
+	dns            GroupDNS
+
+	// floatingIPChanges are reassignments of the floating IP which haven't been
+	// returned by ReconcileFloatingIP yet.
+	floatingIPChanges []FloatingIPChange
 }
 
 var _ ResourceManager = (*DropletResource)(nil)
@@ -68,70 +76,361 @@ func (r *DropletResource) Count() (int, error) {
 	return len(droplets), nil
 }
 
-// Scale sclaes DropletResources byN.
-func (r *DropletResource) Scale(ctx context.Context, g Group, byN int, repo Repository) (bool, error) {
+// Scale sclaes DropletResources by the operation's requested delta.
+func (r *DropletResource) Scale(ctx context.Context, g Group, op *Operation, repo Repository) (bool, error) {
+	byN := op.RequestedDelta
 	if byN > 0 {
-		return true, r.scaleUp(ctx, g, byN, repo)
+		return true, r.scaleUp(ctx, g, byN, op, repo)
 	} else if byN < 0 {
-		return false, r.scaleDown(ctx, g, 0-byN, repo)
+		return false, r.scaleDown(ctx, g, 0-byN, op, repo)
 	} else {
 		return false, nil
 	}
 }
 
+// Resume resumes an operation which was interrupted. Droplets which were created, but not
+// tagged are deleted since they are not part of the group. Droplets which were being tagged
+// are tagged. Operations adding droplets to a load balancer run another round of health
+// checks. Interrupted scale downs are not continued.
+func (r *DropletResource) Resume(ctx context.Context, g Group, op *Operation, repo Repository) error {
+	log := r.log.WithFields(logrus.Fields{
+		"operation-id": op.ID,
+		"state":        op.State,
+	})
+
+	switch op.State {
+	case OperationCreating:
+		log.Info("rolling back droplets which were not tagged")
+		for _, id := range op.DropletIDs {
+			if err := r.doClient.DropletsService.Delete(id); err != nil {
+				log.WithError(err).WithField("droplet-id", id).Error("could not delete droplet")
+				op.AddError(err)
+			}
+		}
+		op.ActualDelta = 0
+
+	case OperationTagging:
+		log.Info("tagging droplets")
+		tmpl, err := repo.GetTemplateVersion(ctx, g.TemplateID, g.TemplateVersion)
+		if err != nil {
+			return err
+		}
+
+		tag := templateTag(tmpl.ID, tmpl.Version)
+		if err := verifyTag(tag, r.doClient, r.log); err != nil {
+			return err
+		}
+
+		op.ActualDelta = r.tagDroplets(tag, op)
+
+		if err := r.startRegistering(ctx, op, repo); err != nil {
+			return err
+		}
+
+		r.publishDNS(op)
+
+	case OperationRegistering:
+		log.Info("checking droplets before adding them to load balancer")
+		op.ActualDelta = len(op.DropletIDs)
+		done, err := r.registerDroplets(ctx, op)
+		if err != nil {
+			log.WithError(err).Error("could not add droplets to load balancer")
+			op.AddError(err)
+		}
+
+		r.publishDNS(op)
+
+		if done {
+			return op.Transition(ctx, repo, OperationDone)
+		}
+
+	case OperationDeleting:
+		log.Info("not continuing scale down")
+		op.ActualDelta = 0 - len(op.DropletIDs)
+	}
+
+	return repo.SaveOperation(ctx, *op)
+}
+
 // ScaleUp scales Droplet resources up.
-func (r *DropletResource) scaleUp(ctx context.Context, g Group, byN int, repo Repository) error {
+func (r *DropletResource) scaleUp(ctx context.Context, g Group, byN int, op *Operation, repo Repository) error {
 	r.log.WithField("by-n", byN).Info("scaling up")
 
-	var wg sync.WaitGroup
-	wg.Add(byN)
-
-	tmpl, err := repo.GetTemplate(ctx, g.TemplateID)
+	tmpl, err := repo.GetTemplateVersion(ctx, g.TemplateID, g.TemplateVersion)
 	if err != nil {
 		return err
 	}
 
-	dc := dropletConfig{
-		doc:       r.doClient,
-		wg:        &wg,
-		log:       r.log,
-		groupName: g.BaseName,
-		template:  tmpl,
-		tag:       r.tag,
+	tag := templateTag(tmpl.ID, tmpl.Version)
+	if err := verifyTag(tag, r.doClient, r.log); err != nil {
+		return err
+	}
+
+	agent := tmpl.Agent.WithDefaults()
+
+	var placer *regionPlacer
+	if len(g.Regions) > 0 {
+		counts, err := r.regionCounts()
+		if err != nil {
+			return err
+		}
+
+		placer = newRegionPlacer(g.Regions, counts)
 	}
 
+	buildConfigUserData := func(name string, i int, region string) (string, error) {
+		vars := UserDataVars{
+			GroupID:         g.ID,
+			GroupName:       g.Name,
+			DropletName:     name,
+			Index:           i,
+			Region:          region,
+			TemplateID:      tmpl.ID,
+			TemplateVersion: tmpl.Version,
+			Vars:            g.Vars,
+		}
+
+		templateUserData, err := tmpl.RenderUserData(vars)
+		if err != nil {
+			return "", fmt.Errorf("unable to render user data: %v", err)
+		}
+
+		groupUserData, err := g.RenderUserData(vars)
+		if err != nil {
+			return "", fmt.Errorf("unable to render group user data: %v", err)
+		}
+
+		userData, err := buildUserData(agent, templateUserData, groupUserData)
+		if err != nil {
+			return "", fmt.Errorf("unable to build user data: %v", err)
+		}
+
+		return userData, nil
+	}
+
+	// droplets which don't fit in the group's regions aren't created. The shortfall is
+	// recorded as an error on the operation rather than failing the scale up.
+	configs := make([]dropletConfig, 0, byN)
 	for i := 0; i < byN; i++ {
-		go bootDroplet(&dc)
+		name := fmt.Sprintf("%s-%s", g.BaseName, rand.String(5))
+
+		region := tmpl.Region
+		if placer != nil {
+			if region, err = placer.next(); err != nil {
+				r.log.WithError(err).WithField("unplaced", byN-i).Warn("unable to place droplets in a region")
+				op.AddError(fmt.Errorf("%d of %d droplets were not created: %v", byN-i, byN, err))
+				break
+			}
+		}
+
+		userData, err := buildConfigUserData(name, i, region)
+		if err != nil {
+			r.log.WithError(err).Error("unable to build user data")
+			return err
+		}
+
+		configs = append(configs, dropletConfig{
+			doc:      r.doClient,
+			log:      r.log,
+			name:     name,
+			region:   region,
+			template: tmpl,
+			userData: userData,
+		})
+	}
+
+	if len(configs) == 0 {
+		op.ActualDelta = 0
+		return repo.SaveOperation(ctx, *op)
+	}
+
+	if err := op.Transition(ctx, repo, OperationCreating); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	wg.Add(len(configs))
+
+	for i := range configs {
+		go func(i int, dc *dropletConfig) {
+			defer wg.Done()
+
+			droplet, err := bootDroplet(dc)
+			for err != nil && placer != nil && isCapacityError(err) {
+				region, perr := placer.fallback(dc.region)
+				if perr != nil {
+					break
+				}
+
+				r.log.WithError(err).WithFields(logrus.Fields{
+					"droplet-name": dc.name,
+					"region":       dc.region,
+					"fallback":     region,
+				}).Warn("region has no capacity, falling back to another region")
+
+				userData, uerr := buildConfigUserData(dc.name, i, region)
+				if uerr != nil {
+					err = uerr
+					break
+				}
+
+				dc.region = region
+				dc.userData = userData
+				droplet, err = bootDroplet(dc)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				op.AddError(err)
+				return
+			}
+
+			op.AddDropletID(droplet.ID)
+			if err := repo.SaveOperation(ctx, *op); err != nil {
+				r.log.WithError(err).WithField("droplet-id", droplet.ID).Error("could not record droplet in operation")
+			}
+		}(i, &configs[i])
 	}
 
 	r.log.Info("waiting for droplets to be created")
 	wg.Wait()
 	r.log.Info("droplets have been created")
 
-	return nil
+	if err := op.Transition(ctx, repo, OperationTagging); err != nil {
+		return err
+	}
+
+	op.ActualDelta = r.tagDroplets(tag, op)
+
+	if err := r.startRegistering(ctx, op, repo); err != nil {
+		return err
+	}
+
+	r.publishDNS(op)
+
+	return repo.SaveOperation(ctx, *op)
+}
+
+// startRegistering moves an operation which created droplets for a group with a load
+// balancer to OperationRegistering. The droplets are health checked and added to the
+// load balancer each time the group is checked, rather than while it is being scaled.
+func (r *DropletResource) startRegistering(ctx context.Context, op *Operation, repo Repository) error {
+	if r.loadBalancerID == "" || len(op.DropletIDs) == 0 {
+		return nil
+	}
+
+	return op.Transition(ctx, repo, OperationRegistering)
+}
+
+// publishDNS creates DNS records for the droplets created by an operation once they are
+// healthy. Failures are recorded as errors since records are reconciled again when the
+// group is next checked.
+func (r *DropletResource) publishDNS(op *Operation) {
+	if !r.dns.IsEnabled() || len(op.DropletIDs) == 0 {
+		return
+	}
+
+	if err := r.reconcileDNS(nil); err != nil {
+		r.log.WithError(err).Error("could not create dns records")
+		op.AddError(err)
+	}
 }
 
 // ScaleDown scales Droplet resources down.
-func (r *DropletResource) scaleDown(ctx context.Context, g Group, byN int, repo Repository) error {
+func (r *DropletResource) scaleDown(ctx context.Context, g Group, byN int, op *Operation, repo Repository) error {
 	r.log.WithField("by-n", byN).Info("scaling down")
 	droplets, err := r.doClient.DropletsService.ListByTag(r.tag)
 	if err != nil {
 		return err
 	}
 
+	if err := op.Transition(ctx, repo, OperationDeleting); err != nil {
+		return err
+	}
+
 	ids := []int{}
+	byID := map[int]do.Droplet{}
 	for _, d := range droplets {
+		byID[d.ID] = d
+		if hasTag(d.Tags, ProtectedTag) {
+			r.log.WithField("droplet-id", d.ID).Info("skipping protected droplet")
+			continue
+		}
+
+		if containsInt(op.Keep, d.ID) {
+			continue
+		}
+
 		ids = append(ids, d.ID)
 	}
 
+	if byN > len(ids) {
+		r.log.WithField("unprotected-count", len(ids)).Warn("not enough unprotected droplets to scale down")
+		byN = len(ids)
+	}
+
 	shuffle.Int(ids)
+	if len(g.Regions) > 0 && !op.Teardown {
+		ids = balancedRemovals(g.Regions, droplets, ids, byN)
+		if len(ids) < byN {
+			r.log.WithField("removable-count", len(ids)).Warn("not enough droplets above region minimums to scale down")
+			byN = len(ids)
+		}
+	}
+
+	if err := r.releaseFloatingIP(ids[:byN], "droplet is being scaled down"); err != nil {
+		r.log.WithError(err).Error("could not move floating ip off droplets being deleted")
+		op.AddError(err)
+	}
+
+	if err := r.releaseDNS(ids[:byN]); err != nil {
+		r.log.WithError(err).Error("could not remove dns records of droplets being deleted")
+		op.AddError(err)
+	}
+
+	if r.loadBalancerID != "" && byN > 0 {
+		if err := r.deregisterDroplets(ctx, ids[:byN], true); err != nil {
+			r.log.WithError(err).Error("could not remove droplets from load balancer")
+			op.AddError(err)
+			repo.SaveOperation(ctx, *op)
+			return err
+		}
+	}
+
+	// volumes are deleted once every droplet is, so they have detached by the time most
+	// of them are deleted.
+	volumes := []string{}
 	for i := 0; i < byN; i++ {
 		id := ids[i]
 		r.log.WithField("droplet-id", id).Info("deleting droplet")
-		if err := r.doClient.DropletsService.Delete(id); err != nil {
+		volumeIDs, err := r.deleteDroplet(byID[id])
+		if err != nil {
 			r.log.WithError(err).WithField("droplet-id", id).Error("could not delete droplet")
+			op.AddError(err)
+			op.ActualDelta = 0 - len(op.DropletIDs)
+			if verr := r.deleteVolumes(volumes); verr != nil {
+				op.AddError(verr)
+			}
+			repo.SaveOperation(ctx, *op)
 			return err
 		}
+
+		volumes = append(volumes, volumeIDs...)
+		op.AddDropletID(id)
+		op.ActualDelta = 0 - len(op.DropletIDs)
+		if err := repo.SaveOperation(ctx, *op); err != nil {
+			r.log.WithError(err).WithField("droplet-id", id).Error("could not record droplet in operation")
+		}
+	}
+
+	if err := r.deleteVolumes(volumes); err != nil {
+		op.AddError(err)
+		if err := repo.SaveOperation(ctx, *op); err != nil {
+			r.log.WithError(err).Error("could not record volume errors in operation")
+		}
 	}
 
 	r.log.Info("scale down complete")
@@ -139,6 +438,79 @@ func (r *DropletResource) scaleDown(ctx context.Context, g Group, byN int, repo
 	return nil
 }
 
+// balancedRemovals orders up to byN of the unprotected droplet ids so removing them keeps
+// the group's spread across regions balanced. Droplets in regions at their minimum are
+// not returned.
+func balancedRemovals(regions GroupRegions, droplets do.Droplets, ids []int, byN int) []int {
+	counts := map[string]int{}
+	regionOf := map[int]string{}
+	for _, d := range droplets {
+		region := dropletRegion(d)
+		counts[region]++
+		regionOf[d.ID] = region
+	}
+
+	byRegion := map[string][]int{}
+	for _, id := range ids {
+		region := regionOf[id]
+		byRegion[region] = append(byRegion[region], id)
+	}
+
+	removals := []int{}
+	for len(removals) < byN {
+		candidates := map[string]bool{}
+		for region, regionIDs := range byRegion {
+			if len(regionIDs) > 0 {
+				candidates[region] = true
+			}
+		}
+
+		region, ok := regions.shrink(counts, candidates)
+		if !ok {
+			break
+		}
+
+		removals = append(removals, byRegion[region][0])
+		byRegion[region] = byRegion[region][1:]
+		counts[region]--
+	}
+
+	return removals
+}
+
+// regionCounts returns the number of droplets in the group in each region.
+func (r *DropletResource) regionCounts() (map[string]int, error) {
+	droplets, err := r.doClient.DropletsService.ListByTag(r.tag)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, d := range droplets {
+		counts[dropletRegion(d)]++
+	}
+
+	return counts, nil
+}
+
+// dropletSize returns the size the droplet was created with, which may be one of its
+// template's fallback sizes.
+func dropletSize(d do.Droplet) string {
+	if d.SizeSlug == "" && d.Size != nil {
+		return d.Size.Slug
+	}
+
+	return d.SizeSlug
+}
+
+func dropletRegion(d do.Droplet) string {
+	if d.Region == nil {
+		return ""
+	}
+
+	return d.Region.Slug
+}
+
 // Allocated returns the allocated droplets.
 func (r *DropletResource) Allocated() ([]ResourceAllocation, error) {
 	droplets, err := r.doClient.DropletsService.ListByTag(r.tag)
@@ -158,10 +530,18 @@ func (r *DropletResource) Allocated() ([]ResourceAllocation, error) {
 			return nil, err
 		}
 
+		templateID, templateVersion := templateFromTags(droplet.Tags)
 		allocation := ResourceAllocation{
-			Name:      droplet.Name,
-			Address:   ip,
-			CreatedAt: t.UTC(),
+			ID:              strconv.Itoa(droplet.ID),
+			Name:            droplet.Name,
+			Address:         ip,
+			Protected:       hasTag(droplet.Tags, ProtectedTag),
+			TemplateID:      templateID,
+			TemplateVersion: templateVersion,
+			AgentPort:       agentPortFromTags(droplet.Tags),
+			Region:          dropletRegion(droplet),
+			Size:            dropletSize(droplet),
+			CreatedAt:       t.UTC(),
 		}
 
 		allocations = append(allocations, allocation)
@@ -170,13 +550,307 @@ func (r *DropletResource) Allocated() ([]ResourceAllocation, error) {
 	return allocations, nil
 }
 
-func bootDroplet(dc *dropletConfig) {
-	defer dc.wg.Done()
-	id := rand.String(5)
+// Protect protects or unprotects a droplet from being removed when the group scales in.
+func (r *DropletResource) Protect(ctx context.Context, id string, protected bool) error {
+	dropletID, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrResourceNotInGroup
+	}
+
+	droplet, err := r.doClient.DropletsService.Get(dropletID)
+	if err != nil {
+		return err
+	}
+
+	if !hasTag(droplet.Tags, r.tag) {
+		return ErrResourceNotInGroup
+	}
+
+	log := r.log.WithFields(logrus.Fields{
+		"droplet-id": dropletID,
+		"protected":  protected,
+	})
+
+	resources := []godo.Resource{
+		{ID: id, Type: godo.DropletResourceType},
+	}
+
+	if protected {
+		if err := verifyTag(ProtectedTag, r.doClient, r.log); err != nil {
+			return err
+		}
+
+		log.Info("protecting droplet")
+		return r.doClient.TagsService.TagResources(ProtectedTag, &godo.TagResourcesRequest{Resources: resources})
+	}
+
+	if !hasTag(droplet.Tags, ProtectedTag) {
+		return nil
+	}
+
+	log.Info("removing droplet protection")
+	return r.doClient.TagsService.UntagResources(ProtectedTag, &godo.UntagResourcesRequest{Resources: resources})
+}
+
+// Attach adopts an existing droplet into the group by tagging it with the group tag.
+func (r *DropletResource) Attach(ctx context.Context, id string) error {
+	dropletID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+
+	droplet, err := r.doClient.DropletsService.Get(dropletID)
+	if err != nil {
+		return err
+	}
+
+	if hasTag(droplet.Tags, BaseTag) {
+		return ErrResourceInGroup
+	}
+
+	trr := &godo.TagResourcesRequest{
+		Resources: []godo.Resource{
+			{ID: id, Type: godo.DropletResourceType},
+		},
+	}
+
+	r.log.WithField("droplet-id", dropletID).Info("attaching droplet")
+	return r.tagDroplet(trr)
+}
 
-	name := fmt.Sprintf("%s-%s", dc.groupName, id)
+// Detach removes a droplet from the group without deleting it.
+func (r *DropletResource) Detach(ctx context.Context, id string) error {
+	dropletID, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrResourceNotInGroup
+	}
+
+	droplet, err := r.doClient.DropletsService.Get(dropletID)
+	if err != nil {
+		return err
+	}
+
+	if !hasTag(droplet.Tags, r.tag) {
+		return ErrResourceNotInGroup
+	}
+
+	urr := &godo.UntagResourcesRequest{
+		Resources: []godo.Resource{
+			{ID: id, Type: godo.DropletResourceType},
+		},
+	}
+
+	if err := r.releaseFloatingIP([]int{dropletID}, "droplet is being detached"); err != nil {
+		return err
+	}
+
+	if err := r.releaseDNS([]int{dropletID}); err != nil {
+		return err
+	}
+
+	if r.loadBalancerID != "" {
+		if err := r.deregisterDroplets(ctx, []int{dropletID}, false); err != nil {
+			return err
+		}
+	}
+
+	r.log.WithField("droplet-id", dropletID).Info("detaching droplet")
+	for _, tag := range []string{r.tag, BaseTag, ProtectedTag} {
+		if !hasTag(droplet.Tags, tag) {
+			continue
+		}
+
+		if err := r.doClient.TagsService.UntagResources(tag, urr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Remove deletes the droplets identified by ids. Droplets which aren't part of the
+// group are not deleted.
+func (r *DropletResource) Remove(ctx context.Context, ids []string) error {
+	droplets := []do.Droplet{}
+	dropletIDs := []int{}
+	for _, id := range ids {
+		dropletID, err := strconv.Atoi(id)
+		if err != nil {
+			return ErrResourceNotInGroup
+		}
+
+		droplet, err := r.doClient.DropletsService.Get(dropletID)
+		if err != nil {
+			return err
+		}
+
+		if !hasTag(droplet.Tags, r.tag) {
+			return ErrResourceNotInGroup
+		}
+
+		droplets = append(droplets, *droplet)
+		dropletIDs = append(dropletIDs, dropletID)
+	}
+
+	if err := r.releaseFloatingIP(dropletIDs, "droplet is being removed"); err != nil {
+		return err
+	}
+
+	if err := r.releaseDNS(dropletIDs); err != nil {
+		return err
+	}
+
+	if r.loadBalancerID != "" && len(dropletIDs) > 0 {
+		if err := r.deregisterDroplets(ctx, dropletIDs, true); err != nil {
+			return err
+		}
+	}
+
+	volumes := []string{}
+	for _, droplet := range droplets {
+		r.log.WithField("droplet-id", droplet.ID).Info("deleting droplet")
+		volumeIDs, err := r.deleteDroplet(droplet)
+		if err != nil {
+			r.deleteVolumes(volumes)
+			return err
+		}
+
+		volumes = append(volumes, volumeIDs...)
+	}
+
+	return r.deleteVolumes(volumes)
+}
+
+// Healthy returns true if all the droplets identified by ids are active. Droplets of a
+// group with a load balancer must also have been added to it.
+func (r *DropletResource) Healthy(ctx context.Context, ids []string) (bool, error) {
+	var lb *do.LoadBalancer
+	if r.loadBalancerID != "" {
+		var err error
+		if lb, err = r.doClient.LoadBalancersService.Get(r.loadBalancerID); err != nil {
+			return false, err
+		}
+	}
+
+	for _, id := range ids {
+		dropletID, err := strconv.Atoi(id)
+		if err != nil {
+			return false, ErrResourceNotInGroup
+		}
+
+		droplet, err := r.doClient.DropletsService.Get(dropletID)
+		if err != nil {
+			return false, err
+		}
+
+		if droplet.Status != "active" {
+			r.log.WithFields(logrus.Fields{
+				"droplet-id": dropletID,
+				"status":     droplet.Status,
+			}).Warn("droplet is not healthy")
+			return false, nil
+		}
+
+		if lb != nil && !isLoadBalanced(lb, *droplet) {
+			r.log.WithFields(logrus.Fields{
+				"droplet-id":       dropletID,
+				"load-balancer-id": lb.ID,
+			}).Warn("droplet is not behind the load balancer")
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// tagDroplets tags the droplets created by an operation with the group tags and
+// templateTag. Droplets which can't be tagged are deleted. It returns the amount of
+// droplets which were tagged.
+func (r *DropletResource) tagDroplets(templateTag string, op *Operation) int {
+	tagged := 0
+
+	for _, id := range op.DropletIDs {
+		log := r.log.WithFields(logrus.Fields{
+			"tag-name":   r.tag,
+			"droplet-id": id,
+		})
+
+		trr := &godo.TagResourcesRequest{
+			Resources: []godo.Resource{
+				{ID: strconv.Itoa(id), Type: godo.DropletResourceType},
+			},
+		}
+
+		log.Info("tagging droplet")
+		if err := r.tagDroplet(trr, templateTag); err != nil {
+			log.WithError(err).Error("deleting droplet because it cannot be tagged")
+			op.AddError(err)
+			r.doClient.DropletsService.Delete(id)
+			continue
+		}
+
+		tagged++
+	}
+
+	return tagged
+}
+
+func (r *DropletResource) tagDroplet(trr *godo.TagResourcesRequest, extraTags ...string) error {
+	tags := append([]string{r.tag, BaseTag}, extraTags...)
+	for _, tag := range tags {
+		if err := r.doClient.TagsService.TagResources(tag, trr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteDroplet deletes a droplet. It returns the volumes autoscale created for the
+// droplet, which can be deleted with deleteVolumes once the droplet is gone.
+func (r *DropletResource) deleteDroplet(droplet do.Droplet) ([]string, error) {
+	if !hasTag(droplet.Tags, VolumesTag) {
+		return nil, r.doClient.DropletsService.Delete(droplet.ID)
+	}
+
+	volumes, err := r.doClient.VolumesService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, v := range volumes {
+		if isDropletVolume(v, droplet) {
+			ids = append(ids, v.ID)
+		}
+	}
+
+	if err := r.doClient.DropletsService.Delete(droplet.ID); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// deleteVolumes deletes the volumes of deleted droplets. Every volume is attempted, and
+// the last error is returned.
+func (r *DropletResource) deleteVolumes(ids []string) error {
+	var lastErr error
+	for _, id := range ids {
+		if err := deleteVolume(r.doClient, id); err != nil {
+			r.log.WithError(err).WithField("volume-id", id).Error("could not delete volume")
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func bootDroplet(dc *dropletConfig) (*do.Droplet, error) {
+	name := dc.name
 	log := dc.log.WithFields(logrus.Fields{
 		"droplet-name": name,
+		"region":       dc.region,
 	})
 
 	keys := []godo.DropletCreateSSHKey{}
@@ -185,66 +859,199 @@ func bootDroplet(dc *dropletConfig) {
 		keys = append(keys, dcs)
 	}
 
-	ci := cloudinit.New()
-	if err := ci.AddPart(cloudinit.MIMETypeShellScript, "ud1.txt", asUserData); err != nil {
-		log.WithError(err).Error("unable to add autoscaling to cloud init")
-		return
+	agent := dc.template.Agent.WithDefaults()
+
+	volumeIDs, err := createVolumes(dc, name)
+	if err != nil {
+		log.WithError(err).Error("unable to create volumes")
+		return nil, err
 	}
 
-	if len(dc.userData) > 0 {
-		if err := ci.AddPart(cloudinit.MIMETypeUnknown, "ud2.txt", dc.userData); err != nil {
-			log.WithError(err).Error("unable to add customer user data to cloud init")
-			return
-		}
+	tags := append([]string{agentPortTag(agent.Port)}, dc.template.Tags...)
+	if len(volumeIDs) > 0 {
+		tags = append(tags, VolumesTag)
 	}
 
-	if err := ci.Close(); err != nil {
-		log.WithError(err).Error("unable to close cloudinit")
-		return
+	dcr := do.DropletCreateRequest{
+		DropletCreateRequest: godo.DropletCreateRequest{
+			Name:              name,
+			Region:            dc.region,
+			Image:             dc.template.dropletCreateImage(),
+			SSHKeys:           keys,
+			Backups:           dc.template.Backups,
+			IPv6:              dc.template.IPv6,
+			PrivateNetworking: dc.template.PrivateNetworking,
+			UserData:          dc.userData,
+		},
+		Monitoring: dc.template.Monitoring,
+		Tags:       tags,
+		Volumes:    volumeIDs,
 	}
 
-	userData := ci.String()
+	var droplet *do.Droplet
+	for _, size := range dc.template.sizes() {
+		dcr.Size = size
+		log.WithField("size", size).Info("creating droplet")
 
-	dcr := godo.DropletCreateRequest{
-		Name:     name,
-		Region:   dc.template.Region,
-		Size:     dc.template.Size,
-		Image:    godo.DropletCreateImage{Slug: dc.template.Image},
-		SSHKeys:  keys,
-		UserData: userData,
-	}
+		droplet, err = dc.doc.DropletsService.CreateWithOptions(&dcr, true)
+		if err == nil || !isCapacityError(err) {
+			break
+		}
 
-	log.Info("creating droplet")
+		log.WithError(err).WithField("size", size).Warn("size has no capacity, trying the next size")
+	}
 
-	droplet, err := dc.doc.DropletsService.Create(&dcr, true)
 	if err != nil {
 		log.WithError(err).Error("unable to create droplet")
-		return
+		for _, id := range volumeIDs {
+			if err := dc.doc.VolumesService.Delete(id); err != nil {
+				log.WithError(err).WithField("volume-id", id).Error("could not delete volume")
+			}
+		}
+		return nil, err
 	}
 
-	log.Info("created droplet")
+	log.WithFields(logrus.Fields{
+		"droplet-id": droplet.ID,
+		"size":       dcr.Size,
+	}).Info("created droplet")
 
-	trr := &godo.TagResourcesRequest{
-		Resources: []godo.Resource{
-			{ID: strconv.Itoa(droplet.ID), Type: godo.DropletResourceType},
-		},
+	return droplet, nil
+}
+
+// createVolumes creates the template's volumes for the droplet named dropletName. Volumes
+// which were created are deleted if a volume can't be created.
+func createVolumes(dc *dropletConfig, dropletName string) ([]string, error) {
+	ids := []string{}
+	for i, v := range dc.template.Volumes {
+		vcr := &do.VolumeCreateRequest{
+			Region:        dc.region,
+			Name:          volumeName(dropletName, i),
+			Description:   fmt.Sprintf("autoscale volume for %s", dropletName),
+			SizeGigabytes: int64(v.SizeGigabytes),
+		}
+
+		volume, err := dc.doc.VolumesService.Create(vcr)
+		if err != nil {
+			for _, id := range ids {
+				dc.doc.VolumesService.Delete(id)
+			}
+			return nil, err
+		}
+
+		ids = append(ids, volume.ID)
 	}
 
-	logWithTag := log.WithFields(logrus.Fields{
-		"tag-name":   dc.tag,
-		"droplet-id": droplet.ID,
-	})
+	return ids, nil
+}
+
+// deleteVolume deletes a volume. A volume can't be deleted while it is attached, so
+// deleting is retried while it is detached from a deleted droplet. Volumes which are
+// already gone count as deleted, and other errors aren't retried.
+func deleteVolume(doc *doclient.Client, id string) error {
+	var err error
+	for i := 0; i < volumeDeleteAttempts; i++ {
+		err = doc.VolumesService.Delete(id)
+		switch {
+		case err == nil, isStatusError(err, http.StatusNotFound):
+			return nil
+		case !isVolumeAttachedError(err):
+			return err
+		}
+
+		time.Sleep(volumeDeleteDelay)
+	}
+
+	return err
+}
+
+// isVolumeAttachedError returns true if err means a volume couldn't be deleted because
+// it is still attached to a droplet.
+func isVolumeAttachedError(err error) bool {
+	er, ok := err.(*godo.ErrorResponse)
+	if !ok || er.Response == nil {
+		return false
+	}
+
+	return er.Response.StatusCode == http.StatusConflict ||
+		strings.Contains(strings.ToLower(er.Message), "attached")
+}
 
-	logWithTag.Info("tagging droplet")
-	if err := dc.doc.TagsService.TagResources(dc.tag, trr); err != nil {
-		dc.doc.DropletsService.Delete(droplet.ID)
-		logWithTag.WithError(err).Error("deleting droplet because it cannot be tagged")
+// isStatusError returns true if err is an API error with status.
+func isStatusError(err error, status int) bool {
+	er, ok := err.(*godo.ErrorResponse)
+	return ok && er.Response != nil && er.Response.StatusCode == status
+}
+
+func volumeName(dropletName string, i int) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(dropletName), i)
+}
+
+// isDropletVolume returns true if the volume was created by autoscale for droplet.
+func isDropletVolume(v do.Volume, droplet do.Droplet) bool {
+	attached := false
+	for _, id := range v.DropletIDs {
+		if id == droplet.ID {
+			attached = true
+			break
+		}
 	}
 
-	if err := dc.doc.TagsService.TagResources(BaseTag, trr); err != nil {
-		dc.doc.DropletsService.Delete(droplet.ID)
-		logWithTag.WithError(err).Error("deleting droplet because it cannot be tagged")
+	prefix := strings.ToLower(droplet.Name) + "-"
+	if !attached || !strings.HasPrefix(v.Name, prefix) {
+		return false
 	}
+
+	_, err := strconv.Atoi(strings.TrimPrefix(v.Name, prefix))
+	return err == nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsInt(list []int, i int) bool {
+	for _, item := range list {
+		if item == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+func templateTag(templateID string, version int) string {
+	return fmt.Sprintf("%s%s:%d", TemplateTagPrefix, templateID, version)
+}
+
+// templateFromTags returns the template and version a droplet was built from. It returns
+// an empty template id if the droplet doesn't have a template tag.
+func templateFromTags(tags []string) (string, int) {
+	for _, t := range tags {
+		if !strings.HasPrefix(t, TemplateTagPrefix) {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimPrefix(t, TemplateTagPrefix), ":")
+		if len(parts) != 2 {
+			continue
+		}
+
+		version, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		return parts[0], version
+	}
+
+	return "", 0
 }
 
 func verifyTag(tag string, doc *doclient.Client, log *logrus.Entry) error {
@@ -273,19 +1080,3 @@ func verifyTag(tag string, doc *doclient.Client, log *logrus.Entry) error {
 
 	return nil
 }
-
-var (
-	asUserData = `#!/usr/bin/env bash
-  version=0.12.0
-  binName=node_exporter-${version}.linux-amd64.tar.gz
-  dlPath=/tmp
-  distURL=https://github.com/prometheus/node_exporter/releases/download/${version}/${binName}
-
-  curl -s -L -o ${dlPath}/${binName} ${distURL}
-  mkdir -p /opt
-  tar -C /opt -xzf ${dlPath}/${binName}
-
-  curl -S -L -o /etc/init/node_exporter.conf https://gist.githubusercontent.com/bryanl/8cd63b1aa0f80d5dcb0f14abbb476f25/raw/51d706b0404cc9c20df9722c6dd3f9c52a296df9/node_exporter-upstart.conf
-  start node_exporter
-  `
-)
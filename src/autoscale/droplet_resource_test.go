@@ -0,0 +1,554 @@
+package autoscale
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"pkg/do"
+	"pkg/do/mocks"
+	"pkg/doclient"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestDropletResource_ScaleDownSkipsProtected(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	droplets := do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Tags: []string{"as-group", BaseTag, ProtectedTag}}},
+		{Droplet: &godo.Droplet{ID: 2, Tags: []string{"as-group", BaseTag}}},
+	}
+	ds.On("ListByTag", "as-group").Return(droplets, nil)
+	ds.On("Delete", 2).Return(nil)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	op := NewOperation("abc", -2)
+	changed, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	require.Equal(t, -1, op.ActualDelta)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleDownSkipsKept(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	droplets := do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Tags: []string{"as-group", BaseTag}}},
+		{Droplet: &godo.Droplet{ID: 2, Tags: []string{"as-group", BaseTag}}},
+	}
+	ds.On("ListByTag", "as-group").Return(droplets, nil)
+	ds.On("Delete", 2).Return(nil)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	op := NewOperation("abc", -1)
+	op.Keep = []int{1}
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestDropletResource_Protect(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("Get", 1).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 1, Tags: []string{"as-group"}}}, nil)
+	ds.On("Get", 2).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 2, Tags: []string{"other"}}}, nil)
+
+	ts := &mocks.TagsService{}
+	ts.On("List").Return(do.Tags{{Tag: &godo.Tag{Name: ProtectedTag}}}, nil)
+	ts.On("TagResources", ProtectedTag, &godo.TagResourcesRequest{
+		Resources: []godo.Resource{{ID: "1", Type: godo.DropletResourceType}},
+	}).Return(nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds, TagsService: ts},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	require.NoError(t, r.Protect(ctx, "1", true))
+	require.Equal(t, ErrResourceNotInGroup, r.Protect(ctx, "2", true))
+	require.Equal(t, ErrResourceNotInGroup, r.Protect(ctx, "nope", true))
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, ts.AssertExpectations(t))
+}
+
+func TestBootDroplet_Options(t *testing.T) {
+	ds := &mocks.DropletsService{}
+	vs := &mocks.VolumesService{}
+
+	vs.On("Create", mock.MatchedBy(func(vcr *do.VolumeCreateRequest) bool {
+		return vcr.Region == "nyc1" && vcr.SizeGigabytes == 10
+	})).Return(&do.Volume{ID: "vol-1"}, nil)
+
+	ds.On("CreateWithOptions", mock.MatchedBy(func(dcr *do.DropletCreateRequest) bool {
+		return dcr.PrivateNetworking && dcr.IPv6 && dcr.Backups && dcr.Monitoring &&
+			assert.ObjectsAreEqual([]string{agentPortTag(DefaultAgentPort), "web", VolumesTag}, dcr.Tags) &&
+			assert.ObjectsAreEqual([]string{"vol-1"}, dcr.Volumes)
+	}), true).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 1}}, nil)
+
+	dc := &dropletConfig{
+		doc:    &doclient.Client{DropletsService: ds, VolumesService: vs},
+		log:    logrus.WithField("test", "droplet-resource"),
+		name:   "as-abcde",
+		region: "nyc1",
+		template: &Template{
+			Region:            "nyc1",
+			Size:              "1gb",
+			Image:             "ubuntu-14-04-x64",
+			PrivateNetworking: true,
+			IPv6:              true,
+			Backups:           true,
+			Monitoring:        true,
+			Tags:              TemplateTags{"web"},
+			Volumes:           TemplateVolumes{{SizeGigabytes: 10}},
+		},
+	}
+
+	droplet, err := bootDroplet(dc)
+	require.NoError(t, err)
+	require.Equal(t, 1, droplet.ID)
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, vs.AssertExpectations(t))
+}
+
+func TestDropletResource_RemoveDeletesVolumes(t *testing.T) {
+	ctx := context.Background()
+
+	delay := volumeDeleteDelay
+	volumeDeleteDelay = 0
+	defer func() { volumeDeleteDelay = delay }()
+
+	ds := &mocks.DropletsService{}
+	droplet := &do.Droplet{Droplet: &godo.Droplet{ID: 1, Name: "as-abcde", Tags: []string{"as-group", VolumesTag}}}
+	ds.On("Get", 1).Return(droplet, nil)
+	ds.On("Delete", 1).Return(nil)
+
+	vs := &mocks.VolumesService{}
+	vs.On("List").Return(do.Volumes{
+		{ID: "vol-1", Name: "as-abcde-0", DropletIDs: []int{1}},
+		{ID: "vol-2", Name: "data", DropletIDs: []int{1}},
+		{ID: "vol-3", Name: "as-fghij-0", DropletIDs: []int{2}},
+	}, nil)
+	vs.On("Delete", "vol-1").Return(testAPIError(http.StatusConflict, "volume is attached")).Once()
+	vs.On("Delete", "vol-1").Return(nil).Once()
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds, VolumesService: vs},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	require.NoError(t, r.Remove(ctx, []string{"1"}))
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, vs.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleDownDeletesVolumes(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Name: "as-abcde", Tags: []string{"as-group", BaseTag, VolumesTag}}},
+	}, nil)
+	ds.On("Delete", 1).Return(nil)
+
+	vs := &mocks.VolumesService{}
+	vs.On("List").Return(do.Volumes{{ID: "vol-1", Name: "as-abcde-0", DropletIDs: []int{1}}}, nil)
+	vs.On("Delete", "vol-1").Return(testAPIError(http.StatusInternalServerError, "server error")).Once()
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := testDropletResource(&doclient.Client{DropletsService: ds, VolumesService: vs})
+
+	op := NewOperation("abc", -1)
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, -1, op.ActualDelta)
+	require.Len(t, op.Errors, 1)
+
+	assert.True(t, vs.AssertExpectations(t))
+}
+
+func testAPIError(status int, message string) error {
+	return &godo.ErrorResponse{
+		Response: &http.Response{
+			Request:    &http.Request{Method: "DELETE", URL: &url.URL{Path: "/v2/volumes"}},
+			StatusCode: status,
+		},
+		Message: message,
+	}
+}
+
+// testDroplet returns a droplet of the as-group group in nyc1 with a public address. Its
+// name and creation date are derived from id, so lower ids are older.
+func testDroplet(id int, status, address string, tags ...string) do.Droplet {
+	return do.Droplet{Droplet: &godo.Droplet{
+		ID:      id,
+		Name:    fmt.Sprintf("as-%d", id),
+		Status:  status,
+		Created: fmt.Sprintf("2017-01-%02dT00:00:00Z", id),
+		Region:  &godo.Region{Slug: "nyc1"},
+		Tags:    append([]string{"as-group", BaseTag}, tags...),
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: address, Type: "public"}},
+		},
+	}}
+}
+
+// testDropletResource returns a resource for the as-group group which uses doc.
+func testDropletResource(doc *doclient.Client) *DropletResource {
+	return &DropletResource{
+		doClient: doc,
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+}
+
+func TestDeleteVolume_Retries(t *testing.T) {
+	delay := volumeDeleteDelay
+	volumeDeleteDelay = 0
+	defer func() { volumeDeleteDelay = delay }()
+
+	vs := &mocks.VolumesService{}
+	vs.On("Delete", "gone").Return(testAPIError(http.StatusNotFound, "not found")).Once()
+	vs.On("Delete", "broken").Return(fmt.Errorf("boom")).Once()
+	vs.On("Delete", "stuck").Return(testAPIError(http.StatusConflict, "volume is attached"))
+
+	doc := &doclient.Client{VolumesService: vs}
+
+	require.NoError(t, deleteVolume(doc, "gone"))
+	require.EqualError(t, deleteVolume(doc, "broken"), "boom")
+	require.Error(t, deleteVolume(doc, "stuck"))
+
+	vs.AssertNumberOfCalls(t, "Delete", 2+volumeDeleteAttempts)
+}
+
+func TestDropletResource_ScaleUpRenderError(t *testing.T) {
+	ctx := context.Background()
+
+	tmpl := &Template{ID: "tmpl", Version: 1, UserData: "## template: go\n{{.Vars.missing}}"}
+
+	repo := &MockRepository{}
+	repo.On("GetTemplateVersion", ctx, "tmpl", 0).Return(tmpl, nil)
+
+	ts := &mocks.TagsService{}
+	ts.On("List").Return(do.Tags{{Tag: &godo.Tag{Name: templateTag("tmpl", 1)}}}, nil)
+
+	ds := &mocks.DropletsService{}
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds, TagsService: ts},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	op := NewOperation("abc", 2)
+	_, err := r.Scale(ctx, Group{ID: "abc", BaseName: "as", TemplateID: "tmpl"}, op, repo)
+	require.Error(t, err)
+	require.Equal(t, OperationPlanned, op.State)
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, repo.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleUpInvalidUserData(t *testing.T) {
+	ctx := context.Background()
+
+	tmpl := &Template{ID: "tmpl", Version: 1, UserData: "#cloud-config\nruncmd: [echo"}
+
+	repo := &MockRepository{}
+	repo.On("GetTemplateVersion", ctx, "tmpl", 0).Return(tmpl, nil)
+
+	ts := &mocks.TagsService{}
+	ts.On("List").Return(do.Tags{{Tag: &godo.Tag{Name: templateTag("tmpl", 1)}}}, nil)
+
+	ds := &mocks.DropletsService{}
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds, TagsService: ts},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	op := NewOperation("abc", 2)
+	_, err := r.Scale(ctx, Group{ID: "abc", BaseName: "as", TemplateID: "tmpl"}, op, repo)
+	require.Error(t, err)
+	require.Equal(t, OperationPlanned, op.State)
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, repo.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleUpRegionFallback(t *testing.T) {
+	ctx := context.Background()
+
+	tmpl := &Template{ID: "tmpl", Version: 1, Region: "nyc1", Agent: Agent{Type: AgentNone}}
+
+	repo := &MockRepository{}
+	repo.On("GetTemplateVersion", ctx, "tmpl", 0).Return(tmpl, nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	ts := &mocks.TagsService{}
+	ts.On("List").Return(do.Tags{{Tag: &godo.Tag{Name: templateTag("tmpl", 1)}}}, nil)
+	ts.On("TagResources", mock.Anything, mock.Anything).Return(nil)
+
+	capacityErr := &godo.ErrorResponse{
+		Response: &http.Response{
+			Request:    &http.Request{Method: "POST", URL: &url.URL{Path: "/v2/droplets"}},
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+		Message: "Droplet creation is currently unavailable in this region.",
+	}
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Region: &godo.Region{Slug: "sfo2"}}},
+	}, nil)
+	ds.On("CreateWithOptions", mock.MatchedBy(func(dcr *do.DropletCreateRequest) bool {
+		return dcr.Region == "nyc1"
+	}), true).Return(nil, capacityErr)
+	ds.On("CreateWithOptions", mock.MatchedBy(func(dcr *do.DropletCreateRequest) bool {
+		return dcr.Region == "sfo2"
+	}), true).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 2}}, nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds, TagsService: ts},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	g := Group{
+		ID:         "abc",
+		BaseName:   "as",
+		TemplateID: "tmpl",
+		Regions:    GroupRegions{{Region: "nyc1"}, {Region: "sfo2"}},
+	}
+
+	op := NewOperation("abc", 1)
+	_, err := r.Scale(ctx, g, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, op.ActualDelta)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+	require.Empty(t, op.Errors)
+
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleUpRegionsFull(t *testing.T) {
+	ctx := context.Background()
+
+	tmpl := &Template{ID: "tmpl", Version: 1, Region: "nyc1", Agent: Agent{Type: AgentNone}}
+
+	repo := &MockRepository{}
+	repo.On("GetTemplateVersion", ctx, "tmpl", 0).Return(tmpl, nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	ts := &mocks.TagsService{}
+	ts.On("List").Return(do.Tags{{Tag: &godo.Tag{Name: templateTag("tmpl", 1)}}}, nil)
+	ts.On("TagResources", mock.Anything, mock.Anything).Return(nil)
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Region: &godo.Region{Slug: "nyc1"}}},
+	}, nil)
+	ds.On("CreateWithOptions", mock.MatchedBy(func(dcr *do.DropletCreateRequest) bool {
+		return dcr.Region == "sfo2"
+	}), true).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 2}}, nil).Once()
+
+	r := testDropletResource(&doclient.Client{DropletsService: ds, TagsService: ts})
+
+	g := Group{
+		ID:         "abc",
+		BaseName:   "as",
+		TemplateID: "tmpl",
+		Regions:    GroupRegions{{Region: "nyc1", Max: 1}, {Region: "sfo2", Max: 1}},
+	}
+
+	op := NewOperation("abc", 3)
+	_, err := r.Scale(ctx, g, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, op.ActualDelta)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+	require.Len(t, op.Errors, 1)
+	require.Contains(t, op.Errors[0], "2 of 3 droplets were not created")
+
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleDownBalancesRegions(t *testing.T) {
+	ctx := context.Background()
+
+	nyc1 := &godo.Region{Slug: "nyc1"}
+	sfo2 := &godo.Region{Slug: "sfo2"}
+
+	ds := &mocks.DropletsService{}
+	droplets := do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Region: nyc1}},
+		{Droplet: &godo.Droplet{ID: 2, Region: nyc1}},
+		{Droplet: &godo.Droplet{ID: 3, Region: nyc1}},
+		{Droplet: &godo.Droplet{ID: 4, Region: sfo2}},
+	}
+	ds.On("ListByTag", "as-group").Return(droplets, nil)
+	ds.On("Delete", mock.MatchedBy(func(id int) bool { return id <= 3 })).Return(nil)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	g := Group{
+		ID:      "abc",
+		Regions: GroupRegions{{Region: "nyc1", Min: 1}, {Region: "sfo2", Min: 1}},
+	}
+
+	op := NewOperation("abc", -3)
+	_, err := r.Scale(ctx, g, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, -2, op.ActualDelta)
+	require.Len(t, op.DropletIDs, 2)
+	require.NotContains(t, op.DropletIDs, 4)
+
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestDropletResource_TeardownIgnoresRegionMinimums(t *testing.T) {
+	ctx := context.Background()
+
+	nyc1 := &godo.Region{Slug: "nyc1"}
+	sfo2 := &godo.Region{Slug: "sfo2"}
+
+	ds := &mocks.DropletsService{}
+	droplets := do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Region: nyc1}},
+		{Droplet: &godo.Droplet{ID: 2, Region: sfo2}},
+	}
+	ds.On("ListByTag", "as-group").Return(droplets, nil)
+	ds.On("Delete", mock.AnythingOfType("int")).Return(nil)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient: &doclient.Client{DropletsService: ds},
+		tag:      "as-group",
+		log:      logrus.WithField("test", "droplet-resource"),
+	}
+
+	g := Group{
+		ID:      "abc",
+		Regions: GroupRegions{{Region: "nyc1", Min: 1}, {Region: "sfo2", Min: 1}},
+	}
+
+	op := NewOperation("abc", -2)
+	op.Teardown = true
+	_, err := r.Scale(ctx, g, op, repo)
+	require.NoError(t, err)
+
+	require.Equal(t, -2, op.ActualDelta)
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestBootDroplet_FallbackSizes(t *testing.T) {
+	capacityErr := &godo.ErrorResponse{
+		Response: &http.Response{
+			Request:    &http.Request{Method: "POST", URL: &url.URL{Path: "/v2/droplets"}},
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+		Message: "The size you selected is not available in this region.",
+	}
+
+	sizeIs := func(size string) interface{} {
+		return mock.MatchedBy(func(dcr *do.DropletCreateRequest) bool {
+			return dcr.Size == size
+		})
+	}
+
+	ds := &mocks.DropletsService{}
+	ds.On("CreateWithOptions", sizeIs("1gb"), true).Return(nil, capacityErr).Once()
+	ds.On("CreateWithOptions", sizeIs("2gb"), true).Return(nil, capacityErr).Once()
+	ds.On("CreateWithOptions", sizeIs("4gb"), true).Return(&do.Droplet{Droplet: &godo.Droplet{ID: 1, SizeSlug: "4gb"}}, nil).Once()
+
+	dc := &dropletConfig{
+		doc:    &doclient.Client{DropletsService: ds},
+		log:    logrus.WithField("test", "droplet-resource"),
+		name:   "as-abcde",
+		region: "nyc1",
+		template: &Template{
+			Region:        "nyc1",
+			Size:          "1gb",
+			FallbackSizes: TemplateSizes{"2gb", "1gb", "4gb"},
+		},
+	}
+
+	droplet, err := bootDroplet(dc)
+	require.NoError(t, err)
+	require.Equal(t, "4gb", dropletSize(*droplet))
+
+	assert.True(t, ds.AssertExpectations(t))
+}
+
+func TestBootDroplet_FallbackSizesOtherErrors(t *testing.T) {
+	ds := &mocks.DropletsService{}
+	ds.On("CreateWithOptions", mock.Anything, true).Return(nil, assert.AnError).Once()
+
+	dc := &dropletConfig{
+		doc:    &doclient.Client{DropletsService: ds},
+		log:    logrus.WithField("test", "droplet-resource"),
+		name:   "as-abcde",
+		region: "nyc1",
+		template: &Template{
+			Region:        "nyc1",
+			Size:          "1gb",
+			FallbackSizes: TemplateSizes{"2gb"},
+		},
+	}
+
+	_, err := bootDroplet(dc)
+	require.Equal(t, assert.AnError, err)
+
+	assert.True(t, ds.AssertExpectations(t))
+}
@@ -0,0 +1,50 @@
+package autoscale
+
+import (
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	// DefaultEventLimit is how many events are listed when a query has no limit.
+	DefaultEventLimit = 50
+
+	// MaxEventLimit is the most events listed by a query.
+	MaxEventLimit = 500
+)
+
+var (
+	// ErrInvalidEventCursor is returned when an event query's cursor isn't an event ID.
+	ErrInvalidEventCursor = errors.Errorf("event cursor is not an event id")
+
+	// ErrUnknownEventCursor is returned when an event query's cursor is an event which
+	// isn't stored, e.g. because it was pruned.
+	ErrUnknownEventCursor = errors.Errorf("event cursor is not a stored event")
+)
+
+// EventQuery selects stored notifications. Events are listed newest first, starting
+// before the event identified by Before if it is set. If After is set instead, events
+// newer than the one it identifies are listed oldest first, which is how clients catch
+// up on what they missed. Before and After must be IDs of stored events.
+type EventQuery struct {
+	GroupID string
+	Events  []string
+	Since   time.Time
+	Until   time.Time
+	Before  string
+	After   string
+	Limit   int
+}
+
+// limit returns the query's limit within the allowed range.
+func (q EventQuery) limit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultEventLimit
+	case q.Limit > MaxEventLimit:
+		return MaxEventLimit
+	default:
+		return q.Limit
+	}
+}
@@ -0,0 +1,231 @@
+package autoscale
+
+import (
+	"fmt"
+	"pkg/do"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// ActivityFloatingIPReassigned is the kind of activities recording a group's floating
+// IP moving to another droplet.
+const ActivityFloatingIPReassigned ActivityKind = "floating-ip-reassigned"
+
+// FloatingIPChange records a group's floating IP moving between droplets. A droplet ID
+// of 0 means the IP was unassigned.
+type FloatingIPChange struct {
+	IP            string
+	FromDropletID int
+	ToDropletID   int
+	Reason        string
+}
+
+func (c FloatingIPChange) String() string {
+	from := "no droplet"
+	if c.FromDropletID != 0 {
+		from = fmt.Sprintf("droplet %d", c.FromDropletID)
+	}
+
+	if c.ToDropletID == 0 {
+		return fmt.Sprintf("floating ip %s unassigned from %s: %s", c.IP, from, c.Reason)
+	}
+
+	return fmt.Sprintf("floating ip %s reassigned from %s to droplet %d: %s", c.IP, from, c.ToDropletID, c.Reason)
+}
+
+// ReconcileFloatingIP makes sure the group's floating IP is assigned to a healthy droplet
+// in the group. It returns the reassignments made since it was last called, including
+// ones made while scaling down or removing droplets.
+func (r *DropletResource) ReconcileFloatingIP(ctx context.Context) ([]FloatingIPChange, error) {
+	if r.floatingIP == "" {
+		return nil, nil
+	}
+
+	err := r.reconcileFloatingIP(nil, "")
+
+	changes := r.floatingIPChanges
+	r.floatingIPChanges = nil
+
+	return changes, err
+}
+
+// releaseFloatingIP moves the group's floating IP off droplets which are about to be
+// deleted or leave the group.
+func (r *DropletResource) releaseFloatingIP(ids []int, reason string) error {
+	if r.floatingIP == "" || len(ids) == 0 {
+		return nil
+	}
+
+	exclude := map[int]bool{}
+	for _, id := range ids {
+		exclude[id] = true
+	}
+
+	return r.reconcileFloatingIP(exclude, reason)
+}
+
+// reconcileFloatingIP reassigns the group's floating IP if it isn't assigned, or its
+// droplet is excluded, not in the group or unhealthy. Protected droplets are preferred,
+// then the oldest droplet. If no droplet can take the IP, an unhealthy droplet keeps it
+// and an excluded droplet has it unassigned. If reason is empty, it is derived from why
+// the current droplet can't keep the IP.
+func (r *DropletResource) reconcileFloatingIP(exclude map[int]bool, reason string) error {
+	fip, err := r.doClient.FloatingIPsService.Get(r.floatingIP)
+	if err != nil {
+		return err
+	}
+
+	droplets, err := r.doClient.DropletsService.ListByTag(r.tag)
+	if err != nil {
+		return err
+	}
+
+	var lb *do.LoadBalancer
+	if r.loadBalancerID != "" {
+		if lb, err = r.doClient.LoadBalancersService.Get(r.loadBalancerID); err != nil {
+			return err
+		}
+	}
+
+	current := 0
+	if fip.Droplet != nil {
+		current = fip.Droplet.ID
+	}
+
+	log := r.log.WithFields(logrus.Fields{
+		"floating-ip": r.floatingIP,
+		"droplet-id":  current,
+	})
+
+	candidates := do.Droplets{}
+	var holder *do.Droplet
+	for i, d := range droplets {
+		if d.ID == current {
+			holder = &droplets[i]
+		}
+
+		if exclude[d.ID] || d.Status != "active" {
+			continue
+		}
+
+		if fip.Region != nil && dropletRegion(d) != fip.Region.Slug {
+			continue
+		}
+
+		if lb != nil && !isLoadBalanced(lb, d) {
+			continue
+		}
+
+		candidates = append(candidates, d)
+	}
+
+	var holderErr error
+	switch {
+	case current == 0:
+		holderErr = fmt.Errorf("floating ip is not assigned")
+	case exclude[current]:
+		holderErr = fmt.Errorf("droplet is leaving the group")
+	case holder == nil:
+		holderErr = fmt.Errorf("droplet is not in the group")
+	default:
+		holderErr = floatingIPHealth(lb, *holder)
+	}
+
+	if holderErr == nil {
+		return nil
+	}
+
+	if reason == "" {
+		reason = holderErr.Error()
+	}
+
+	if len(candidates) == 0 {
+		if current == 0 || !exclude[current] {
+			log.WithError(holderErr).Warn("no healthy droplet can take the floating ip")
+			return nil
+		}
+
+		log.WithField("reason", reason).Info("unassigning floating ip")
+		if _, err := r.doClient.FloatingIPActionsService.Unassign(r.floatingIP); err != nil {
+			return err
+		}
+
+		r.recordFloatingIPChange(current, 0, reason)
+		return nil
+	}
+
+	sort.Sort(floatingIPCandidates(candidates))
+	next := candidates[0].ID
+
+	log.WithFields(logrus.Fields{
+		"new-droplet-id": next,
+		"reason":         reason,
+	}).Info("reassigning floating ip")
+	if _, err := r.doClient.FloatingIPActionsService.Assign(r.floatingIP, next); err != nil {
+		return err
+	}
+
+	r.recordFloatingIPChange(current, next, reason)
+	return nil
+}
+
+func (r *DropletResource) recordFloatingIPChange(from, to int, reason string) {
+	r.floatingIPChanges = append(r.floatingIPChanges, FloatingIPChange{
+		IP:            r.floatingIP,
+		FromDropletID: from,
+		ToDropletID:   to,
+		Reason:        reason,
+	})
+}
+
+// floatingIPHealth returns an error if the droplet holding a floating IP is not active.
+// Droplets of a group with a load balancer must also be behind it and pass its health
+// check.
+func floatingIPHealth(lb *do.LoadBalancer, d do.Droplet) error {
+	if d.Status != "active" {
+		return fmt.Errorf("droplet is %s", d.Status)
+	}
+
+	if lb == nil {
+		return nil
+	}
+
+	if !isLoadBalanced(lb, d) {
+		return fmt.Errorf("droplet is not behind the load balancer")
+	}
+
+	if lb.HealthCheck == nil || (lb.Region != nil && dropletRegion(d) != lb.Region.Slug) {
+		return nil
+	}
+
+	address, err := healthCheckAddress(d)
+	if err != nil {
+		return err
+	}
+
+	if err := checkHealth(lb.HealthCheck, address); err != nil {
+		return fmt.Errorf("droplet failed health check: %v", err)
+	}
+
+	return nil
+}
+
+// floatingIPCandidates sorts droplets by which should take a floating IP first.
+type floatingIPCandidates do.Droplets
+
+func (c floatingIPCandidates) Len() int      { return len(c) }
+func (c floatingIPCandidates) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c floatingIPCandidates) Less(i, j int) bool {
+	pi, pj := hasTag(c[i].Tags, ProtectedTag), hasTag(c[j].Tags, ProtectedTag)
+	if pi != pj {
+		return pi
+	}
+
+	if c[i].Created != c[j].Created {
+		return c[i].Created < c[j].Created
+	}
+
+	return c[i].ID < c[j].ID
+}
@@ -0,0 +1,258 @@
+package autoscale
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pkg/do"
+	"pkg/do/mocks"
+	"pkg/doclient"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func testFloatingIP(dropletID int) *do.FloatingIP {
+	fip := &do.FloatingIP{FloatingIP: &godo.FloatingIP{
+		IP:     "10.0.0.1",
+		Region: &godo.Region{Slug: "nyc1"},
+	}}
+
+	if dropletID != 0 {
+		fip.Droplet = &godo.Droplet{ID: dropletID}
+	}
+
+	return fip
+}
+
+func testFloatingIPResource(ds *mocks.DropletsService, fs *mocks.FloatingIPsService, fas *mocks.FloatingIPActionsService) *DropletResource {
+	r := testDropletResource(&doclient.Client{
+		DropletsService:          ds,
+		FloatingIPsService:       fs,
+		FloatingIPActionsService: fas,
+	})
+	r.floatingIP = "10.0.0.1"
+
+	return r
+}
+
+func TestDropletResource_ReconcileFloatingIP(t *testing.T) {
+	ctx := context.Background()
+
+	other := testDroplet(4, "active", "10.0.0.14")
+	other.Region = &godo.Region{Slug: "sfo2"}
+
+	cases := []struct {
+		name     string
+		holder   int
+		droplets do.Droplets
+		assignTo int
+		reason   string
+	}{
+		{
+			name:   "unassigned prefers protected droplets",
+			holder: 0,
+			droplets: do.Droplets{
+				testDroplet(1, "active", "10.0.0.11"),
+				testDroplet(2, "active", "10.0.0.12", ProtectedTag),
+				other,
+			},
+			assignTo: 2,
+			reason:   "floating ip is not assigned",
+		},
+		{
+			name:   "unhealthy droplet moves to the oldest droplet",
+			holder: 1,
+			droplets: do.Droplets{
+				testDroplet(1, "off", "10.0.0.11"),
+				testDroplet(2, "active", "10.0.0.12"),
+				testDroplet(3, "active", "10.0.0.13"),
+				other,
+			},
+			assignTo: 2,
+			reason:   "droplet is off",
+		},
+		{
+			name:   "droplet outside the group",
+			holder: 9,
+			droplets: do.Droplets{
+				testDroplet(1, "active", "10.0.0.11"),
+			},
+			assignTo: 1,
+			reason:   "droplet is not in the group",
+		},
+		{
+			name:   "healthy droplet keeps the floating ip",
+			holder: 2,
+			droplets: do.Droplets{
+				testDroplet(1, "active", "10.0.0.11"),
+				testDroplet(2, "active", "10.0.0.12"),
+			},
+		},
+		{
+			name:   "unhealthy droplet keeps the floating ip without replacements",
+			holder: 1,
+			droplets: do.Droplets{
+				testDroplet(1, "off", "10.0.0.11"),
+				other,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		ds := &mocks.DropletsService{}
+		ds.On("ListByTag", "as-group").Return(c.droplets, nil)
+
+		fs := &mocks.FloatingIPsService{}
+		fs.On("Get", "10.0.0.1").Return(testFloatingIP(c.holder), nil)
+
+		fas := &mocks.FloatingIPActionsService{}
+		fas.On("Assign", "10.0.0.1", mock.Anything).Return(&do.Action{}, nil)
+
+		r := testFloatingIPResource(ds, fs, fas)
+
+		changes, err := r.ReconcileFloatingIP(ctx)
+		require.NoError(t, err, c.name)
+
+		if c.assignTo == 0 {
+			assert.Empty(t, changes, c.name)
+			fas.AssertNotCalled(t, "Assign", "10.0.0.1", mock.Anything)
+			continue
+		}
+
+		fas.AssertCalled(t, "Assign", "10.0.0.1", c.assignTo)
+		expected := []FloatingIPChange{{IP: "10.0.0.1", FromDropletID: c.holder, ToDropletID: c.assignTo, Reason: c.reason}}
+		assert.Equal(t, expected, changes, c.name)
+	}
+}
+
+func TestDropletResource_ScaleDownMovesFloatingIP(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		testDroplet(1, "active", "10.0.0.11", ProtectedTag),
+		testDroplet(2, "active", "10.0.0.12"),
+	}, nil)
+	ds.On("Delete", 2).Return(nil)
+
+	fs := &mocks.FloatingIPsService{}
+	fs.On("Get", "10.0.0.1").Return(testFloatingIP(2), nil).Once()
+	fs.On("Get", "10.0.0.1").Return(testFloatingIP(1), nil)
+
+	fas := &mocks.FloatingIPActionsService{}
+	fas.On("Assign", "10.0.0.1", 1).Return(&do.Action{}, nil).Once()
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := testFloatingIPResource(ds, fs, fas)
+
+	op := NewOperation("abc", -1)
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+
+	changes, err := r.ReconcileFloatingIP(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []FloatingIPChange{
+		{IP: "10.0.0.1", FromDropletID: 2, ToDropletID: 1, Reason: "droplet is being scaled down"},
+	}, changes)
+
+	changes, err = r.ReconcileFloatingIP(ctx)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, fas.AssertExpectations(t))
+}
+
+func TestDropletResource_RemoveUnassignsFloatingIP(t *testing.T) {
+	ctx := context.Background()
+
+	droplet := testDroplet(1, "active", "10.0.0.11")
+
+	ds := &mocks.DropletsService{}
+	ds.On("Get", 1).Return(&droplet, nil)
+	ds.On("ListByTag", "as-group").Return(do.Droplets{droplet}, nil)
+	ds.On("Delete", 1).Return(nil)
+
+	fs := &mocks.FloatingIPsService{}
+	fs.On("Get", "10.0.0.1").Return(testFloatingIP(1), nil)
+
+	fas := &mocks.FloatingIPActionsService{}
+	fas.On("Unassign", "10.0.0.1").Return(&do.Action{}, nil)
+
+	r := testFloatingIPResource(ds, fs, fas)
+
+	require.NoError(t, r.Remove(ctx, []string{"1"}))
+	require.Equal(t, []FloatingIPChange{
+		{IP: "10.0.0.1", FromDropletID: 1, Reason: "droplet is being removed"},
+	}, r.floatingIPChanges)
+	require.Equal(t, "floating ip 10.0.0.1 unassigned from droplet 1: droplet is being removed", r.floatingIPChanges[0].String())
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, fas.AssertExpectations(t))
+}
+
+func TestCheckScale_RecordsFloatingIPActivity(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	ogDefaultConfig := DefaultConfig
+	defer func() {
+		ResourceManagerFactory = ogFactory
+		DefaultConfig = ogDefaultConfig
+	}()
+
+	tmpPath, err := ioutil.TempDir("", "autoscaler")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpPath)
+
+	DefaultConfig[OptionFileLoadPath] = tmpPath
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpPath, "test-group"), []byte("0.5"), 0600))
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	change := FloatingIPChange{IP: "10.0.0.1", FromDropletID: 1, ToDropletID: 2, Reason: "droplet is off"}
+
+	rm := &MockResourceManager{}
+	rm.On("Count").Return(1, nil)
+	rm.On("ReconcileFloatingIP", ctx).Return([]FloatingIPChange{change}, nil)
+
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return rm, nil
+	}
+
+	metric, err := NewFileLoad()
+	require.NoError(t, err)
+
+	policy, err := NewValuePolicy(ValuePolicyScale(1, 10, 0.8, 2, 0.2, 1))
+	require.NoError(t, err)
+
+	repo := &MockRepository{}
+	repo.On("GetGroup", ctx, "id").Return(&Group{
+		ID:         "id",
+		Name:       "test-group",
+		MetricType: "load",
+		Metric:     metric,
+		PolicyType: "value",
+		Policy:     policy,
+		FloatingIP: "10.0.0.1",
+	}, nil)
+
+	as := NewCheck(repo).Scale(ctx, "id")
+	require.NoError(t, as.Err)
+	require.Equal(t, []SchedulerActivity{
+		{
+			ID:      "id",
+			Count:   1,
+			Kind:    ActivityFloatingIPReassigned,
+			Message: "floating ip 10.0.0.1 reassigned from droplet 1 to droplet 2: droplet is off",
+		},
+	}, as.Activities)
+}
@@ -22,6 +22,40 @@
 // db/migrations/0002_create_groups.up.sql
 // db/migrations/0003_create_group_status.down.sql
 // db/migrations/0003_create_group_status.up.sql
+// db/migrations/0004_create_operations.down.sql
+// db/migrations/0004_create_operations.up.sql
+// db/migrations/0005_create_refreshes.down.sql
+// db/migrations/0005_create_refreshes.up.sql
+// db/migrations/0006_create_template_versions.down.sql
+// db/migrations/0006_create_template_versions.up.sql
+// db/migrations/0007_add_template_options.down.sql
+// db/migrations/0007_add_template_options.up.sql
+// db/migrations/0008_add_group_vars.down.sql
+// db/migrations/0008_add_group_vars.up.sql
+// db/migrations/0009_add_template_agent.down.sql
+// db/migrations/0009_add_template_agent.up.sql
+// db/migrations/0010_add_group_user_data.down.sql
+// db/migrations/0010_add_group_user_data.up.sql
+// db/migrations/0011_add_group_regions.down.sql
+// db/migrations/0011_add_group_regions.up.sql
+// db/migrations/0012_add_template_fallback_sizes.down.sql
+// db/migrations/0012_add_template_fallback_sizes.up.sql
+// db/migrations/0013_add_group_load_balancer.down.sql
+// db/migrations/0013_add_group_load_balancer.up.sql
+// db/migrations/0014_add_group_floating_ip.down.sql
+// db/migrations/0014_add_group_floating_ip.up.sql
+// db/migrations/0015_add_group_dns.down.sql
+// db/migrations/0015_add_group_dns.up.sql
+// db/migrations/0016_add_notification_sinks.down.sql
+// db/migrations/0016_add_notification_sinks.up.sql
+// db/migrations/0017_add_group_status_decision.down.sql
+// db/migrations/0017_add_group_status_decision.up.sql
+// db/migrations/0018_create_events.down.sql
+// db/migrations/0018_create_events.up.sql
+// db/migrations/0019_add_activity_kinds.down.sql
+// db/migrations/0019_add_activity_kinds.up.sql
+// db/migrations/0020_add_operation_health_passes.down.sql
+// db/migrations/0020_add_operation_health_passes.up.sql
 // DO NOT EDIT!
 
 package gen
@@ -529,6 +563,686 @@ func dbMigrations0003_create_group_statusUpSql() (*asset, error) {
 	return a, nil
 }
 
+var _dbMigrations0004_create_operationsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x2f\x48\x2d\x4a\x2c\xc9\xcc\xcf\x2b\x8e\x2f\x2e\x49\x2c\x49\x8d\xcf\x4c\xa9\xb0\xe6\x72\xc1\xaa\x20\xbd\x28\xbf\xb4\x00\xa8\x00\x49\x4d\x88\xa3\x93\x8f\x2b\x92\x1a\x6b\x00\x67\x6b\x4c\x8b\x5b\x00\x00\x00")
+
+func dbMigrations0004_create_operationsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0004_create_operationsDownSql,
+		"db/migrations/0004_create_operations.down.sql",
+	)
+}
+
+func dbMigrations0004_create_operationsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0004_create_operationsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0004_create_operations.down.sql", size: 91, mode: os.FileMode(420), modTime: time.Unix(1792384878, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0004_create_operationsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x50\xc1\x6a\xc3\x30\x0c\xbd\xe7\x2b\x74\x4c\xa0\x7f\xd0\x53\xda\x78\x10\xd6\x8d\x12\x12\x58\x4f\xc6\x8b\xb5\xcc\x23\xb1\x33\x59\x66\x65\x5f\x3f\x3b\x25\x2c\xb0\x8d\x81\x74\xd0\x7b\x4f\x4f\xf6\x3b\x36\xa2\x6c\x05\xb4\xe5\xe1\x24\xc0\xcd\x48\x8a\x8d\xb3\x1e\xf2\x0c\xc0\x68\xe8\xba\xba\x82\x73\x53\x3f\x94\xcd\x05\xee\xc5\x05\x2a\x71\x57\x76\xa7\x16\x06\xb4\x92\x94\xd5\x6e\x92\x21\x18\x9d\x17\xbb\xb8\x30\x90\x0b\xb3\x5c\xd7\x08\x5f\x90\xd0\xf6\xe8\x6f\x84\xcf\x8d\x5e\x64\x9e\x15\x23\x30\x5e\x19\xac\x8b\x1d\xc6\x31\xc1\x84\xef\x01\x3d\xa3\x96\x1a\x47\x56\x60\x2c\xe3\x80\x94\x28\xd5\x73\x50\xe3\x4f\x5c\x93\x9b\x47\xe4\x78\xd2\xc3\x9b\x77\xf6\x39\x81\x48\xe4\x68\x33\xf7\x84\x2a\xb9\x2a\x06\x36\x53\xbc\xa0\xa6\x19\x3e\x0c\xbf\x2e\x23\x7c\x3a\x8b\x49\x16\x66\xfd\x9f\x2c\x2b\xf6\x59\x76\xbc\x25\x56\x3f\x56\xe2\x69\x93\x98\x5c\x3f\x1f\xeb\x0a\xce\x6e\xa8\x7c\xa5\x76\xdf\x4f\x89\x4e\x7f\x19\x2d\xf1\xfc\xe2\xb2\xe0\xc5\xfe\x0b\x37\x90\x46\x7b\xb2\x01\x00\x00")
+
+func dbMigrations0004_create_operationsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0004_create_operationsUpSql,
+		"db/migrations/0004_create_operations.up.sql",
+	)
+}
+
+func dbMigrations0004_create_operationsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0004_create_operationsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0004_create_operations.up.sql", size: 434, mode: os.FileMode(420), modTime: time.Unix(1792384878, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0005_create_refreshesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\x28\x4a\x4d\x2b\x4a\x2d\xce\x48\x2d\x8e\x2f\x2e\x49\x2c\x49\x8d\xcf\x4c\xa9\xb0\xe6\x72\xc1\x26\x9f\x5e\x94\x5f\x5a\x00\x94\x47\x52\x12\xe2\xe8\xe4\xe3\x8a\x50\x62\x0d\x00\xee\x1d\xa0\xdb\x58\x00\x00\x00")
+
+func dbMigrations0005_create_refreshesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0005_create_refreshesDownSql,
+		"db/migrations/0005_create_refreshes.down.sql",
+	)
+}
+
+func dbMigrations0005_create_refreshesDownSql() (*asset, error) {
+	bytes, err := dbMigrations0005_create_refreshesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0005_create_refreshes.down.sql", size: 88, mode: os.FileMode(420), modTime: time.Unix(1792385670, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0005_create_refreshesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x91\x41\x6a\xc3\x30\x10\x45\xf7\x3e\xc5\x2c\x6d\xc8\x0d\xb2\x72\x63\x15\x4c\x93\x52\x8c\x0d\xcd\x4a\x38\xd6\xd4\x56\xb1\x25\x31\x92\xda\x34\xa7\xaf\xe4\xe2\x3a\xb4\x29\x05\x69\xa1\xff\xff\x3c\x46\x33\xbb\x8a\xe5\x35\x83\x3a\xbf\xdb\x33\x20\x7c\x21\xb4\x03\x5a\x48\x13\x00\x29\xa0\x69\xca\x02\x9e\xaa\xf2\x90\x57\x47\x78\x60\x47\x28\xd8\x7d\xde\xec\x6b\xe8\x51\x71\x6a\x95\xd0\x13\xf7\x5e\x8a\x34\xdb\x84\x82\x9e\xb4\x37\x7c\x29\x0b\x30\x24\x54\x5d\xa0\xcd\x86\x4d\xa5\x98\x63\xd6\xb5\x0e\xc1\xe1\xd9\x81\xd2\xe1\xfa\x71\x8c\xb2\xc3\xc9\x8c\xc1\xb9\x05\x58\xbc\x6f\x86\x21\x7c\x93\xda\x5b\x7e\x5d\x15\x91\xd1\x9c\xa4\xe2\x03\xb6\xa3\x1b\x3e\xb8\x41\xea\x50\x39\x90\xca\x61\x8f\x14\xed\x53\xeb\xba\x81\x5b\x79\xc1\x6b\x95\x30\x70\x3a\x14\x01\x64\xe1\xd5\x6a\x75\x8a\x6a\x47\x18\xe0\x3f\x44\x24\xd2\x74\x23\xd4\x3a\x70\x72\xc2\xf0\xbf\xc9\xc0\xbb\x74\xc3\xfc\x84\x8b\x56\x18\x63\xde\x88\xff\x62\x49\xb6\x4d\x92\xdd\xd7\x4a\xca\xc7\x82\x3d\xaf\x2b\xe1\xcb\x74\xc3\x39\x83\x56\xab\x93\x2e\xce\x66\x6d\x24\x70\xfe\xc0\xcc\xd3\xff\xcd\x98\xe5\x6c\xfb\x09\x9b\x73\xd5\x78\x0f\x02\x00\x00")
+
+func dbMigrations0005_create_refreshesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0005_create_refreshesUpSql,
+		"db/migrations/0005_create_refreshes.up.sql",
+	)
+}
+
+func dbMigrations0005_create_refreshesUpSql() (*asset, error) {
+	bytes, err := dbMigrations0005_create_refreshesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0005_create_refreshes.up.sql", size: 527, mode: os.FileMode(420), modTime: time.Unix(1792385672, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0006_create_template_versionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x8e\xb1\x0e\x82\x30\x14\x45\xf7\x7e\xc5\x1b\xf1\x1b\x98\x2a\x3c\x0c\xb1\x16\x53\xea\xe0\x44\x4c\x7c\x68\x83\x4a\xd3\x02\x89\x7f\x2f\xd1\x08\xc2\x82\xf3\xc9\xb9\xe7\x72\xa1\x51\x81\xe6\x6b\x81\xe0\xa8\x74\xe4\xaf\xe4\x21\x56\xd9\x1e\xa2\x4c\x1c\x76\x12\xac\xa3\xce\xd4\xad\x2f\x1a\xba\xdb\xdb\xa9\xa1\xa2\x23\xe7\x4d\xfd\x08\x19\x5f\x94\xff\x75\x78\x1c\xf7\x8a\xcc\xb5\xe2\xa9\xd4\x23\x18\xa3\xe6\x5c\x94\x15\x3d\x21\xc9\x14\xa6\x1b\x09\x5b\x3c\x42\xf0\x43\x57\xa0\x30\x41\x85\x32\xc2\x7c\xc8\xfa\xa0\x07\x21\x9b\x44\x2f\xae\x6e\xed\xd2\x4b\xf6\xc6\x1f\x61\x4e\xfd\x6c\x70\x88\x4d\x36\xbf\x53\x2f\x9e\x19\x61\xde\x61\x01\x00\x00")
+
+func dbMigrations0006_create_template_versionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0006_create_template_versionsDownSql,
+		"db/migrations/0006_create_template_versions.down.sql",
+	)
+}
+
+func dbMigrations0006_create_template_versionsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0006_create_template_versionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0006_create_template_versions.down.sql", size: 353, mode: os.FileMode(420), modTime: time.Unix(1792385866, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0006_create_template_versionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9d\x92\x31\x6f\x83\x30\x10\x85\x77\x7e\xc5\x8d\x20\x79\x68\xe7\x4c\x2e\xb8\x52\x54\x02\x91\x21\x43\x26\x44\xc3\x85\xb8\x05\x83\x6c\x93\xb4\xf9\xf5\x35\x69\x0a\x34\x6d\xd4\x2a\xe3\xf9\x7c\x9f\xdf\x7b\x3e\x1a\xa6\x8c\x43\x4a\x1f\x42\x06\x06\xeb\xb6\xca\x0d\x6a\xa0\x41\x00\x7e\x1c\xae\x16\x11\xec\x51\x69\xd1\x48\x10\xd2\x60\x89\x0a\x64\x63\x40\x76\x55\x05\x05\x6e\xf3\xae\x32\x70\x3f\x73\x1c\x9f\x33\x9a\xb2\x0b\x4c\x76\x1e\xd5\xe0\x3a\x30\x9e\x8a\x02\x56\xab\x79\x00\x0a\xb7\xa8\x50\x6e\xec\x73\xc3\xc3\xae\x28\x3c\x88\x23\x08\x58\xc8\x2c\xcf\xa7\x89\x4f\x03\x46\xec\xf8\x35\x19\x7d\x4f\x61\xd9\xb7\x0c\xbe\x99\xbe\xd4\xe2\x88\x43\x21\xea\xbc\x1c\x2b\xad\x77\xd9\x2b\xbe\x6b\x78\xd1\x8d\x7c\xee\x4f\x3a\x8d\x2a\x2b\x72\x93\x0f\x77\x36\x0a\xad\x94\x22\xcb\x0d\x18\x51\xa3\x36\x79\xdd\xc2\x41\x98\xdd\xa9\x84\x63\x23\xb1\xbf\xb6\xe4\xf3\x05\xe5\x6b\x78\x62\x6b\x70\x27\xe6\xc8\x97\x54\xcf\xf1\x6c\x32\xf3\x28\x61\x3c\x85\x79\x94\xc6\x3f\x83\xb1\x98\x5f\x47\xc9\xd9\x12\x39\x79\x21\x9f\x26\xc8\xa0\x9e\x8c\xaa\xc9\x44\xae\x67\x71\x89\x0d\xce\x4f\xe1\x46\x96\x6c\x0e\xae\x07\x8f\x3c\x5e\x8c\x5f\x62\x3d\xd0\xc9\x8e\x94\xaa\xe9\xda\x6f\x0b\x72\xe9\xea\xfa\xa6\xdc\x5d\xb0\xec\x06\x28\xd4\x3b\xbb\x00\x01\x8f\x97\x96\x17\x25\x29\xa7\x36\xaa\xb1\x93\x4d\xe2\xc9\xb6\x56\xf0\xec\x0a\xe1\x56\x41\x7f\xd2\x5a\x85\x7b\xd1\x74\x13\x25\xff\xc0\x7e\x00\x09\x9e\x91\x9f\x56\x03\x00\x00")
+
+func dbMigrations0006_create_template_versionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0006_create_template_versionsUpSql,
+		"db/migrations/0006_create_template_versions.up.sql",
+	)
+}
+
+func dbMigrations0006_create_template_versionsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0006_create_template_versionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0006_create_template_versions.up.sql", size: 854, mode: os.FileMode(420), modTime: time.Unix(1792385866, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0007_add_template_optionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x8d\x2f\x4b\x2d\x2a\xce\xcc\xcf\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x28\xcb\xcf\x29\xcd\x4d\x2d\xb6\xe6\x72\x24\x5a\x4b\x49\x62\x3a\x49\xea\x93\x12\x93\xb3\x4b\x0b\x48\xd2\x92\x9b\x9f\x97\x59\x92\x5f\x94\x99\x97\x4e\x8a\xae\xcc\x82\x32\x33\x52\xd4\x17\x14\x65\x96\x81\x24\xf3\x52\x4b\xca\xf3\x8b\xb2\xc1\xb6\x61\xd5\x4e\x42\x90\x11\x1b\x54\x24\x04\x11\x69\x41\x43\x6c\x90\x10\x0c\x0a\x00\xb6\x3e\x52\x4b\x44\x02\x00\x00")
+
+func dbMigrations0007_add_template_optionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0007_add_template_optionsDownSql,
+		"db/migrations/0007_add_template_options.down.sql",
+	)
+}
+
+func dbMigrations0007_add_template_optionsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0007_add_template_optionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0007_add_template_options.down.sql", size: 580, mode: os.FileMode(420), modTime: time.Unix(1792386309, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0007_add_template_optionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb5\xd1\xbd\x0a\xc2\x30\x14\x86\xe1\xdd\xab\x38\x9b\x77\xe0\xe2\x14\x6d\xb7\xaa\x20\x75\x12\x29\xa9\x9e\x96\xd8\xf4\x9c\x90\x3f\x6f\x5f\x77\x03\x2d\x8d\xae\xdf\xf0\xf0\xc1\x2b\xaa\xba\x3c\x43\x2d\x76\x55\x09\x1e\x47\xa3\xa5\x47\x07\xa2\x28\x60\x7f\xaa\x2e\x87\x23\x18\xab\xe2\x67\x6b\x08\xfd\x8b\xed\xa0\xa8\x87\x96\x59\xa3\x24\x20\xf6\x40\x41\x6b\x78\x60\x27\x83\xf6\xd0\x49\xed\x70\xbb\x12\x53\xa6\x32\x71\x93\xaf\x8c\x4c\xca\xb3\xfd\xc9\xa3\x56\xde\x87\x60\x5c\x3e\xe4\x65\xef\xe0\xe9\x98\xda\x6f\x63\x7d\xbd\xad\x67\x10\x91\x75\x18\x71\x42\x49\x32\x4d\x44\xeb\x14\xd3\x3f\x02\x26\xed\xc5\x21\x93\x5a\x66\xd0\xa4\x99\x13\x36\x09\x2e\x0b\x9c\xa4\xe6\x84\x7e\x03\x9a\xbe\xd8\x1e\xa0\x03\x00\x00")
+
+func dbMigrations0007_add_template_optionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0007_add_template_optionsUpSql,
+		"db/migrations/0007_add_template_options.up.sql",
+	)
+}
+
+func dbMigrations0007_add_template_optionsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0007_add_template_optionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0007_add_template_options.up.sql", size: 928, mode: os.FileMode(420), modTime: time.Unix(1792386309, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0008_add_group_varsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4b\x2c\x2a\xb6\x06\x00\x49\x02\x28\x69\x24\x00\x00\x00")
+
+func dbMigrations0008_add_group_varsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0008_add_group_varsDownSql,
+		"db/migrations/0008_add_group_vars.down.sql",
+	)
+}
+
+func dbMigrations0008_add_group_varsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0008_add_group_varsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0008_add_group_vars.down.sql", size: 36, mode: os.FileMode(420), modTime: time.Unix(1792386521, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0008_add_group_varsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4b\x2c\x2a\x56\xc8\x2a\xce\xcf\x4b\x52\xc8\xcb\x2f\x51\xc8\x2b\xcd\xc9\x51\x48\x49\x4d\x4b\x2c\xcd\x29\x51\x50\xaf\xae\x55\xb7\x06\x00\xbe\x98\x09\x97\x3f\x00\x00\x00")
+
+func dbMigrations0008_add_group_varsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0008_add_group_varsUpSql,
+		"db/migrations/0008_add_group_vars.up.sql",
+	)
+}
+
+func dbMigrations0008_add_group_varsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0008_add_group_varsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0008_add_group_vars.up.sql", size: 63, mode: os.FileMode(420), modTime: time.Unix(1792386521, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0009_add_template_agentDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x8d\x2f\x4b\x2d\x2a\xce\xcc\xcf\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x4c\x4f\xcd\x2b\xb1\xe6\x72\xc4\xa2\x01\x9b\x42\x00\x38\x9e\xff\x3a\x59\x00\x00\x00")
+
+func dbMigrations0009_add_template_agentDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0009_add_template_agentDownSql,
+		"db/migrations/0009_add_template_agent.down.sql",
+	)
+}
+
+func dbMigrations0009_add_template_agentDownSql() (*asset, error) {
+	bytes, err := dbMigrations0009_add_template_agentDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0009_add_template_agent.down.sql", size: 89, mode: os.FileMode(420), modTime: time.Unix(1792386635, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0009_add_template_agentUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x2d\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x4c\x4f\xcd\x2b\x51\xc8\x2a\xce\xcf\x4b\x52\xc8\xcb\x2f\x51\xc8\x2b\xcd\xc9\x51\x48\x49\x4d\x4b\x2c\xcd\x29\x51\x50\xaf\xae\x55\xb7\xe6\x72\xc4\x62\x46\x7c\x59\x6a\x51\x71\x66\x7e\x1e\x89\x66\x01\x00\xd8\x21\x21\x22\x8f\x00\x00\x00")
+
+func dbMigrations0009_add_template_agentUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0009_add_template_agentUpSql,
+		"db/migrations/0009_add_template_agent.up.sql",
+	)
+}
+
+func dbMigrations0009_add_template_agentUpSql() (*asset, error) {
+	bytes, err := dbMigrations0009_add_template_agentUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0009_add_template_agent.up.sql", size: 143, mode: os.FileMode(420), modTime: time.Unix(1792386635, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0010_add_group_user_dataDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x2d\x4e\x2d\x8a\x4f\x49\x2c\x49\xb4\x06\x00\x83\x5f\xf9\xe0\x29\x00\x00\x00")
+
+func dbMigrations0010_add_group_user_dataDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0010_add_group_user_dataDownSql,
+		"db/migrations/0010_add_group_user_data.down.sql",
+	)
+}
+
+func dbMigrations0010_add_group_user_dataDownSql() (*asset, error) {
+	bytes, err := dbMigrations0010_add_group_user_dataDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0010_add_group_user_data.down.sql", size: 41, mode: os.FileMode(420), modTime: time.Unix(1792386753, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0010_add_group_user_dataUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x2d\x4e\x2d\x8a\x4f\x49\x2c\x49\x54\x28\x49\xad\x28\x51\xc8\xcb\x07\xe2\xd2\x9c\x1c\x85\x94\xd4\xb4\xc4\xd2\x9c\x12\x05\x75\x75\x6b\x00\x33\x7d\x50\x11\x41\x00\x00\x00")
+
+func dbMigrations0010_add_group_user_dataUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0010_add_group_user_dataUpSql,
+		"db/migrations/0010_add_group_user_data.up.sql",
+	)
+}
+
+func dbMigrations0010_add_group_user_dataUpSql() (*asset, error) {
+	bytes, err := dbMigrations0010_add_group_user_dataUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0010_add_group_user_data.up.sql", size: 65, mode: os.FileMode(420), modTime: time.Unix(1792386753, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0011_add_group_regionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4a\x4d\xcf\xcc\xcf\x2b\xb6\x06\x00\x60\x2e\xf8\x3d\x27\x00\x00\x00")
+
+func dbMigrations0011_add_group_regionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0011_add_group_regionsDownSql,
+		"db/migrations/0011_add_group_regions.down.sql",
+	)
+}
+
+func dbMigrations0011_add_group_regionsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0011_add_group_regionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0011_add_group_regions.down.sql", size: 39, mode: os.FileMode(420), modTime: time.Unix(1792387200, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0011_add_group_regionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4a\x4d\xcf\xcc\xcf\x2b\x56\xc8\x2a\xce\xcf\x4b\x52\xc8\xcb\x2f\x51\xc8\x2b\xcd\xc9\x51\x48\x49\x4d\x4b\x2c\xcd\x29\x51\x50\x8f\x8e\x55\xb7\x06\x00\xd0\xee\x52\x28\x42\x00\x00\x00")
+
+func dbMigrations0011_add_group_regionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0011_add_group_regionsUpSql,
+		"db/migrations/0011_add_group_regions.up.sql",
+	)
+}
+
+func dbMigrations0011_add_group_regionsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0011_add_group_regionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0011_add_group_regions.up.sql", size: 66, mode: os.FileMode(420), modTime: time.Unix(1792387200, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0012_add_template_fallback_sizesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x8d\x2f\x4b\x2d\x2a\xce\xcc\xcf\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x4b\xcc\xc9\x49\x4a\x4c\xce\x8e\x2f\xce\xac\x4a\x2d\xb6\xe6\x72\xc4\xa2\x13\xaf\x0e\x00\xef\xfc\x4e\x42\x6b\x00\x00\x00")
+
+func dbMigrations0012_add_template_fallback_sizesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0012_add_template_fallback_sizesDownSql,
+		"db/migrations/0012_add_template_fallback_sizes.down.sql",
+	)
+}
+
+func dbMigrations0012_add_template_fallback_sizesDownSql() (*asset, error) {
+	bytes, err := dbMigrations0012_add_template_fallback_sizesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0012_add_template_fallback_sizes.down.sql", size: 107, mode: os.FileMode(420), modTime: time.Unix(1792387297, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0012_add_template_fallback_sizesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x2d\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x4b\xcc\xc9\x49\x4a\x4c\xce\x8e\x2f\xce\xac\x02\xca\x64\x15\xe7\xe7\x25\x29\xe4\xe5\x97\x28\xe4\x95\xe6\xe4\x28\xa4\xa4\xa6\x25\x96\xe6\x94\x28\xa8\x47\xc7\xaa\x5b\x73\x39\x62\x31\x2c\xbe\x2c\xb5\xa8\x38\x33\x3f\x8f\x5c\x43\x01\x05\x3b\x30\x6c\xa1\x00\x00\x00")
+
+func dbMigrations0012_add_template_fallback_sizesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0012_add_template_fallback_sizesUpSql,
+		"db/migrations/0012_add_template_fallback_sizes.up.sql",
+	)
+}
+
+func dbMigrations0012_add_template_fallback_sizesUpSql() (*asset, error) {
+	bytes, err := dbMigrations0012_add_template_fallback_sizesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0012_add_template_fallback_sizes.up.sql", size: 161, mode: os.FileMode(420), modTime: time.Unix(1792387297, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0013_add_group_load_balancerDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xc8\xc9\x4f\x4c\x89\x4f\x4a\xcc\x49\xcc\x4b\x4e\x2d\x8a\xcf\x4c\xb1\x06\x00\xd4\x4e\xfa\xaa\x30\x00\x00\x00")
+
+func dbMigrations0013_add_group_load_balancerDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0013_add_group_load_balancerDownSql,
+		"db/migrations/0013_add_group_load_balancer.down.sql",
+	)
+}
+
+func dbMigrations0013_add_group_load_balancerDownSql() (*asset, error) {
+	bytes, err := dbMigrations0013_add_group_load_balancerDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0013_add_group_load_balancer.down.sql", size: 48, mode: os.FileMode(420), modTime: time.Unix(1792387446, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0013_add_group_load_balancerUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\xc8\xc9\x4f\x4c\x89\x4f\x4a\xcc\x49\xcc\x4b\x4e\x2d\x8a\xcf\x4c\x51\x28\x49\xad\x28\x51\xc8\xcb\x07\xe2\xd2\x9c\x1c\x85\x94\xd4\xb4\xc4\xd2\x9c\x12\x05\x75\x75\x6b\x00\x2c\xd6\xdd\x26\x48\x00\x00\x00")
+
+func dbMigrations0013_add_group_load_balancerUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0013_add_group_load_balancerUpSql,
+		"db/migrations/0013_add_group_load_balancer.up.sql",
+	)
+}
+
+func dbMigrations0013_add_group_load_balancerUpSql() (*asset, error) {
+	bytes, err := dbMigrations0013_add_group_load_balancerUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0013_add_group_load_balancer.up.sql", size: 72, mode: os.FileMode(420), modTime: time.Unix(1792387446, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0014_add_group_floating_ipDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x48\xcb\xc9\x4f\x2c\xc9\xcc\x4b\x8f\xcf\x2c\xb0\x06\x00\x4e\x31\x61\x9c\x2b\x00\x00\x00")
+
+func dbMigrations0014_add_group_floating_ipDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0014_add_group_floating_ipDownSql,
+		"db/migrations/0014_add_group_floating_ip.down.sql",
+	)
+}
+
+func dbMigrations0014_add_group_floating_ipDownSql() (*asset, error) {
+	bytes, err := dbMigrations0014_add_group_floating_ipDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0014_add_group_floating_ip.down.sql", size: 43, mode: os.FileMode(420), modTime: time.Unix(1792387861, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0014_add_group_floating_ipUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\xcb\xc9\x4f\x2c\xc9\xcc\x4b\x8f\xcf\x2c\x50\x28\x49\xad\x28\x51\xc8\xcb\x07\xe2\xd2\x9c\x1c\x85\x94\xd4\xb4\xc4\xd2\x9c\x12\x05\x75\x75\x6b\x00\x67\xc8\xfd\x38\x43\x00\x00\x00")
+
+func dbMigrations0014_add_group_floating_ipUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0014_add_group_floating_ipUpSql,
+		"db/migrations/0014_add_group_floating_ip.up.sql",
+	)
+}
+
+func dbMigrations0014_add_group_floating_ipUpSql() (*asset, error) {
+	bytes, err := dbMigrations0014_add_group_floating_ipUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0014_add_group_floating_ip.up.sql", size: 67, mode: os.FileMode(420), modTime: time.Unix(1792387861, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0015_add_group_dnsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x48\xc9\x2b\xb6\x06\x00\x44\xe7\xfa\xb1\x23\x00\x00\x00")
+
+func dbMigrations0015_add_group_dnsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0015_add_group_dnsDownSql,
+		"db/migrations/0015_add_group_dns.down.sql",
+	)
+}
+
+func dbMigrations0015_add_group_dnsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0015_add_group_dnsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0015_add_group_dns.down.sql", size: 35, mode: os.FileMode(420), modTime: time.Unix(1792388134, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0015_add_group_dnsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x28\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\xc9\x2b\x56\xc8\x2a\xce\xcf\x4b\x52\xc8\xcb\x2f\x51\xc8\x2b\xcd\xc9\x51\x48\x49\x4d\x4b\x2c\xcd\x29\x51\x50\xaf\xae\x55\xb7\x06\x00\xa3\xf4\x7d\x70\x3e\x00\x00\x00")
+
+func dbMigrations0015_add_group_dnsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0015_add_group_dnsUpSql,
+		"db/migrations/0015_add_group_dns.up.sql",
+	)
+}
+
+func dbMigrations0015_add_group_dnsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0015_add_group_dnsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0015_add_group_dns.up.sql", size: 62, mode: os.FileMode(420), modTime: time.Unix(1792388134, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0016_add_notification_sinksDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x48\x49\x4d\x4c\x89\xcf\x49\x2d\x29\x49\x2d\x2a\xb6\xe6\x72\xf4\x09\x71\x0d\x82\xca\xa4\x17\xe5\x97\x16\x14\x2b\xb8\x80\xd4\x3a\xfb\xfb\x84\xfa\xfa\x29\xe4\xe5\x97\x64\xa6\x65\x26\x27\x96\x64\xe6\xe7\xc5\x17\x67\xe6\x65\x17\x5b\x03\x00\x55\x18\x64\x5a\x4b\x00\x00\x00")
+
+func dbMigrations0016_add_notification_sinksDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0016_add_notification_sinksDownSql,
+		"db/migrations/0016_add_notification_sinks.down.sql",
+	)
+}
+
+func dbMigrations0016_add_notification_sinksDownSql() (*asset, error) {
+	bytes, err := dbMigrations0016_add_notification_sinksDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0016_add_notification_sinks.down.sql", size: 75, mode: os.FileMode(420), modTime: time.Unix(1792388302, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0016_add_notification_sinksUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x55\x8f\x41\x4e\xc3\x30\x10\x45\xf7\x39\xc5\xdf\x35\x91\xb8\x41\x57\xa1\x31\x52\x44\x5a\x50\x94\x48\x54\x08\x59\xa6\x9e\x06\x43\x62\x47\xf6\x44\x14\x4e\x4f\x5c\x50\x43\x97\xf3\x9e\xe6\xcf\x9f\xbc\x6a\x44\x8d\x26\xbf\xad\x04\x3a\xef\xa6\x31\x20\x2f\x0a\x6c\x1e\xaa\x76\xbb\x83\x75\x6c\x8e\xe6\xa0\xd8\x38\x2b\x83\xb1\x1f\x01\xef\xc1\xd9\xd7\x28\x60\xa7\xbe\x87\xa6\xa3\x9a\x7a\xc6\xea\xf9\x65\xb5\x4e\x92\x4d\x2d\xf2\x46\xfc\xe5\x69\x52\x5a\xf6\xc4\x4c\x3e\x20\x4d\x00\xa3\xd1\xb6\x65\x81\xc7\xba\xdc\xe6\xf5\x1e\xf7\x62\x8f\x42\xdc\xe5\x6d\xd5\xa0\x23\x2b\xbd\xb2\xda\x0d\x72\x9a\x8c\x4e\xb3\x9b\x79\x21\x9e\x04\xd3\x89\x2f\x07\x23\x3d\xf7\x94\x73\x58\x34\x11\x5c\xd5\xfc\xc7\x47\xf5\xd5\x3b\xa5\x7f\x3b\x47\x40\xde\x3b\x7f\xd1\x6a\x6e\x36\x8c\x1c\x60\x2c\x53\x47\x3e\xb2\x83\x27\xc5\xa4\xa5\x62\xb0\x19\x28\xb0\x1a\x46\x7c\x1a\x7e\x3b\x8f\xf8\x76\x96\x92\x6c\x79\xb4\xdc\x15\xe2\xe9\xea\x51\xb9\x24\xcc\x55\x4e\x70\xf6\x4a\xa7\x8b\xce\xd6\x3f\xa9\x96\xae\xaa\x7d\x01\x00\x00")
+
+func dbMigrations0016_add_notification_sinksUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0016_add_notification_sinksUpSql,
+		"db/migrations/0016_add_notification_sinks.up.sql",
+	)
+}
+
+func dbMigrations0016_add_notification_sinksUpSql() (*asset, error) {
+	bytes, err := dbMigrations0016_add_notification_sinksUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0016_add_notification_sinks.up.sql", size: 381, mode: os.FileMode(420), modTime: time.Unix(1792388302, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0017_add_group_status_decisionDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x88\x2f\x2e\x49\x2c\x29\x2d\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x49\x4d\xce\x2c\xce\xcc\xcf\xb3\x06\x00\x18\xec\x61\xe3\x2e\x00\x00\x00")
+
+func dbMigrations0017_add_group_status_decisionDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0017_add_group_status_decisionDownSql,
+		"db/migrations/0017_add_group_status_decision.down.sql",
+	)
+}
+
+func dbMigrations0017_add_group_status_decisionDownSql() (*asset, error) {
+	bytes, err := dbMigrations0017_add_group_status_decisionDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0017_add_group_status_decision.down.sql", size: 46, mode: os.FileMode(420), modTime: time.Unix(1792388794, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0017_add_group_status_decisionUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2f\xca\x2f\x2d\x88\x2f\x2e\x49\x2c\x29\x2d\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x49\x4d\xce\x2c\xce\xcc\xcf\x53\xc8\x2a\xce\xcf\x4b\xb2\x06\x00\x84\x13\x90\xe7\x33\x00\x00\x00")
+
+func dbMigrations0017_add_group_status_decisionUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0017_add_group_status_decisionUpSql,
+		"db/migrations/0017_add_group_status_decision.up.sql",
+	)
+}
+
+func dbMigrations0017_add_group_status_decisionUpSql() (*asset, error) {
+	bytes, err := dbMigrations0017_add_group_status_decisionUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0017_add_group_status_decision.up.sql", size: 51, mode: os.FileMode(420), modTime: time.Unix(1792388794, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0018_create_eventsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\x48\x2d\x4b\xcd\x2b\x29\x8e\x4f\x2e\x4a\x4d\x2c\x49\x4d\x89\x4f\x2c\x89\xcf\x4c\xa9\xb0\xe6\x72\xc1\x50\x91\x5e\x94\x5f\x5a\x00\x94\x44\x92\x0f\x71\x74\xf2\x71\x85\xca\x5b\x03\x00\x6e\xeb\x31\x64\x54\x00\x00\x00")
+
+func dbMigrations0018_create_eventsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0018_create_eventsDownSql,
+		"db/migrations/0018_create_events.down.sql",
+	)
+}
+
+func dbMigrations0018_create_eventsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0018_create_eventsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0018_create_events.down.sql", size: 84, mode: os.FileMode(420), modTime: time.Unix(1792388922, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0018_create_eventsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x50\xd1\x6a\x83\x40\x10\x7c\xf7\x2b\xf6\x31\x81\xfc\x41\x9e\x6c\x7a\x0f\x52\x6b\x5b\x51\x68\x9e\x64\xd5\xc5\x6e\xd1\xbb\xf4\xee\x4c\x42\xbf\xbe\x7b\x9a\xc4\x10\x0a\xc7\xc1\xcc\xee\xcc\xb0\xb3\xcb\x55\x5c\x28\x28\xe2\xa7\x54\x01\x1d\x49\x7b\x07\xab\x08\xc0\xd1\x0f\xd4\xdc\x39\xb2\x8c\x3d\xbc\xe7\xc9\x6b\x9c\xef\xe1\x45\xed\x37\x32\xe4\x16\xc6\x51\xbe\x32\x4b\x3e\x4a\x05\xd9\x5b\x01\x59\x99\xa6\x61\xd4\x59\x33\x1e\xaa\xcb\x42\x20\x34\x0e\x04\x9e\xce\x3e\x80\x29\xe0\x86\xb0\xf1\x6c\xf4\x0d\xb6\xd4\x7b\x04\xd6\x9e\x3a\xb2\x81\x68\xcc\x28\xdb\x77\xc4\x40\xce\x61\xb7\xd8\xb1\xab\xc8\x5a\x63\xa1\x36\xa6\x27\xd4\xb3\x4b\xc3\x2e\xd8\x7e\x3b\xa3\xeb\xc9\xc6\x12\x7a\x6a\x2b\x94\x64\x16\x0b\x8f\xc3\x01\x4e\xec\xbf\x26\x08\xbf\x46\x53\xb4\xde\x46\xd1\x6e\xae\x22\xc9\x9e\xd5\xe7\xa5\x8a\xea\x7a\x8e\xbc\x33\x88\xe9\x4c\xaf\xae\xf4\x46\x6a\x12\xe9\x7f\xca\x25\xf5\x41\xbb\x0c\xd6\xdb\x3f\xdf\x72\x38\x18\x7e\x01\x00\x00")
+
+func dbMigrations0018_create_eventsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0018_create_eventsUpSql,
+		"db/migrations/0018_create_events.up.sql",
+	)
+}
+
+func dbMigrations0018_create_eventsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0018_create_eventsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0018_create_events.up.sql", size: 382, mode: os.FileMode(420), modTime: time.Unix(1792388922, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0019_add_activity_kindsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2d\x4b\xcd\x2b\x29\x56\x08\x72\xf5\x73\xf4\x75\x55\x70\xf6\xf7\x09\xf5\xf5\x53\xc8\xce\xcc\x4b\x51\x08\xf1\x57\x48\x4c\x2e\xc9\xcc\xcf\xb3\xe6\x72\x44\xd2\x90\x5e\x94\x5f\x5a\x10\x5f\x5c\x92\x58\x52\x5a\xac\xe0\x12\xe4\x1f\x00\xd3\x94\x5a\x54\x94\x5f\x44\xa4\x5a\x90\x05\xd6\x00\x31\x85\x43\x5a\x87\x00\x00\x00")
+
+func dbMigrations0019_add_activity_kindsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0019_add_activity_kindsDownSql,
+		"db/migrations/0019_add_activity_kinds.down.sql",
+	)
+}
+
+func dbMigrations0019_add_activity_kindsDownSql() (*asset, error) {
+	bytes, err := dbMigrations0019_add_activity_kindsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0019_add_activity_kinds.down.sql", size: 135, mode: os.FileMode(420), modTime: time.Unix(1792389129, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0019_add_activity_kindsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\xce\xb1\x0a\xc2\x30\x14\x46\xe1\xdd\xa7\xf8\xb7\x3e\x44\xa7\xab\xcd\x96\xb4\x50\xe2\x2c\xa1\xb9\x4a\x30\x24\x92\xdc\x88\x8f\xdf\x52\xe8\x22\x08\x0e\x67\xfd\x38\xa4\xad\x9a\x61\xe9\xac\x15\x1e\x25\xb7\xd7\xad\x8a\x93\x56\x41\xc3\x80\xcb\xa4\xaf\x66\xc4\x33\x24\x0f\xe1\x8f\x20\xe5\xad\x16\x23\x3c\xdf\x5d\x8b\x82\xae\x2e\x2e\xb2\xef\xfa\x13\xfd\x01\x71\x29\xb9\xfc\x92\xbe\x0c\x7e\x73\x92\x8a\x59\x8d\x64\xd4\x01\xb8\x45\x42\x4e\xb0\xd3\xfe\xd4\xaf\xcb\xb3\xd7\x4c\xbd\x00\x00\x00")
+
+func dbMigrations0019_add_activity_kindsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0019_add_activity_kindsUpSql,
+		"db/migrations/0019_add_activity_kinds.up.sql",
+	)
+}
+
+func dbMigrations0019_add_activity_kindsUpSql() (*asset, error) {
+	bytes, err := dbMigrations0019_add_activity_kindsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0019_add_activity_kinds.up.sql", size: 189, mode: os.FileMode(420), modTime: time.Unix(1792389129, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0020_add_operation_health_passesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x2f\x48\x2d\x4a\x2c\xc9\xcc\xcf\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xc8\x48\x4d\xcc\x29\xc9\x88\x2f\x48\x2c\x2e\x4e\x2d\xb6\x06\x00\xfc\x76\xce\x04\x31\x00\x00\x00")
+
+func dbMigrations0020_add_operation_health_passesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0020_add_operation_health_passesDownSql,
+		"db/migrations/0020_add_operation_health_passes.down.sql",
+	)
+}
+
+func dbMigrations0020_add_operation_health_passesDownSql() (*asset, error) {
+	bytes, err := dbMigrations0020_add_operation_health_passesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0020_add_operation_health_passes.down.sql", size: 49, mode: os.FileMode(420), modTime: time.Unix(1792390354, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _dbMigrations0020_add_operation_health_passesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x2f\x48\x2d\x4a\x2c\xc9\xcc\xcf\x2b\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\xc8\x48\x4d\xcc\x29\xc9\x88\x2f\x48\x2c\x2e\x4e\x2d\x56\xc8\x2a\xce\xcf\x4b\x52\xc8\xcb\x2f\x51\xc8\x2b\xcd\xc9\x51\x48\x49\x4d\x4b\x2c\xcd\x29\x51\x50\xaf\xae\x55\xb7\x06\x00\x4e\xd2\x86\x98\x4c\x00\x00\x00")
+
+func dbMigrations0020_add_operation_health_passesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_dbMigrations0020_add_operation_health_passesUpSql,
+		"db/migrations/0020_add_operation_health_passes.up.sql",
+	)
+}
+
+func dbMigrations0020_add_operation_health_passesUpSql() (*asset, error) {
+	bytes, err := dbMigrations0020_add_operation_health_passesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "db/migrations/0020_add_operation_health_passes.up.sql", size: 76, mode: os.FileMode(420), modTime: time.Unix(1792390356, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -603,6 +1317,40 @@ var _bindata = map[string]func() (*asset, error){
 	"db/migrations/0002_create_groups.up.sql": dbMigrations0002_create_groupsUpSql,
 	"db/migrations/0003_create_group_status.down.sql": dbMigrations0003_create_group_statusDownSql,
 	"db/migrations/0003_create_group_status.up.sql": dbMigrations0003_create_group_statusUpSql,
+	"db/migrations/0004_create_operations.down.sql": dbMigrations0004_create_operationsDownSql,
+	"db/migrations/0004_create_operations.up.sql": dbMigrations0004_create_operationsUpSql,
+	"db/migrations/0005_create_refreshes.down.sql": dbMigrations0005_create_refreshesDownSql,
+	"db/migrations/0005_create_refreshes.up.sql": dbMigrations0005_create_refreshesUpSql,
+	"db/migrations/0006_create_template_versions.down.sql": dbMigrations0006_create_template_versionsDownSql,
+	"db/migrations/0006_create_template_versions.up.sql": dbMigrations0006_create_template_versionsUpSql,
+	"db/migrations/0007_add_template_options.down.sql": dbMigrations0007_add_template_optionsDownSql,
+	"db/migrations/0007_add_template_options.up.sql": dbMigrations0007_add_template_optionsUpSql,
+	"db/migrations/0008_add_group_vars.down.sql": dbMigrations0008_add_group_varsDownSql,
+	"db/migrations/0008_add_group_vars.up.sql": dbMigrations0008_add_group_varsUpSql,
+	"db/migrations/0009_add_template_agent.down.sql": dbMigrations0009_add_template_agentDownSql,
+	"db/migrations/0009_add_template_agent.up.sql": dbMigrations0009_add_template_agentUpSql,
+	"db/migrations/0010_add_group_user_data.down.sql": dbMigrations0010_add_group_user_dataDownSql,
+	"db/migrations/0010_add_group_user_data.up.sql": dbMigrations0010_add_group_user_dataUpSql,
+	"db/migrations/0011_add_group_regions.down.sql": dbMigrations0011_add_group_regionsDownSql,
+	"db/migrations/0011_add_group_regions.up.sql": dbMigrations0011_add_group_regionsUpSql,
+	"db/migrations/0012_add_template_fallback_sizes.down.sql": dbMigrations0012_add_template_fallback_sizesDownSql,
+	"db/migrations/0012_add_template_fallback_sizes.up.sql": dbMigrations0012_add_template_fallback_sizesUpSql,
+	"db/migrations/0013_add_group_load_balancer.down.sql": dbMigrations0013_add_group_load_balancerDownSql,
+	"db/migrations/0013_add_group_load_balancer.up.sql": dbMigrations0013_add_group_load_balancerUpSql,
+	"db/migrations/0014_add_group_floating_ip.down.sql": dbMigrations0014_add_group_floating_ipDownSql,
+	"db/migrations/0014_add_group_floating_ip.up.sql": dbMigrations0014_add_group_floating_ipUpSql,
+	"db/migrations/0015_add_group_dns.down.sql": dbMigrations0015_add_group_dnsDownSql,
+	"db/migrations/0015_add_group_dns.up.sql": dbMigrations0015_add_group_dnsUpSql,
+	"db/migrations/0016_add_notification_sinks.down.sql": dbMigrations0016_add_notification_sinksDownSql,
+	"db/migrations/0016_add_notification_sinks.up.sql": dbMigrations0016_add_notification_sinksUpSql,
+	"db/migrations/0017_add_group_status_decision.down.sql": dbMigrations0017_add_group_status_decisionDownSql,
+	"db/migrations/0017_add_group_status_decision.up.sql": dbMigrations0017_add_group_status_decisionUpSql,
+	"db/migrations/0018_create_events.down.sql": dbMigrations0018_create_eventsDownSql,
+	"db/migrations/0018_create_events.up.sql": dbMigrations0018_create_eventsUpSql,
+	"db/migrations/0019_add_activity_kinds.down.sql": dbMigrations0019_add_activity_kindsDownSql,
+	"db/migrations/0019_add_activity_kinds.up.sql": dbMigrations0019_add_activity_kindsUpSql,
+	"db/migrations/0020_add_operation_health_passes.down.sql": dbMigrations0020_add_operation_health_passesDownSql,
+	"db/migrations/0020_add_operation_health_passes.up.sql": dbMigrations0020_add_operation_health_passesUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -653,6 +1401,40 @@ var _bintree = &bintree{nil, map[string]*bintree{
 			"0002_create_groups.up.sql": &bintree{dbMigrations0002_create_groupsUpSql, map[string]*bintree{}},
 			"0003_create_group_status.down.sql": &bintree{dbMigrations0003_create_group_statusDownSql, map[string]*bintree{}},
 			"0003_create_group_status.up.sql": &bintree{dbMigrations0003_create_group_statusUpSql, map[string]*bintree{}},
+			"0004_create_operations.down.sql": &bintree{dbMigrations0004_create_operationsDownSql, map[string]*bintree{}},
+			"0004_create_operations.up.sql": &bintree{dbMigrations0004_create_operationsUpSql, map[string]*bintree{}},
+			"0005_create_refreshes.down.sql": &bintree{dbMigrations0005_create_refreshesDownSql, map[string]*bintree{}},
+			"0005_create_refreshes.up.sql": &bintree{dbMigrations0005_create_refreshesUpSql, map[string]*bintree{}},
+			"0006_create_template_versions.down.sql": &bintree{dbMigrations0006_create_template_versionsDownSql, map[string]*bintree{}},
+			"0006_create_template_versions.up.sql": &bintree{dbMigrations0006_create_template_versionsUpSql, map[string]*bintree{}},
+			"0007_add_template_options.down.sql": &bintree{dbMigrations0007_add_template_optionsDownSql, map[string]*bintree{}},
+			"0007_add_template_options.up.sql": &bintree{dbMigrations0007_add_template_optionsUpSql, map[string]*bintree{}},
+			"0008_add_group_vars.down.sql": &bintree{dbMigrations0008_add_group_varsDownSql, map[string]*bintree{}},
+			"0008_add_group_vars.up.sql": &bintree{dbMigrations0008_add_group_varsUpSql, map[string]*bintree{}},
+			"0009_add_template_agent.down.sql": &bintree{dbMigrations0009_add_template_agentDownSql, map[string]*bintree{}},
+			"0009_add_template_agent.up.sql": &bintree{dbMigrations0009_add_template_agentUpSql, map[string]*bintree{}},
+			"0010_add_group_user_data.down.sql": &bintree{dbMigrations0010_add_group_user_dataDownSql, map[string]*bintree{}},
+			"0010_add_group_user_data.up.sql": &bintree{dbMigrations0010_add_group_user_dataUpSql, map[string]*bintree{}},
+			"0011_add_group_regions.down.sql": &bintree{dbMigrations0011_add_group_regionsDownSql, map[string]*bintree{}},
+			"0011_add_group_regions.up.sql": &bintree{dbMigrations0011_add_group_regionsUpSql, map[string]*bintree{}},
+			"0012_add_template_fallback_sizes.down.sql": &bintree{dbMigrations0012_add_template_fallback_sizesDownSql, map[string]*bintree{}},
+			"0012_add_template_fallback_sizes.up.sql": &bintree{dbMigrations0012_add_template_fallback_sizesUpSql, map[string]*bintree{}},
+			"0013_add_group_load_balancer.down.sql": &bintree{dbMigrations0013_add_group_load_balancerDownSql, map[string]*bintree{}},
+			"0013_add_group_load_balancer.up.sql": &bintree{dbMigrations0013_add_group_load_balancerUpSql, map[string]*bintree{}},
+			"0014_add_group_floating_ip.down.sql": &bintree{dbMigrations0014_add_group_floating_ipDownSql, map[string]*bintree{}},
+			"0014_add_group_floating_ip.up.sql": &bintree{dbMigrations0014_add_group_floating_ipUpSql, map[string]*bintree{}},
+			"0015_add_group_dns.down.sql": &bintree{dbMigrations0015_add_group_dnsDownSql, map[string]*bintree{}},
+			"0015_add_group_dns.up.sql": &bintree{dbMigrations0015_add_group_dnsUpSql, map[string]*bintree{}},
+			"0016_add_notification_sinks.down.sql": &bintree{dbMigrations0016_add_notification_sinksDownSql, map[string]*bintree{}},
+			"0016_add_notification_sinks.up.sql": &bintree{dbMigrations0016_add_notification_sinksUpSql, map[string]*bintree{}},
+			"0017_add_group_status_decision.down.sql": &bintree{dbMigrations0017_add_group_status_decisionDownSql, map[string]*bintree{}},
+			"0017_add_group_status_decision.up.sql": &bintree{dbMigrations0017_add_group_status_decisionUpSql, map[string]*bintree{}},
+			"0018_create_events.down.sql": &bintree{dbMigrations0018_create_eventsDownSql, map[string]*bintree{}},
+			"0018_create_events.up.sql": &bintree{dbMigrations0018_create_eventsUpSql, map[string]*bintree{}},
+			"0019_add_activity_kinds.down.sql": &bintree{dbMigrations0019_add_activity_kindsDownSql, map[string]*bintree{}},
+			"0019_add_activity_kinds.up.sql": &bintree{dbMigrations0019_add_activity_kindsUpSql, map[string]*bintree{}},
+			"0020_add_operation_health_passes.down.sql": &bintree{dbMigrations0020_add_operation_health_passesDownSql, map[string]*bintree{}},
+			"0020_add_operation_health_passes.up.sql": &bintree{dbMigrations0020_add_operation_health_passesUpSql, map[string]*bintree{}},
 		}},
 	}},
 	"static": &bintree{nil, map[string]*bintree{
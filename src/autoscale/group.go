@@ -1,8 +1,10 @@
 package autoscale
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sync"
 
 	"golang.org/x/net/context"
@@ -13,66 +15,100 @@ import (
 // ResourceManagerFactoryFn is a function that returns ResourceManagerFactory.
 type ResourceManagerFactoryFn func(g *Group) (ResourceManager, error)
 
-// UpdateGroupRequest is a group update request.
+// UpdateGroupRequest is a group update request. BaseSize is required, so it is nil if
+// the request didn't set it.
 type UpdateGroupRequest struct {
-	BaseSize int `json:"base_size"`
+	BaseSize *int `json:"base_size"`
 }
 
 // Group is an autoscale group
 type Group struct {
-	ID           string          `json:"id" db:"id"`
-	Name         string          `json:"name" db:"name"`
-	BaseName     string          `json:"baseName" db:"base_name"`
-	TemplateID   string          `json:"templateID" db:"template_id"`
-	MetricType   string          `json:"metricType" db:"metric_type"`
-	Metric       Metrics         `json:"metric"`
-	RawMetric    json.RawMessage `json:"rawMetric,omitempty" db:"metric"`
-	PolicyType   string          `json:"policyType" db:"policy_type"`
-	Policy       Policy          `json:"policy" `
-	RawPolicy    json.RawMessage `json:"rawPolicy,omitempty" db:"policy"`
-	ScaleHistory []GroupStatus   `json:"scaleHistory"`
-	Values       []TimeSeries    `json:"timeseriesValues"`
+	ID                string          `json:"id" db:"id"`
+	Name              string          `json:"name" db:"name"`
+	BaseName          string          `json:"baseName" db:"base_name"`
+	TemplateID        string          `json:"templateID" db:"template_id"`
+	TemplateVersion   int             `json:"templateVersion" db:"template_version"`
+	Vars              GroupVars       `json:"vars" db:"vars"`
+	UserData          string          `json:"userData" db:"user_data"`
+	Regions           GroupRegions    `json:"regions" db:"regions"`
+	LoadBalancerID    string          `json:"loadBalancerID" db:"load_balancer_id"`
+	FloatingIP        string          `json:"floatingIP" db:"floating_ip"`
+	DNS               GroupDNS        `json:"dns" db:"dns"`
+	NotificationSinks SinkConfigs     `json:"notificationSinks" db:"notification_sinks"`
+	MetricType        string          `json:"metricType" db:"metric_type"`
+	Metric            Metrics         `json:"metric"`
+	RawMetric         json.RawMessage `json:"rawMetric,omitempty" db:"metric"`
+	PolicyType        string          `json:"policyType" db:"policy_type"`
+	Policy            Policy          `json:"policy" `
+	RawPolicy         json.RawMessage `json:"rawPolicy,omitempty" db:"policy"`
+	ScaleHistory      []GroupStatus   `json:"scaleHistory"`
+	Values            []TimeSeries    `json:"timeseriesValues"`
 }
 
 var _ json.Marshaler = (*Group)(nil)
 var _ json.Unmarshaler = (*Group)(nil)
 
 type groupToJSON struct {
-	ID           string               `json:"id"`
-	Name         string               `json:"name"`
-	BaseName     string               `json:"baseName"`
-	TemplateID   string               `json:"templateID"`
-	MetricType   string               `json:"metricType"`
-	Metric       json.RawMessage      `json:"metric"`
-	PolicyType   string               `json:"policyType"`
-	Policy       json.RawMessage      `json:"policy"`
-	ScaleHistory []GroupStatus        `json:"scaleHistory,omitempty"`
-	Values       []TimeSeries         `json:"timeseriesValues,omitempty"`
-	Resources    []ResourceAllocation `json:"resources,omitempty"`
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	BaseName          string               `json:"baseName"`
+	TemplateID        string               `json:"templateID"`
+	TemplateVersion   int                  `json:"templateVersion"`
+	Vars              GroupVars            `json:"vars"`
+	UserData          string               `json:"userData"`
+	Regions           GroupRegions         `json:"regions"`
+	RegionCounts      map[string]int       `json:"regionCounts,omitempty"`
+	LoadBalancerID    string               `json:"loadBalancerID"`
+	FloatingIP        string               `json:"floatingIP"`
+	DNS               GroupDNS             `json:"dns"`
+	NotificationSinks SinkConfigs          `json:"notificationSinks"`
+	MetricType        string               `json:"metricType"`
+	Metric            json.RawMessage      `json:"metric"`
+	PolicyType        string               `json:"policyType"`
+	Policy            json.RawMessage      `json:"policy"`
+	ScaleHistory      []GroupStatus        `json:"scaleHistory,omitempty"`
+	Values            []TimeSeries         `json:"timeseriesValues,omitempty"`
+	Resources         []ResourceAllocation `json:"resources,omitempty"`
 }
 
 type jsonToGroup struct {
-	ID         string          `json:"id"`
-	Name       string          `json:"name"`
-	BaseName   string          `json:"baseName"`
-	TemplateID string          `json:"templateID"`
-	MetricType string          `json:"metricType"`
-	Metric     json.RawMessage `json:"metric"`
-	PolicyType string          `json:"policyType"`
-	Policy     json.RawMessage `json:"policy"`
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	BaseName          string          `json:"baseName"`
+	TemplateID        string          `json:"templateID"`
+	TemplateVersion   int             `json:"templateVersion"`
+	Vars              GroupVars       `json:"vars"`
+	UserData          string          `json:"userData"`
+	Regions           GroupRegions    `json:"regions"`
+	LoadBalancerID    string          `json:"loadBalancerID"`
+	FloatingIP        string          `json:"floatingIP"`
+	DNS               GroupDNS        `json:"dns"`
+	NotificationSinks SinkConfigs     `json:"notificationSinks"`
+	MetricType        string          `json:"metricType"`
+	Metric            json.RawMessage `json:"metric"`
+	PolicyType        string          `json:"policyType"`
+	Policy            json.RawMessage `json:"policy"`
 }
 
 // MarshalJSON marshals a Group into json.
 func (g *Group) MarshalJSON() ([]byte, error) {
 	tmp := groupToJSON{
-		ID:           g.ID,
-		Name:         g.Name,
-		BaseName:     g.BaseName,
-		TemplateID:   g.TemplateID,
-		MetricType:   g.MetricType,
-		PolicyType:   g.PolicyType,
-		ScaleHistory: g.ScaleHistory,
-		Values:       g.Values,
+		ID:                g.ID,
+		Name:              g.Name,
+		BaseName:          g.BaseName,
+		TemplateID:        g.TemplateID,
+		TemplateVersion:   g.TemplateVersion,
+		Vars:              g.Vars,
+		UserData:          g.UserData,
+		Regions:           g.Regions,
+		LoadBalancerID:    g.LoadBalancerID,
+		FloatingIP:        g.FloatingIP,
+		DNS:               g.DNS,
+		NotificationSinks: g.NotificationSinks.Redacted(),
+		MetricType:        g.MetricType,
+		PolicyType:        g.PolicyType,
+		ScaleHistory:      g.ScaleHistory,
+		Values:            g.Values,
 	}
 
 	if g.Metric != nil {
@@ -122,6 +158,9 @@ func (g *Group) MarshalJSON() ([]byte, error) {
 	}
 
 	tmp.Resources = resources
+	if len(g.Regions) > 0 {
+		tmp.RegionCounts = regionCounts(resources)
+	}
 
 	return json.Marshal(&tmp)
 }
@@ -138,6 +177,14 @@ func (g *Group) UnmarshalJSON(b []byte) error {
 	g.Name = tmp.Name
 	g.BaseName = tmp.BaseName
 	g.TemplateID = tmp.TemplateID
+	g.TemplateVersion = tmp.TemplateVersion
+	g.Vars = tmp.Vars
+	g.UserData = tmp.UserData
+	g.Regions = tmp.Regions
+	g.LoadBalancerID = tmp.LoadBalancerID
+	g.FloatingIP = tmp.FloatingIP
+	g.DNS = tmp.DNS
+	g.NotificationSinks = tmp.NotificationSinks
 	g.MetricType = tmp.MetricType
 	g.PolicyType = tmp.PolicyType
 	g.RawMetric = tmp.Metric
@@ -178,7 +225,20 @@ func (g *Group) IsValid() bool {
 		return false
 	}
 
-	return true
+	if !validUserData(g.UserData) {
+		return false
+	}
+
+	if _, err := g.RenderUserData(UserDataVars{Vars: g.Vars}); err != nil {
+		return false
+	}
+
+	// the regions must have room for as many droplets as the policy allows.
+	if g.Policy != nil && !g.Regions.holds(g.Policy.Clamp(math.MaxInt32)) {
+		return false
+	}
+
+	return g.Regions.IsValid() && g.DNS.IsValid() && g.NotificationSinks.IsValid()
 }
 
 func (g *Group) Disable(ctx context.Context) error {
@@ -267,3 +327,27 @@ func (g *Group) LoadMetric(in interface{}) error {
 type LoadConfig struct {
 	Utilization float64 `json:"utilization"`
 }
+
+// GroupVars are user defined variables which are available when rendering a group's
+// user data.
+type GroupVars map[string]string
+
+// Value converts group vars to JSON to be stored in the database.
+func (gv GroupVars) Value() (driver.Value, error) {
+	if gv == nil {
+		gv = GroupVars{}
+	}
+
+	return json.Marshal(gv)
+}
+
+// Scan converts a DB value back into GroupVars.
+func (gv *GroupVars) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, gv)
+}
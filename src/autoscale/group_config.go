@@ -1,6 +1,7 @@
 package autoscale
 
 import (
+	"pkg/do"
 	"pkg/doclient"
 
 	"golang.org/x/net/context"
@@ -8,10 +9,13 @@ import (
 
 // GroupConfig are the options available for creating a group.
 type GroupConfig struct {
-	ID        string     `json:"id"`
-	Policies  []string   `json:"policies"`
-	Metrics   []string   `json:"metrics"`
-	Templates []Template `json:"templates"`
+	ID            string           `json:"id"`
+	Policies      []string         `json:"policies"`
+	Metrics       []string         `json:"metrics"`
+	Templates     []Template       `json:"templates"`
+	LoadBalancers do.LoadBalancers `json:"loadBalancers"`
+	FloatingIPs   do.FloatingIPs   `json:"floatingIPs"`
+	Domains       do.Domains       `json:"domains"`
 }
 
 // NewGroupConfig creates an instance of GroupConfig.
@@ -26,10 +30,28 @@ func NewGroupConfig(ctx context.Context, dc *doclient.Client, repo Repository) (
 		return nil, err
 	}
 
+	lbs, err := dc.LoadBalancersService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	fips, err := dc.FloatingIPsService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := dc.DomainsService.List()
+	if err != nil {
+		return nil, err
+	}
+
 	return &GroupConfig{
-		ID:        a.UUID,
-		Policies:  []string{"value"},
-		Metrics:   []string{"load"},
-		Templates: tmpls,
+		ID:            a.UUID,
+		Policies:      []string{"value"},
+		Metrics:       []string{"load"},
+		Templates:     tmpls,
+		LoadBalancers: lbs,
+		FloatingIPs:   fips,
+		Domains:       domains,
 	}, nil
 }
@@ -2,10 +2,15 @@ package autoscale
 
 import "time"
 
-// GroupStatus is a log of a scaling event for a group.
+// GroupStatus is a log of a scaling event for a group. Decision records why the group
+// changed. It is nil for events recorded before decisions were kept. Failed actions
+// have an error, and keep the group's last known total.
 type GroupStatus struct {
-	GroupID   string    `json:"groupID" db:"group_id"`
-	Delta     int       `json:"delta" db:"delta"`
-	Total     int       `json:"total" db:"total"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	GroupID   string       `json:"groupID" db:"group_id"`
+	Kind      ActivityKind `json:"kind" db:"kind"`
+	Delta     int          `json:"delta" db:"delta"`
+	Total     int          `json:"total" db:"total"`
+	Error     string       `json:"error,omitempty" db:"error"`
+	Decision  *Decision    `json:"decision,omitempty" db:"decision"`
+	CreatedAt time.Time    `json:"createdAt" db:"created_at"`
 }
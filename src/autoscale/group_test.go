@@ -12,23 +12,52 @@ import (
 
 func TestGroup_IsValid(t *testing.T) {
 	cases := []struct {
-		Name    string
-		IsValid bool
+		Name     string
+		UserData string
+		Vars     GroupVars
+		IsValid  bool
 	}{
 		{Name: "1234", IsValid: true},
 		{Name: "-1234", IsValid: false},
 		{Name: "a-template", IsValid: true},
+		{Name: "a-template", UserData: "## template: go\n{{.Vars.service}}", Vars: GroupVars{"service": "web"}, IsValid: true},
+		{Name: "a-template", UserData: "## template: go\n{{.Vars.service}}", IsValid: false},
+		{Name: "a-template", UserData: "## template: go\n{{.GroupName", IsValid: false},
+		{Name: "a-template", UserData: "{{.GroupName", IsValid: true},
 	}
 
 	for _, c := range cases {
 		group := Group{
-			Name: c.Name,
+			Name:     c.Name,
+			UserData: c.UserData,
+			Vars:     c.Vars,
 		}
 
 		assert.Equal(t, c.IsValid, group.IsValid())
 	}
 }
 
+func TestGroup_IsValidRegionCapacity(t *testing.T) {
+	policy, err := NewValuePolicy(ValuePolicyScale(1, 5, 0.8, 1, 0.2, 1))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		regions GroupRegions
+		valid   bool
+	}{
+		{name: "no regions", valid: true},
+		{name: "room for max", regions: GroupRegions{{Region: "nyc1", Max: 3}, {Region: "sfo2", Max: 2}}, valid: true},
+		{name: "unlimited region", regions: GroupRegions{{Region: "nyc1", Max: 1}, {Region: "sfo2"}}, valid: true},
+		{name: "below max", regions: GroupRegions{{Region: "nyc1", Max: 2}, {Region: "sfo2", Max: 2}}},
+	}
+
+	for _, c := range cases {
+		group := Group{Name: "group", Regions: c.regions, Policy: policy}
+		assert.Equal(t, c.valid, group.IsValid(), c.name)
+	}
+}
+
 func TestConvertGroupToJSON(t *testing.T) {
 	ctx := context.Background()
 	ogRMFactory := ResourceManagerFactory
@@ -55,6 +84,9 @@ func TestConvertGroupToJSON(t *testing.T) {
 		PolicyType: "value",
 		Policy:     vp,
 		TemplateID: "a-template",
+		NotificationSinks: SinkConfigs{
+			{Type: SinkWebhook, URL: "https://example.com/hook", Secret: "s"},
+		},
 	}
 
 	j, err := json.Marshal(&g)
@@ -72,6 +104,8 @@ func TestConvertGroupToJSON(t *testing.T) {
 	assert.Equal(t, g.MetricType, newGroup.MetricType)
 	assert.Equal(t, g.Policy, newGroup.Policy)
 	assert.Equal(t, g.Metric, newGroup.Metric)
+	assert.Equal(t, g.NotificationSinks.Redacted(), newGroup.NotificationSinks)
+	assert.NotContains(t, string(j), `"secret":"s"`)
 }
 
 func TestConvertEmptyGroupToJSON(t *testing.T) {
@@ -0,0 +1,279 @@
+package autoscale
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"pkg/do"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// errLoadBalancerRegion is returned when a droplet can't be added to a load balancer
+// because it is in another region.
+var errLoadBalancerRegion = fmt.Errorf("droplet is not in the load balancer's region")
+
+// checkHealth runs a load balancer health check against the droplet at address. HTTP
+// checks pass for 2xx and 3xx responses. Certificates aren't verified for HTTPS checks,
+// the same as the load balancer.
+func checkHealth(hc *do.HealthCheck, address string) error {
+	timeout := defaultHealthCheckTimeout
+	if hc.ResponseTimeoutSeconds > 0 {
+		timeout = time.Duration(hc.ResponseTimeoutSeconds) * time.Second
+	}
+
+	hostPort := net.JoinHostPort(address, strconv.Itoa(hc.Port))
+
+	switch hc.Protocol {
+	case "tcp":
+		conn, err := net.DialTimeout("tcp", hostPort, timeout)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+
+	case "http", "https":
+		client := &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		path := hc.Path
+		if path == "" {
+			path = "/"
+		}
+
+		resp, err := client.Get(fmt.Sprintf("%s://%s%s", hc.Protocol, hostPort, path))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("health check returned status %d", resp.StatusCode)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unknown health check protocol %q", hc.Protocol)
+	}
+}
+
+// registerDroplets runs a round of load balancer health checks against the droplets
+// created by an operation which aren't behind the group's load balancer yet. Droplets
+// which are active and have passed as many checks in a row as the health check's
+// healthy threshold are added. It returns true once every droplet is added, or once
+// the operation has waited lbRegistrationTimeout, after which droplets which never
+// became healthy are left out and recorded as errors. Droplets in other regions than the
+// load balancer are skipped.
+func (r *DropletResource) registerDroplets(ctx context.Context, op *Operation) (bool, error) {
+	lb, err := r.doClient.LoadBalancersService.Get(r.loadBalancerID)
+	if err != nil {
+		return false, err
+	}
+
+	log := r.log.WithField("load-balancer-id", lb.ID)
+
+	threshold := 1
+	if lb.HealthCheck != nil && lb.HealthCheck.HealthyThreshold > 0 {
+		threshold = lb.HealthCheck.HealthyThreshold
+	}
+
+	if op.HealthPasses == nil {
+		op.HealthPasses = HealthPasses{}
+	}
+
+	registered := map[int]bool{}
+	for _, id := range lb.DropletIDs {
+		registered[id] = true
+	}
+
+	healthy := []int{}
+	pending := []int{}
+	for _, id := range op.DropletIDs {
+		if registered[id] {
+			delete(op.HealthPasses, id)
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		err := r.checkDroplet(lb, id)
+		if err == errLoadBalancerRegion {
+			log.WithField("droplet-id", id).Warn("not adding droplet to load balancer in another region")
+			delete(op.HealthPasses, id)
+			continue
+		}
+
+		if err != nil {
+			log.WithError(err).WithField("droplet-id", id).Info("droplet is not healthy yet")
+			op.HealthPasses[id] = 0
+			pending = append(pending, id)
+			continue
+		}
+
+		op.HealthPasses[id]++
+		if op.HealthPasses[id] >= threshold {
+			healthy = append(healthy, id)
+			continue
+		}
+
+		pending = append(pending, id)
+	}
+
+	if len(healthy) > 0 {
+		sort.Ints(healthy)
+		log.WithField("droplet-ids", healthy).Info("adding droplets to load balancer")
+		if err := r.doClient.LoadBalancersService.AddDroplets(lb.ID, healthy); err != nil {
+			return false, err
+		}
+
+		for _, id := range healthy {
+			delete(op.HealthPasses, id)
+		}
+	}
+
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	if time.Since(op.CreatedAt) < lbRegistrationTimeout {
+		return false, nil
+	}
+
+	for _, id := range pending {
+		op.AddError(fmt.Errorf("droplet %d did not pass load balancer health checks", id))
+		delete(op.HealthPasses, id)
+	}
+
+	return true, nil
+}
+
+// checkDroplet returns an error if the droplet isn't active or doesn't pass the load
+// balancer's health check.
+func (r *DropletResource) checkDroplet(lb *do.LoadBalancer, id int) error {
+	droplet, err := r.doClient.DropletsService.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if lb.Region != nil && dropletRegion(*droplet) != lb.Region.Slug {
+		return errLoadBalancerRegion
+	}
+
+	if droplet.Status != "active" {
+		return fmt.Errorf("droplet is %s", droplet.Status)
+	}
+
+	if lb.HealthCheck == nil {
+		return nil
+	}
+
+	address, err := healthCheckAddress(*droplet)
+	if err != nil {
+		return err
+	}
+
+	return checkHealth(lb.HealthCheck, address)
+}
+
+// healthCheckAddress returns the address a droplet is health checked on. Autoscale runs
+// the checks itself, so the private address is only used if it can reach droplets over
+// their private network.
+func healthCheckAddress(d do.Droplet) (string, error) {
+	if UsePrivateNetworking {
+		if address, err := d.PrivateIPv4(); err == nil && address != "" {
+			return address, nil
+		}
+	}
+
+	address, err := d.PublicIPv4()
+	if err != nil {
+		return "", err
+	}
+
+	if address == "" {
+		return "", fmt.Errorf("droplet has no ipv4 address")
+	}
+
+	return address, nil
+}
+
+// deregisterDroplets removes droplets from the group's load balancer. Droplets which
+// aren't behind the load balancer are skipped. If drain is true, it waits for their
+// connections to drain, or until ctx is done.
+func (r *DropletResource) deregisterDroplets(ctx context.Context, ids []int, drain bool) error {
+	lb, err := r.doClient.LoadBalancersService.Get(r.loadBalancerID)
+	if err != nil {
+		return err
+	}
+
+	registered := map[int]bool{}
+	for _, id := range lb.DropletIDs {
+		registered[id] = true
+	}
+
+	remove := []int{}
+	for _, id := range ids {
+		if registered[id] {
+			remove = append(remove, id)
+		}
+	}
+
+	if len(remove) == 0 {
+		return nil
+	}
+
+	r.log.WithFields(logrus.Fields{
+		"load-balancer-id": lb.ID,
+		"droplet-ids":      remove,
+	}).Info("removing droplets from load balancer")
+	if err := r.doClient.LoadBalancersService.RemoveDroplets(lb.ID, remove); err != nil {
+		return err
+	}
+
+	if !drain {
+		return nil
+	}
+
+	r.log.WithField("drain-duration", lbDrainDelay).Info("waiting for droplets to drain")
+	select {
+	case <-time.After(lbDrainDelay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isLoadBalanced returns true if the droplet is behind the load balancer. Droplets in
+// other regions can't be added to the load balancer, so they are always treated as load
+// balanced.
+func isLoadBalanced(lb *do.LoadBalancer, droplet do.Droplet) bool {
+	if lb.Region != nil && dropletRegion(droplet) != lb.Region.Slug {
+		return true
+	}
+
+	for _, id := range lb.DropletIDs {
+		if id == droplet.ID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,299 @@
+package autoscale
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pkg/do"
+	"pkg/do/mocks"
+	"pkg/doclient"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+// testHealthServer starts an http server which responds with status to /health. It
+// returns the server and its port.
+func testHealthServer(t *testing.T, status int) (*httptest.Server, int) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(status)
+	}))
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	_, p, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	port, err := strconv.Atoi(p)
+	require.NoError(t, err)
+
+	return ts, port
+}
+
+func TestCheckHealth(t *testing.T) {
+	ok, okPort := testHealthServer(t, http.StatusOK)
+	defer ok.Close()
+
+	failing, failingPort := testHealthServer(t, http.StatusInternalServerError)
+	defer failing.Close()
+
+	assert.NoError(t, checkHealth(&do.HealthCheck{Protocol: "http", Port: okPort, Path: "/health"}, "127.0.0.1"))
+	assert.Error(t, checkHealth(&do.HealthCheck{Protocol: "http", Port: okPort, Path: "/missing"}, "127.0.0.1"))
+	assert.Error(t, checkHealth(&do.HealthCheck{Protocol: "http", Port: failingPort, Path: "/health"}, "127.0.0.1"))
+	assert.NoError(t, checkHealth(&do.HealthCheck{Protocol: "tcp", Port: okPort}, "127.0.0.1"))
+	assert.Error(t, checkHealth(&do.HealthCheck{Protocol: "udp", Port: okPort}, "127.0.0.1"))
+}
+
+func TestDropletResource_RegisterDroplets(t *testing.T) {
+	ctx := context.Background()
+
+	hs, port := testHealthServer(t, http.StatusOK)
+	defer hs.Close()
+
+	active := testDroplet(1, "active", "127.0.0.1")
+	pending := testDroplet(2, "new", "127.0.0.1")
+	other := testDroplet(3, "active", "127.0.0.1")
+	other.Region = &godo.Region{Slug: "sfo2"}
+
+	ds := &mocks.DropletsService{}
+	ds.On("Get", 1).Return(&active, nil)
+	ds.On("Get", 2).Return(&pending, nil)
+	ds.On("Get", 3).Return(&other, nil)
+
+	lb := do.LoadBalancer{
+		ID:          "lb-1",
+		Region:      &godo.Region{Slug: "nyc1"},
+		HealthCheck: &do.HealthCheck{Protocol: "http", Port: port, Path: "/health", HealthyThreshold: 2},
+	}
+	added := lb
+	added.DropletIDs = []int{1}
+
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&lb, nil).Twice()
+	lbs.On("Get", "lb-1").Return(&added, nil)
+	lbs.On("AddDroplets", "lb-1", []int{1}).Return(nil).Once()
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{DropletsService: ds, LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	op := NewOperation("abc", 3)
+	op.DropletIDs = DropletIDs{1, 2, 3}
+
+	done, err := r.registerDroplets(ctx, op)
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Equal(t, HealthPasses{1: 1, 2: 0}, op.HealthPasses)
+	lbs.AssertNotCalled(t, "AddDroplets", "lb-1", mock.Anything)
+
+	done, err = r.registerDroplets(ctx, op)
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Equal(t, HealthPasses{2: 0}, op.HealthPasses)
+
+	op.CreatedAt = time.Now().Add(-lbRegistrationTimeout)
+	done, err = r.registerDroplets(ctx, op)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Empty(t, op.HealthPasses)
+	require.Equal(t, OperationErrors{"droplet 2 did not pass load balancer health checks"}, op.Errors)
+
+	assert.True(t, lbs.AssertExpectations(t))
+}
+
+func TestDropletResource_RegisterDropletsSkipsMembers(t *testing.T) {
+	ctx := context.Background()
+
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&do.LoadBalancer{ID: "lb-1", DropletIDs: []int{1}}, nil)
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{DropletsService: &mocks.DropletsService{}, LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	op := NewOperation("abc", 1)
+	op.DropletIDs = DropletIDs{1}
+	op.HealthPasses[1] = 1
+
+	done, err := r.registerDroplets(ctx, op)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Empty(t, op.HealthPasses)
+}
+
+func TestDropletResource_ScaleUpLeavesRegistering(t *testing.T) {
+	ctx := context.Background()
+
+	op := NewOperation("abc", 1)
+	op.DropletIDs = DropletIDs{1}
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{loadBalancerID: "lb-1"}
+	require.NoError(t, r.startRegistering(ctx, op, repo))
+	require.Equal(t, OperationRegistering, op.State)
+
+	op = NewOperation("abc", 1)
+	op.DropletIDs = DropletIDs{1}
+	r = &DropletResource{}
+	require.NoError(t, r.startRegistering(ctx, op, repo))
+	require.Equal(t, OperationPlanned, op.State)
+}
+
+func TestDropletResource_DeregisterDropletsDrain(t *testing.T) {
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&do.LoadBalancer{ID: "lb-1", DropletIDs: []int{1, 2}}, nil)
+	lbs.On("RemoveDroplets", "lb-1", []int{2}).Return(nil)
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Equal(t, context.Canceled, r.deregisterDroplets(ctx, []int{2, 3}, true))
+	require.NoError(t, r.deregisterDroplets(ctx, []int{2}, false))
+	assert.True(t, lbs.AssertExpectations(t))
+}
+
+func TestHealthCheckAddress(t *testing.T) {
+	d := testDroplet(1, "active", "127.0.0.1")
+
+	address, err := healthCheckAddress(d)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", address)
+
+	d.Networks.V4 = append(d.Networks.V4, godo.NetworkV4{IPAddress: "10.0.0.2", Type: "private"})
+	address, err = healthCheckAddress(d)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", address)
+
+	UsePrivateNetworking = true
+	defer func() { UsePrivateNetworking = false }()
+
+	address, err = healthCheckAddress(d)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2", address)
+}
+
+func TestDropletResource_ScaleDownDeregisters(t *testing.T) {
+	ctx := context.Background()
+
+	drain := lbDrainDelay
+	lbDrainDelay = 0
+	defer func() { lbDrainDelay = drain }()
+
+	ds := &mocks.DropletsService{}
+	droplets := do.Droplets{
+		{Droplet: &godo.Droplet{ID: 1, Tags: []string{"as-group", BaseTag, ProtectedTag}}},
+		{Droplet: &godo.Droplet{ID: 2, Tags: []string{"as-group", BaseTag}}},
+	}
+	ds.On("ListByTag", "as-group").Return(droplets, nil)
+	ds.On("Delete", 2).Return(nil)
+
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&do.LoadBalancer{ID: "lb-1", DropletIDs: []int{1, 2}}, nil)
+	lbs.On("RemoveDroplets", "lb-1", []int{2}).Return(nil)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{DropletsService: ds, LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	op := NewOperation("abc", -1)
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.NoError(t, err)
+	require.Equal(t, DropletIDs{2}, op.DropletIDs)
+
+	assert.True(t, ds.AssertExpectations(t))
+	assert.True(t, lbs.AssertExpectations(t))
+}
+
+func TestDropletResource_ScaleDownDeregisterError(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	ds.On("ListByTag", "as-group").Return(do.Droplets{
+		{Droplet: &godo.Droplet{ID: 2, Tags: []string{"as-group", BaseTag}}},
+	}, nil)
+
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&do.LoadBalancer{ID: "lb-1", DropletIDs: []int{2}}, nil)
+	lbs.On("RemoveDroplets", "lb-1", []int{2}).Return(assert.AnError)
+
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.Anything).Return(nil)
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{DropletsService: ds, LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	op := NewOperation("abc", -1)
+	_, err := r.Scale(ctx, Group{ID: "abc"}, op, repo)
+	require.Equal(t, assert.AnError, err)
+	require.Empty(t, op.DropletIDs)
+
+	ds.AssertNotCalled(t, "Delete", 2)
+}
+
+func TestDropletResource_HealthyLoadBalancer(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &mocks.DropletsService{}
+	registered := testDroplet(1, "active", "127.0.0.1")
+	unregistered := testDroplet(2, "active", "127.0.0.1")
+	ds.On("Get", 1).Return(&registered, nil)
+	ds.On("Get", 2).Return(&unregistered, nil)
+
+	lbs := &mocks.LoadBalancersService{}
+	lbs.On("Get", "lb-1").Return(&do.LoadBalancer{ID: "lb-1", Region: &godo.Region{Slug: "nyc1"}, DropletIDs: []int{1}}, nil)
+
+	r := &DropletResource{
+		doClient:       &doclient.Client{DropletsService: ds, LoadBalancersService: lbs},
+		tag:            "as-group",
+		log:            logrus.WithField("test", "load-balancer"),
+		loadBalancerID: "lb-1",
+	}
+
+	healthy, err := r.Healthy(ctx, []string{"1"})
+	require.NoError(t, err)
+	require.True(t, healthy)
+
+	healthy, err = r.Healthy(ctx, []string{"1", "2"})
+	require.NoError(t, err)
+	require.False(t, healthy)
+}
@@ -3,6 +3,7 @@ package autoscale
 import (
 	"fmt"
 	"pkg/ctxutil"
+	"strconv"
 
 	"golang.org/x/net/context"
 
@@ -11,8 +12,11 @@ import (
 
 // LocalResource is a local resource. Useful for testing on planes.
 type LocalResource struct {
-	count int
-	log   *logrus.Entry
+	count           int
+	protected       map[string]bool
+	templateID      string
+	templateVersion int
+	log             *logrus.Entry
 }
 
 var _ ResourceManager = (*LocalResource)(nil)
@@ -28,7 +32,8 @@ func NewLocalResource(ctx context.Context) ResourceManager {
 	log = log.WithField("resource-type", "local")
 
 	return &LocalResource{
-		log: log,
+		protected: map[string]bool{},
+		log:       log,
 	}
 }
 
@@ -37,8 +42,14 @@ func (r *LocalResource) Count() (int, error) {
 	return r.count, nil
 }
 
-// Scale scales in memory resources byN.
-func (r *LocalResource) Scale(ctx context.Context, g Group, byN int, repo Repository) (bool, error) {
+// Scale scales in memory resources by the operation's requested delta.
+func (r *LocalResource) Scale(ctx context.Context, g Group, op *Operation, repo Repository) (bool, error) {
+	byN := op.RequestedDelta
+	if unprotected := r.count - len(r.protected) - len(op.Keep); byN < -unprotected {
+		byN = -unprotected
+	}
+	op.ActualDelta = byN
+
 	if byN > 0 {
 		return true, r.scaleUp(ctx, g, byN, repo)
 	} else if byN < 0 {
@@ -48,10 +59,18 @@ func (r *LocalResource) Scale(ctx context.Context, g Group, byN int, repo Reposi
 	}
 }
 
+// Resume resumes an interrupted operation. In memory resources don't survive a restart,
+// so there is nothing to resume.
+func (r *LocalResource) Resume(ctx context.Context, g Group, op *Operation, repo Repository) error {
+	return nil
+}
+
 // ScaleUp scales resources up.
 func (r *LocalResource) scaleUp(ctx context.Context, g Group, byN int, repo Repository) error {
 	r.log.WithField("by-n", byN).Info("scaling up")
 
+	r.templateID = g.TemplateID
+	r.templateVersion = g.TemplateVersion
 	r.count = r.count + byN
 	return nil
 }
@@ -68,11 +87,82 @@ func (r *LocalResource) scaleDown(ctx context.Context, g Group, byN int, repo Re
 func (r *LocalResource) Allocated() ([]ResourceAllocation, error) {
 	allocations := []ResourceAllocation{}
 	for i := 0; i < r.count; i++ {
+		id := strconv.Itoa(i + 1)
 		allocation := ResourceAllocation{
-			Name: fmt.Sprintf("instance-%d", i+1),
+			ID:              id,
+			Name:            fmt.Sprintf("instance-%d", i+1),
+			Protected:       r.protected[id],
+			TemplateID:      r.templateID,
+			TemplateVersion: r.templateVersion,
+			AgentPort:       DefaultAgentPort,
 		}
 		allocations = append(allocations, allocation)
 	}
 
 	return allocations, nil
 }
+
+// Protect protects or unprotects an in memory resource from being scaled in. Protected
+// resources are counted, but are never removed.
+func (r *LocalResource) Protect(ctx context.Context, id string, protected bool) error {
+	i, err := strconv.Atoi(id)
+	if err != nil || i < 1 || i > r.count {
+		return ErrResourceNotInGroup
+	}
+
+	if protected {
+		r.protected[id] = true
+	} else {
+		delete(r.protected, id)
+	}
+
+	return nil
+}
+
+// Attach adds an in memory resource to the group.
+func (r *LocalResource) Attach(ctx context.Context, id string) error {
+	r.count++
+	return nil
+}
+
+// Detach removes an in memory resource from the group.
+func (r *LocalResource) Detach(ctx context.Context, id string) error {
+	i, err := strconv.Atoi(id)
+	if err != nil || i < 1 || i > r.count {
+		return ErrResourceNotInGroup
+	}
+
+	delete(r.protected, id)
+	r.count--
+	return nil
+}
+
+// Remove removes in memory resources.
+func (r *LocalResource) Remove(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		i, err := strconv.Atoi(id)
+		if err != nil || i < 1 || i > r.count {
+			return ErrResourceNotInGroup
+		}
+
+		delete(r.protected, id)
+	}
+
+	r.count = r.count - len(ids)
+	return nil
+}
+
+// Healthy returns true since in memory resources are always healthy.
+func (r *LocalResource) Healthy(ctx context.Context, ids []string) (bool, error) {
+	return true, nil
+}
+
+// ReconcileFloatingIP does nothing since in memory resources have no floating IP.
+func (r *LocalResource) ReconcileFloatingIP(ctx context.Context) ([]FloatingIPChange, error) {
+	return nil, nil
+}
+
+// ReconcileDNS does nothing since in memory resources have no DNS records.
+func (r *LocalResource) ReconcileDNS(ctx context.Context) error {
+	return nil
+}
@@ -22,6 +22,62 @@ func (_m *MockGroupAction) Scale(ctx context.Context, groupID string) *ActionSta
 
 	return r0
 }
+func (_m *MockGroupAction) Resize(ctx context.Context, groupID string, size int) *ActionStatus {
+	ret := _m.Called(ctx, groupID, size)
+
+	var r0 *ActionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *ActionStatus); ok {
+		r0 = rf(ctx, groupID, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ActionStatus)
+		}
+	}
+
+	return r0
+}
+func (_m *MockGroupAction) Attach(ctx context.Context, groupID string, resourceID string, adjustCount bool) *ActionStatus {
+	ret := _m.Called(ctx, groupID, resourceID, adjustCount)
+
+	var r0 *ActionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) *ActionStatus); ok {
+		r0 = rf(ctx, groupID, resourceID, adjustCount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ActionStatus)
+		}
+	}
+
+	return r0
+}
+func (_m *MockGroupAction) Detach(ctx context.Context, groupID string, resourceID string, adjustCount bool) *ActionStatus {
+	ret := _m.Called(ctx, groupID, resourceID, adjustCount)
+
+	var r0 *ActionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) *ActionStatus); ok {
+		r0 = rf(ctx, groupID, resourceID, adjustCount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ActionStatus)
+		}
+	}
+
+	return r0
+}
+func (_m *MockGroupAction) Refresh(ctx context.Context, groupID string, refreshID string) *ActionStatus {
+	ret := _m.Called(ctx, groupID, refreshID)
+
+	var r0 *ActionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *ActionStatus); ok {
+		r0 = rf(ctx, groupID, refreshID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ActionStatus)
+		}
+	}
+
+	return r0
+}
 func (_m *MockGroupAction) Disable(ctx context.Context, groupID string) *ActionStatus {
 	ret := _m.Called(ctx, groupID)
 
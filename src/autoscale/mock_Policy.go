@@ -20,6 +20,37 @@ func (_m *MockPolicy) CalculateSize(resourceCount int, value float64) int {
 
 	return r0
 }
+func (_m *MockPolicy) Clamp(size int) int {
+	ret := _m.Called(size)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int) int); ok {
+		r0 = rf(size)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+func (_m *MockPolicy) Trigger(value float64) (float64, string) {
+	ret := _m.Called(value)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(float64) float64); ok {
+		r0 = rf(value)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(float64) string); ok {
+		r1 = rf(value)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	return r0, r1
+}
 func (_m *MockPolicy) WarmUpPeriod() time.Duration {
 	ret := _m.Called()
 
@@ -71,6 +71,27 @@ func (_m *MockRepository) ListTemplates(ctx context.Context) ([]Template, error)
 
 	return r0, r1
 }
+func (_m *MockRepository) UpdateTemplate(ctx context.Context, t Template) (*Template, error) {
+	ret := _m.Called(ctx, t)
+
+	var r0 *Template
+	if rf, ok := ret.Get(0).(func(context.Context, Template) *Template); ok {
+		r0 = rf(ctx, t)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Template)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, Template) error); ok {
+		r1 = rf(ctx, t)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
 func (_m *MockRepository) DeleteTemplate(ctx context.Context, name string) error {
 	ret := _m.Called(ctx, name)
 
@@ -83,6 +104,48 @@ func (_m *MockRepository) DeleteTemplate(ctx context.Context, name string) error
 
 	return r0
 }
+func (_m *MockRepository) GetTemplateVersion(ctx context.Context, id string, version int) (*Template, error) {
+	ret := _m.Called(ctx, id, version)
+
+	var r0 *Template
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *Template); ok {
+		r0 = rf(ctx, id, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Template)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) ListTemplateVersions(ctx context.Context, id string) ([]Template, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []Template
+	if rf, ok := ret.Get(0).(func(context.Context, string) []Template); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Template)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
 func (_m *MockRepository) CreateGroup(ctx context.Context, g Group) (*Group, error) {
 	ret := _m.Called(ctx, g)
 
@@ -245,6 +308,213 @@ func (_m *MockRepository) GetGroupHistory(ctx context.Context, groupID string, t
 
 	return r0, r1
 }
+func (_m *MockRepository) CreateOperation(ctx context.Context, op Operation) (*Operation, error) {
+	ret := _m.Called(ctx, op)
+
+	var r0 *Operation
+	if rf, ok := ret.Get(0).(func(context.Context, Operation) *Operation); ok {
+		r0 = rf(ctx, op)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, Operation) error); ok {
+		r1 = rf(ctx, op)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) SaveOperation(ctx context.Context, op Operation) error {
+	ret := _m.Called(ctx, op)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Operation) error); ok {
+		r0 = rf(ctx, op)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockRepository) GetOperation(ctx context.Context, id string) (*Operation, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string) *Operation); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) ListUnfinishedOperations(ctx context.Context) ([]Operation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []Operation
+	if rf, ok := ret.Get(0).(func(context.Context) []Operation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) CreateRefresh(ctx context.Context, r Refresh) (*Refresh, error) {
+	ret := _m.Called(ctx, r)
+
+	var r0 *Refresh
+	if rf, ok := ret.Get(0).(func(context.Context, Refresh) *Refresh); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Refresh)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, Refresh) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) GetRefresh(ctx context.Context, id string) (*Refresh, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *Refresh
+	if rf, ok := ret.Get(0).(func(context.Context, string) *Refresh); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Refresh)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) UpdateRefreshState(ctx context.Context, id string, state RefreshState) error {
+	ret := _m.Called(ctx, id, state)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, RefreshState) error); ok {
+		r0 = rf(ctx, id, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockRepository) SaveRefreshProgress(ctx context.Context, r Refresh) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Refresh) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockRepository) ListUnfinishedRefreshes(ctx context.Context) ([]Refresh, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []Refresh
+	if rf, ok := ret.Get(0).(func(context.Context) []Refresh); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Refresh)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockRepository) AddDeadLetter(ctx context.Context, d DeadLetter) error {
+	ret := _m.Called(ctx, d)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, DeadLetter) error); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockRepository) AddEvent(ctx context.Context, n Notification) error {
+	ret := _m.Called(ctx, n)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Notification) error); ok {
+		r0 = rf(ctx, n)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockRepository) ListEvents(ctx context.Context, q EventQuery) ([]Notification, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 []Notification
+	if rf, ok := ret.Get(0).(func(context.Context, EventQuery) []Notification); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Notification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, EventQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
 func (_m *MockRepository) Close() error {
 	ret := _m.Called()
 
@@ -27,25 +27,37 @@ func (_m *MockResourceManager) Count() (int, error) {
 
 	return r0, r1
 }
-func (_m *MockResourceManager) Scale(ctx context.Context, g Group, byN int, repo Repository) (bool, error) {
-	ret := _m.Called(ctx, g, byN, repo)
+func (_m *MockResourceManager) Scale(ctx context.Context, g Group, op *Operation, repo Repository) (bool, error) {
+	ret := _m.Called(ctx, g, op, repo)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context, Group, int, Repository) bool); ok {
-		r0 = rf(ctx, g, byN, repo)
+	if rf, ok := ret.Get(0).(func(context.Context, Group, *Operation, Repository) bool); ok {
+		r0 = rf(ctx, g, op, repo)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, Group, int, Repository) error); ok {
-		r1 = rf(ctx, g, byN, repo)
+	if rf, ok := ret.Get(1).(func(context.Context, Group, *Operation, Repository) error); ok {
+		r1 = rf(ctx, g, op, repo)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+func (_m *MockResourceManager) Resume(ctx context.Context, g Group, op *Operation, repo Repository) error {
+	ret := _m.Called(ctx, g, op, repo)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Group, *Operation, Repository) error); ok {
+		r0 = rf(ctx, g, op, repo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
 func (_m *MockResourceManager) Allocated() ([]ResourceAllocation, error) {
 	ret := _m.Called()
 
@@ -67,3 +79,103 @@ func (_m *MockResourceManager) Allocated() ([]ResourceAllocation, error) {
 
 	return r0, r1
 }
+func (_m *MockResourceManager) Protect(ctx context.Context, id string, protected bool) error {
+	ret := _m.Called(ctx, id, protected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, id, protected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockResourceManager) Attach(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockResourceManager) Detach(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockResourceManager) Remove(ctx context.Context, ids []string) error {
+	ret := _m.Called(ctx, ids)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *MockResourceManager) Healthy(ctx context.Context, ids []string) (bool, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, []string) bool); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockResourceManager) ReconcileFloatingIP(ctx context.Context) ([]FloatingIPChange, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []FloatingIPChange
+	if rf, ok := ret.Get(0).(func(context.Context) []FloatingIPChange); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FloatingIPChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *MockResourceManager) ReconcileDNS(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
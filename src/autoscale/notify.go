@@ -1,33 +1,95 @@
 package autoscale
 
 import (
+	"encoding/json"
+	"fmt"
 	"pkg/ctxutil"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/satori/go.uuid"
 
 	"golang.org/x/net/context"
 )
 
-// Notification is a notification message from the scheduler.
+// Notification is a notification message from the scheduler. Notifications are stored
+// as events so they can be listed and replayed later.
 type Notification struct {
-	ID        string    `json:"id"`
-	GroupID   string    `json:"groupID"`
-	Name      string    `json:"name"`
-	Action    string    `json:"action"`
-	Delta     int       `json:"delta"`
-	Count     int       `json:"count"`
-	Message   string    `json:"message"`
-	IsError   bool      `json:"isError"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string    `json:"id" db:"id"`
+	GroupID   string    `json:"groupID" db:"group_id"`
+	Name      string    `json:"name" db:"name"`
+	Event     string    `json:"event" db:"event"`
+	Kind      string    `json:"kind" db:"kind"`
+	Delta     int       `json:"delta" db:"delta"`
+	Count     int       `json:"count" db:"count"`
+	Message   string    `json:"message" db:"message"`
+	IsError   bool      `json:"isError" db:"is_error"`
+	Decision  *Decision `json:"decision,omitempty" db:"decision"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
-// Notify listens to the scheduler to generate Notification.
+// Text describes the notification in a line, e.g. "group web scaled 3→5 (load 1.2 ≥ 0.8)".
+func (n Notification) Text() string {
+	name := n.Name
+	if name == "" {
+		name = n.GroupID
+	}
+
+	switch {
+	case n.IsError:
+		return fmt.Sprintf("group %s failed: %s", name, n.Message)
+	case n.Event == EventDisabled:
+		return fmt.Sprintf("group %s was disabled", name)
+	case n.Event == EventEnabled:
+		return fmt.Sprintf("group %s was enabled", name)
+	case n.Kind == string(ActivityScaled):
+		text := fmt.Sprintf("group %s scaled %d→%d", name, n.Count-n.Delta, n.Count)
+		if n.Decision != nil {
+			text += fmt.Sprintf(" (%s)", n.Decision)
+		}
+
+		return text
+	case n.Message != "":
+		return fmt.Sprintf("group %s: %s", name, n.Message)
+	default:
+		return fmt.Sprintf("group %s: %s", name, n.Event)
+	}
+}
+
+// digestText describes several notifications, one per line.
+func digestText(notifs []Notification) string {
+	lines := []string{fmt.Sprintf("%d autoscale notifications:", len(notifs))}
+	for _, n := range notifs {
+		lines = append(lines, "• "+n.Text())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NotifyActivityKinds are the kinds of activity Notify should be registered for.
+var NotifyActivityKinds = []ActivityKind{
+	ActivityScaled,
+	ActivityFailed,
+	ActivityDisabled,
+	ActivityEnabled,
+	ActivityTimedOut,
+	ActivityFloatingIPReassigned,
+}
+
+// Notify listens to the scheduler to generate Notification. Notifications are sent to
+// the global sinks and the sinks of their group as well.
 type Notify struct {
 	ctx                  context.Context
 	repo                 Repository
 	ActivityListener     chan SchedulerActivity
 	NotificationListener chan Notification
+
+	mu         sync.Mutex
+	sinks      []*notifySink
+	groupSinks map[string][]*notifySink
+	groupKeys  map[string]string
 }
 
 // NewNotify creates an instance of Notify.
@@ -37,29 +99,54 @@ func NewNotify(ctx context.Context, repo Repository) *Notify {
 		repo:                 repo,
 		ActivityListener:     make(chan SchedulerActivity),
 		NotificationListener: make(chan Notification, 100),
+		groupSinks:           map[string][]*notifySink{},
+		groupKeys:            map[string]string{},
+	}
+}
+
+// AddSinks adds sinks which receive notifications for every group.
+func (n *Notify) AddSinks(scs SinkConfigs) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, sc := range scs {
+		ns, err := newNotifySink(sc)
+		if err != nil {
+			return err
+		}
+
+		n.sinks = append(n.sinks, ns)
 	}
+
+	return nil
 }
 
 // Start starts the listener.
 func (n *Notify) Start() {
 	for msg := range n.ActivityListener {
-		if msg.Delta == 0 {
-			continue
-		}
-
-		log := ctxutil.LogFromContext(n.ctx).WithField("action", "notify")
+		log := ctxutil.LogFromContext(n.ctx).WithFields(logrus.Fields{
+			"action":   "notify",
+			"group-id": msg.ID,
+			"kind":     msg.Kind,
+		})
 		log.Info("sending notification to websocket clients")
 
+		// The group may be gone, e.g. when it was disabled because it was deleted. The
+		// notification is still sent without its name.
 		g, err := n.repo.GetGroup(n.ctx, msg.ID)
 		if err != nil {
-			log.WithError(err).Error("unable to load group")
-			continue
+			log.WithError(err).Warn("unable to load group")
+			g = &Group{ID: msg.ID}
 		}
 
 		notif := Notification{
 			ID:        uuid.NewV4().String(),
 			GroupID:   msg.ID,
 			Name:      g.Name,
+			Event:     activityEvent(msg),
+			Kind:      string(msg.Kind),
+			Message:   msg.Message,
+			Decision:  msg.Decision,
 			CreatedAt: time.Now(),
 		}
 		if msg.Err != nil {
@@ -70,6 +157,69 @@ func (n *Notify) Start() {
 			notif.Count = msg.Count
 		}
 
+		if err := n.repo.AddEvent(n.ctx, notif); err != nil {
+			log.WithError(err).Error("unable to store event")
+		}
+
 		n.NotificationListener <- notif
+
+		for _, ns := range n.matchingSinks(g, notif.Event) {
+			ns.enqueue(n.ctx, n.repo, notif)
+		}
+	}
+}
+
+// matchingSinks returns the global sinks and the group's sinks which accept event. The
+// group's sinks are created again when its sink configs change.
+func (n *Notify) matchingSinks(g *Group, event string) []*notifySink {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	log := ctxutil.LogFromContext(n.ctx).WithField("group-id", g.ID)
+
+	key := ""
+	if b, err := json.Marshal(g.NotificationSinks); err == nil {
+		key = string(b)
+	}
+
+	if n.groupKeys[g.ID] != key {
+		sinks := []*notifySink{}
+		for _, sc := range g.NotificationSinks {
+			ns, err := newNotifySink(sc)
+			if err != nil {
+				log.WithError(err).WithField("sink", sc.String()).Error("unable to create notification sink")
+				continue
+			}
+
+			sinks = append(sinks, ns)
+		}
+
+		n.groupSinks[g.ID] = sinks
+		n.groupKeys[g.ID] = key
+	}
+
+	matching := []*notifySink{}
+	for _, ns := range append(append([]*notifySink{}, n.sinks...), n.groupSinks[g.ID]...) {
+		if ns.config.Accepts(event) {
+			matching = append(matching, ns)
+		}
+	}
+
+	return matching
+}
+
+// activityEvent returns the event of the notification for an activity.
+func activityEvent(msg SchedulerActivity) string {
+	switch msg.Kind {
+	case ActivityScaled:
+		if msg.Delta > 0 {
+			return EventScaleUp
+		}
+
+		return EventScaleDown
+	case ActivityFailed:
+		return EventError
+	default:
+		return string(msg.Kind)
 	}
 }
@@ -0,0 +1,44 @@
+package autoscale
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestNotify_StoresEvents(t *testing.T) {
+	repo := &MockRepository{}
+	repo.On("GetGroup", mock.Anything, "g-1").Return(&Group{ID: "g-1", Name: "web"}, nil)
+	repo.On("AddEvent", mock.Anything, mock.AnythingOfType("Notification")).Return(nil)
+
+	n := NewNotify(context.Background(), repo)
+	go n.Start()
+	defer close(n.ActivityListener)
+
+	n.ActivityListener <- SchedulerActivity{ID: "g-1", Kind: ActivityScaled, Delta: 2, Count: 5}
+	n.ActivityListener <- SchedulerActivity{ID: "g-1", Kind: ActivityFailed, Err: fmt.Errorf("boom")}
+
+	scaled := <-n.NotificationListener
+	failed := <-n.NotificationListener
+
+	assert.Equal(t, EventScaleUp, scaled.Event)
+	assert.Equal(t, EventError, failed.Event)
+	assert.Equal(t, "boom", failed.Message)
+	assert.True(t, failed.IsError)
+
+	stored := []Notification{}
+	for _, call := range repo.Calls {
+		if call.Method == "AddEvent" {
+			stored = append(stored, call.Arguments.Get(1).(Notification))
+		}
+	}
+
+	require.Len(t, stored, 2)
+	assert.Equal(t, scaled, stored[0])
+	assert.Equal(t, failed, stored[1])
+}
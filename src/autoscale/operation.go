@@ -0,0 +1,243 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"pkg/ctxutil"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// OperationState is the state of a scale operation.
+type OperationState string
+
+const (
+	// OperationPlanned is an operation that has been recorded, but not started.
+	OperationPlanned OperationState = "planned"
+	// OperationCreating is an operation which is creating resources.
+	OperationCreating OperationState = "creating"
+	// OperationDeleting is an operation which is deleting resources.
+	OperationDeleting OperationState = "deleting"
+	// OperationTagging is an operation which is tagging newly created resources.
+	OperationTagging OperationState = "tagging"
+	// OperationRegistering is an operation which is adding new resources to a load balancer
+	// once they are healthy. It is advanced each time its group is checked, so its group
+	// isn't held up while resources boot.
+	OperationRegistering OperationState = "registering"
+	// OperationNotifyingMetrics is an operation which is updating the metrics configuration.
+	OperationNotifyingMetrics OperationState = "notifying-metrics"
+	// OperationWarming is an operation waiting for new resources to warm up.
+	OperationWarming OperationState = "warming"
+	// OperationDone is an operation that completed successfully.
+	OperationDone OperationState = "done"
+	// OperationFailed is an operation that did not complete.
+	OperationFailed OperationState = "failed"
+)
+
+// IsFinished returns true if the operation will not change state again.
+func (s OperationState) IsFinished() bool {
+	return s == OperationDone || s == OperationFailed
+}
+
+// Operation is a persisted record of a scale action for a group.
+type Operation struct {
+	ID             string          `json:"id" db:"id"`
+	GroupID        string          `json:"groupID" db:"group_id"`
+	State          OperationState  `json:"state" db:"state"`
+	RequestedDelta int             `json:"requestedDelta" db:"requested_delta"`
+	ActualDelta    int             `json:"actualDelta" db:"actual_delta"`
+	DropletIDs     DropletIDs      `json:"dropletIDs" db:"droplet_ids"`
+	Errors         OperationErrors `json:"errors" db:"errors"`
+	HealthPasses   HealthPasses    `json:"healthPasses" db:"health_passes"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updatedAt" db:"updated_at"`
+
+	// Keep holds droplets a scale down must not remove, and Teardown lets a scale down
+	// remove droplets from regions at their minimum. Neither is stored, since
+	// interrupted scale downs aren't continued.
+	Keep     []int `json:"-" db:"-"`
+	Teardown bool  `json:"-" db:"-"`
+}
+
+// NewOperation creates an instance of Operation in the planned state.
+func NewOperation(groupID string, delta int) *Operation {
+	now := time.Now().UTC()
+
+	return &Operation{
+		GroupID:        groupID,
+		State:          OperationPlanned,
+		RequestedDelta: delta,
+		DropletIDs:     DropletIDs{},
+		Errors:         OperationErrors{},
+		HealthPasses:   HealthPasses{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// AddError records an error for the operation.
+func (o *Operation) AddError(err error) {
+	o.Errors = append(o.Errors, err.Error())
+}
+
+// AddDropletID records a droplet which was created or deleted by the operation.
+func (o *Operation) AddDropletID(id int) {
+	o.DropletIDs = append(o.DropletIDs, id)
+}
+
+// Transition moves the operation to a new state and persists it.
+func (o *Operation) Transition(ctx context.Context, repo Repository, state OperationState) error {
+	if o.State.IsFinished() {
+		return fmt.Errorf("operation %s is %s and can't move to %s", o.ID, o.State, state)
+	}
+
+	o.State = state
+	o.UpdatedAt = time.Now().UTC()
+
+	return repo.SaveOperation(ctx, *o)
+}
+
+// Fail moves the operation to the failed state and records err.
+func (o *Operation) Fail(ctx context.Context, repo Repository, err error) error {
+	o.AddError(err)
+	return o.Transition(ctx, repo, OperationFailed)
+}
+
+// DropletIDs is a slice of droplet ids.
+type DropletIDs []int
+
+// Value converts droplet ids to JSON to be stored in the database.
+func (d DropletIDs) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan converts a DB value back into DropletIDs.
+func (d *DropletIDs) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, d)
+}
+
+// HealthPasses is the amount of health checks in a row each droplet waiting to be added
+// to a load balancer has passed.
+type HealthPasses map[int]int
+
+// Value converts health passes to JSON to be stored in the database.
+func (h HealthPasses) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(h)
+}
+
+// Scan converts a DB value back into HealthPasses.
+func (h *HealthPasses) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, h)
+}
+
+// OperationErrors is a slice of error messages for an operation.
+type OperationErrors []string
+
+// Value converts operation errors to JSON to be stored in the database.
+func (e OperationErrors) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Scan converts a DB value back into OperationErrors.
+func (e *OperationErrors) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, e)
+}
+
+// ResumeOperations finds operations that were interrupted and either finishes them
+// or rolls them back. It should be run before the scheduler is started.
+func ResumeOperations(ctx context.Context, repo Repository) error {
+	log := ctxutil.LogFromContext(ctx).WithField("action", "resume-operations")
+
+	ops, err := repo.ListUnfinishedOperations(ctx)
+	if err != nil {
+		log.WithError(err).Error("unable to list unfinished operations")
+		return err
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		opLog := log.WithFields(logrus.Fields{
+			"operation-id": op.ID,
+			"group-id":     op.GroupID,
+			"state":        op.State,
+		})
+
+		opLog.Info("resuming operation")
+		if err := resumeOperation(ctx, repo, op); err != nil {
+			opLog.WithError(err).Error("unable to resume operation")
+			if err := op.Fail(ctx, repo, err); err != nil {
+				opLog.WithError(err).Error("unable to mark operation as failed")
+			}
+		}
+	}
+
+	return nil
+}
+
+func resumeOperation(ctx context.Context, repo Repository, op *Operation) error {
+	if op.State == OperationPlanned {
+		return fmt.Errorf("operation was interrupted before it started")
+	}
+
+	group, err := repo.GetGroup(ctx, op.GroupID)
+	if err != nil {
+		return err
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		return err
+	}
+
+	switch op.State {
+	case OperationRegistering:
+		// registrations are advanced when the group is checked.
+		return nil
+
+	case OperationCreating, OperationDeleting, OperationTagging:
+		if err := resource.Resume(ctx, *group, op, repo); err != nil {
+			return err
+		}
+
+		if op.State == OperationRegistering {
+			return group.MetricNotify()
+		}
+
+		if err := op.Transition(ctx, repo, OperationNotifyingMetrics); err != nil {
+			return err
+		}
+
+		fallthrough
+	case OperationNotifyingMetrics:
+		if err := group.MetricNotify(); err != nil {
+			return err
+		}
+	}
+
+	return op.Transition(ctx, repo, OperationDone)
+}
@@ -0,0 +1,83 @@
+package autoscale
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestOperation_Transition(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockRepository{}
+	repo.On("SaveOperation", ctx, mock.AnythingOfType("autoscale.Operation")).Return(nil)
+
+	op := NewOperation("id", 2)
+	require.Equal(t, OperationPlanned, op.State)
+
+	require.NoError(t, op.Transition(ctx, repo, OperationCreating))
+	require.NoError(t, op.Transition(ctx, repo, OperationDone))
+
+	err := op.Transition(ctx, repo, OperationWarming)
+	assert.Error(t, err)
+	assert.Equal(t, OperationDone, op.State)
+}
+
+func TestResumeOperations(t *testing.T) {
+	ogFactory := ResourceManagerFactory
+	ogDefaultConfig := DefaultConfig
+	defer func() {
+		ResourceManagerFactory = ogFactory
+		DefaultConfig = ogDefaultConfig
+	}()
+
+	tmpPath, err := ioutil.TempDir("", "autoscaler")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpPath)
+
+	DefaultConfig[OptionFileLoadPath] = tmpPath
+
+	ctx := context.Background()
+	RegisterOfflineMetrics(ctx)
+
+	ResourceManagerFactory = func(g *Group) (ResourceManager, error) {
+		return NewLocalResource(ctx), nil
+	}
+
+	group := &Group{ID: "group-id", Name: "group", MetricType: "load"}
+
+	ops := []Operation{
+		{ID: "1", GroupID: "group-id", State: OperationPlanned},
+		{ID: "2", GroupID: "group-id", State: OperationTagging},
+		{ID: "3", GroupID: "group-id", State: OperationWarming},
+		{ID: "4", GroupID: "group-id", State: OperationRegistering},
+	}
+
+	repo := &MockRepository{}
+	repo.On("ListUnfinishedOperations", ctx).Return(ops, nil)
+	repo.On("GetGroup", ctx, "group-id").Return(group, nil)
+	repo.On("SaveOperation", ctx, mock.AnythingOfType("autoscale.Operation")).Return(nil)
+
+	err = ResumeOperations(ctx, repo)
+	require.NoError(t, err)
+
+	final := map[string]OperationState{}
+	for _, call := range repo.Calls {
+		if call.Method == "SaveOperation" {
+			op := call.Arguments.Get(1).(Operation)
+			final[op.ID] = op.State
+		}
+	}
+
+	assert.Equal(t, OperationFailed, final["1"])
+	assert.Equal(t, OperationDone, final["2"])
+	assert.Equal(t, OperationDone, final["3"])
+
+	_, saved := final["4"]
+	assert.False(t, saved, "registering operations are advanced when their group is checked")
+}
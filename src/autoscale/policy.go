@@ -23,6 +23,8 @@ type PolicyConfig map[string]interface{}
 // Policy determine how many resources there should be at the current point in time.
 type Policy interface {
 	CalculateSize(resourceCount int, value float64) int
+	Clamp(size int) int
+	Trigger(value float64) (float64, string)
 	WarmUpPeriod() time.Duration
 	Config() PolicyConfig
 	MarshalJSON() ([]byte, error)
@@ -130,15 +132,43 @@ func (p *ValuePolicy) CalculateSize(resourceCount int, value float64) int {
 		newCount = newCount + p.vpd.ScaleUpBy
 	}
 
-	if newCount <= p.vpd.MinSize {
+	return p.clamp(newCount)
+}
+
+// Clamp limits size to the policy's minimum and maximum size.
+func (p *ValuePolicy) Clamp(size int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.clamp(size)
+}
+
+func (p *ValuePolicy) clamp(size int) int {
+	if size <= p.vpd.MinSize {
 		return p.vpd.MinSize
 	}
 
-	if newCount > p.vpd.MaxSize {
+	if size > p.vpd.MaxSize {
 		return p.vpd.MaxSize
 	}
 
-	return newCount
+	return size
+}
+
+// Trigger returns the threshold value crossed and how it was crossed, ">=" for the scale
+// up value and "<=" for the scale down value. The comparison is empty if value is
+// between them.
+func (p *ValuePolicy) Trigger(value float64) (float64, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if value <= p.vpd.ScaleDownValue {
+		return p.vpd.ScaleDownValue, "<="
+	} else if value >= p.vpd.ScaleUpValue {
+		return p.vpd.ScaleUpValue, ">="
+	}
+
+	return 0, ""
 }
 
 // WarmUpPeriod is the time needed for the new service to warm up. No checks should happen in this period.
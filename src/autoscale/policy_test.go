@@ -31,3 +31,46 @@ func TestValuePolicy(t *testing.T) {
 		assert.Equal(t, c.expected, v, fmt.Sprintf("case: %#v\n", c))
 	}
 }
+
+func TestValuePolicy_Trigger(t *testing.T) {
+	cases := []struct {
+		value      float64
+		threshold  float64
+		comparison string
+	}{
+		{value: 0.5, threshold: 0, comparison: ""},
+		{value: 0.2, threshold: 0.2, comparison: "<="},
+		{value: 1.2, threshold: 0.8, comparison: ">="},
+	}
+
+	vp, err := NewValuePolicy(ValuePolicyScale(
+		1, 10, 0.8, 3, 0.2, 2,
+	))
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		threshold, comparison := vp.Trigger(c.value)
+		assert.Equal(t, c.threshold, threshold, fmt.Sprintf("case: %#v\n", c))
+		assert.Equal(t, c.comparison, comparison, fmt.Sprintf("case: %#v\n", c))
+	}
+}
+
+func TestValuePolicy_Clamp(t *testing.T) {
+	cases := []struct {
+		size     int
+		expected int
+	}{
+		{size: 0, expected: 1},
+		{size: 5, expected: 5},
+		{size: 11, expected: 10},
+	}
+
+	vp, err := NewValuePolicy(ValuePolicyScale(
+		1, 10, 0.8, 3, 0.2, 2,
+	))
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, vp.Clamp(c.size), fmt.Sprintf("case: %#v\n", c))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"pkg/ctxutil"
+	"sort"
 	"time"
 
 	"golang.org/x/net/context"
@@ -104,18 +105,40 @@ func (l *PrometheusLoad) Measure(ctx context.Context, groupName string) (float64
 // Update updates the prometheus config for a group.
 func (l *PrometheusLoad) Update(groupID string, resourceAllocations []ResourceAllocation) error {
 	l.log.WithField("group", groupID).Info("updating prometheus")
-	tg := targetGroup{
-		Labels: map[string]string{
-			"group": groupID,
-		},
-	}
-
+	// targets are grouped by size and region so per-instance metrics are labelled with
+	// the size the droplet was actually created with.
+	byKey := map[string]*targetGroup{}
+	keys := []string{}
 	for _, allocation := range resourceAllocations {
-		target := fmt.Sprintf("%s:%d", allocation.Address, prometheusAgentPort)
+		key := allocation.Size + "/" + allocation.Region
+		tg, ok := byKey[key]
+		if !ok {
+			tg = &targetGroup{Labels: map[string]string{"group": groupID}}
+			if allocation.Size != "" {
+				tg.Labels["size"] = allocation.Size
+			}
+			if allocation.Region != "" {
+				tg.Labels["region"] = allocation.Region
+			}
+
+			byKey[key] = tg
+			keys = append(keys, key)
+		}
+
+		port := allocation.AgentPort
+		if port == 0 {
+			port = DefaultAgentPort
+		}
+
+		target := fmt.Sprintf("%s:%d", allocation.Address, port)
 		tg.Targets = append(tg.Targets, target)
 	}
 
-	targetGroups := []targetGroup{tg}
+	sort.Strings(keys)
+	targetGroups := []targetGroup{}
+	for _, key := range keys {
+		targetGroups = append(targetGroups, *byKey[key])
+	}
 
 	path := l.targetJSONPath(groupID)
 	if err := os.MkdirAll(l.configDir, 0755); err != nil {
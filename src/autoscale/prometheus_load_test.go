@@ -0,0 +1,50 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusLoad_Update(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prometheus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := &PrometheusLoad{
+		log:       logrus.WithField("test", "prometheus-load"),
+		configDir: dir,
+	}
+
+	allocations := []ResourceAllocation{
+		{Address: "10.0.0.1", Size: "1gb", Region: "nyc1"},
+		{Address: "10.0.0.2", Size: "2gb", Region: "nyc1", AgentPort: 9200},
+		{Address: "10.0.0.3", Size: "1gb", Region: "nyc1"},
+	}
+
+	require.NoError(t, l.Update("group-1", allocations))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "group-1.json"))
+	require.NoError(t, err)
+
+	var targetGroups []targetGroup
+	require.NoError(t, json.Unmarshal(b, &targetGroups))
+
+	expected := []targetGroup{
+		{
+			Targets: []string{"10.0.0.1:9100", "10.0.0.3:9100"},
+			Labels:  map[string]string{"group": "group-1", "size": "1gb", "region": "nyc1"},
+		},
+		{
+			Targets: []string{"10.0.0.2:9200"},
+			Labels:  map[string]string{"group": "group-1", "size": "2gb", "region": "nyc1"},
+		},
+	}
+	assert.Equal(t, expected, targetGroups)
+}
@@ -0,0 +1,260 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"pkg/ctxutil"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// RefreshState is the state of an instance refresh.
+type RefreshState string
+
+const (
+	// RefreshRunning is a refresh which is replacing resources.
+	RefreshRunning RefreshState = "running"
+	// RefreshPaused is a refresh which will not start another batch until it is resumed.
+	RefreshPaused RefreshState = "paused"
+	// RefreshCancelled is a refresh which was stopped before it replaced all resources.
+	RefreshCancelled RefreshState = "cancelled"
+	// RefreshRollingBack is a refresh which is replacing resources with the previous template.
+	RefreshRollingBack RefreshState = "rolling-back"
+	// RefreshRolledBack is a refresh which was rolled back.
+	RefreshRolledBack RefreshState = "rolled-back"
+	// RefreshDone is a refresh which replaced all resources.
+	RefreshDone RefreshState = "done"
+	// RefreshFailed is a refresh which did not complete.
+	RefreshFailed RefreshState = "failed"
+)
+
+var refreshTransitions = map[RefreshState][]RefreshState{
+	RefreshRunning:     {RefreshPaused, RefreshCancelled, RefreshRollingBack, RefreshDone, RefreshFailed},
+	RefreshPaused:      {RefreshRunning, RefreshCancelled, RefreshRollingBack},
+	RefreshCancelled:   {RefreshRollingBack},
+	RefreshRollingBack: {RefreshPaused, RefreshCancelled, RefreshRolledBack, RefreshFailed},
+}
+
+// IsActive returns true if resources are being replaced in this state.
+func (s RefreshState) IsActive() bool {
+	return s == RefreshRunning || s == RefreshRollingBack
+}
+
+// IsFinished returns true if the refresh will not replace any more resources unless
+// it is rolled back.
+func (s RefreshState) IsFinished() bool {
+	return s == RefreshCancelled || s == RefreshRolledBack || s == RefreshDone || s == RefreshFailed
+}
+
+// Refresh is a rolling replacement of a group's resources with resources built from
+// the group's current template.
+type Refresh struct {
+	ID                      string          `json:"id" db:"id"`
+	GroupID                 string          `json:"groupID" db:"group_id"`
+	State                   RefreshState    `json:"state" db:"state"`
+	TemplateID              string          `json:"templateID" db:"template_id"`
+	TemplateVersion         int             `json:"templateVersion" db:"template_version"`
+	PreviousTemplateID      string          `json:"previousTemplateID" db:"previous_template_id"`
+	PreviousTemplateVersion int             `json:"previousTemplateVersion" db:"previous_template_version"`
+	MinHealthyPercent       int             `json:"minHealthyPercent" db:"min_healthy_percent"`
+	BatchSize               int             `json:"batchSize" db:"batch_size"`
+	ReplacedIDs             ResourceIDs     `json:"replacedIDs" db:"replaced_ids"`
+	CreatedIDs              ResourceIDs     `json:"createdIDs" db:"created_ids"`
+	Errors                  OperationErrors `json:"errors" db:"errors"`
+	CreatedAt               time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// NewRefresh creates an instance of Refresh in the running state which replaces resources
+// with ones built from the target template version. The batch size is the amount of
+// resources which can be replaced at once while keeping minHealthyPercent of count
+// resources in service.
+func NewRefresh(groupID string, target, previous Template, count, minHealthyPercent int) (*Refresh, error) {
+	if minHealthyPercent < 0 || minHealthyPercent > 100 {
+		return nil, fmt.Errorf("minHealthyPercent (%d) must be between 0 and 100", minHealthyPercent)
+	}
+
+	minHealthy := int(math.Ceil(float64(count*minHealthyPercent) / 100))
+	batchSize := count - minHealthy
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	now := time.Now().UTC()
+
+	return &Refresh{
+		GroupID:                 groupID,
+		State:                   RefreshRunning,
+		TemplateID:              target.ID,
+		TemplateVersion:         target.Version,
+		PreviousTemplateID:      previous.ID,
+		PreviousTemplateVersion: previous.Version,
+		MinHealthyPercent:       minHealthyPercent,
+		BatchSize:               batchSize,
+		ReplacedIDs:             ResourceIDs{},
+		CreatedIDs:              ResourceIDs{},
+		Errors:                  OperationErrors{},
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}, nil
+}
+
+// TargetTemplate returns the template and version resources are being replaced with.
+func (r *Refresh) TargetTemplate() (string, int) {
+	if r.State == RefreshRollingBack {
+		return r.PreviousTemplateID, r.PreviousTemplateVersion
+	}
+
+	return r.TemplateID, r.TemplateVersion
+}
+
+// Transition moves the refresh to a new state and persists it.
+func (r *Refresh) Transition(ctx context.Context, repo Repository, state RefreshState) error {
+	allowed := false
+	for _, s := range refreshTransitions[r.State] {
+		if s == state {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return ErrInvalidRefreshTransition
+	}
+
+	if state == RefreshRollingBack && r.PreviousTemplateID == "" {
+		return ErrInvalidRefreshTransition
+	}
+
+	r.State = state
+	r.UpdatedAt = time.Now().UTC()
+
+	return repo.UpdateRefreshState(ctx, r.ID, state)
+}
+
+// Fail moves the refresh to the failed state and records err.
+func (r *Refresh) Fail(ctx context.Context, repo Repository, err error) error {
+	r.Errors = append(r.Errors, err.Error())
+	if saveErr := repo.SaveRefreshProgress(ctx, *r); saveErr != nil {
+		return saveErr
+	}
+
+	return r.Transition(ctx, repo, RefreshFailed)
+}
+
+// ResourceIDs is a slice of resource ids.
+type ResourceIDs []string
+
+// Value converts resource ids to JSON to be stored in the database.
+func (ids ResourceIDs) Value() (driver.Value, error) {
+	return json.Marshal(ids)
+}
+
+// Scan converts a DB value back into ResourceIDs.
+func (ids *ResourceIDs) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, ids)
+}
+
+// previousTemplate returns the template version most resources in allocations were
+// built from, ignoring resources built from target.
+func previousTemplate(allocations []ResourceAllocation, target Template) Template {
+	counts := map[string]int{}
+	previous := Template{}
+	best := 0
+	for _, a := range allocations {
+		if a.TemplateID == "" || (a.TemplateID == target.ID && a.TemplateVersion == target.Version) {
+			continue
+		}
+
+		key := templateTag(a.TemplateID, a.TemplateVersion)
+		counts[key]++
+		if counts[key] > best {
+			best = counts[key]
+			previous = Template{ID: a.TemplateID, Version: a.TemplateVersion}
+		}
+	}
+
+	return previous
+}
+
+// StartRefresh creates a refresh which replaces the resources in the group identified
+// by groupID that weren't built from the group's current template. Only one refresh
+// can be in progress for a group.
+func StartRefresh(ctx context.Context, repo Repository, groupID string, minHealthyPercent int) (*Refresh, error) {
+	refreshes, err := repo.ListUnfinishedRefreshes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range refreshes {
+		if r.GroupID == groupID {
+			return nil, ErrRefreshInProgress
+		}
+	}
+
+	group, err := repo.GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := group.Resource()
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := resource.Allocated()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := repo.GetTemplateVersion(ctx, group.TemplateID, group.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	target := Template{ID: tmpl.ID, Version: tmpl.Version}
+	previous := previousTemplate(allocations, target)
+	refresh, err := NewRefresh(group.ID, target, previous, len(allocations), minHealthyPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.CreateRefresh(ctx, *refresh)
+}
+
+// ResumeRefreshes requests the scheduler to continue refreshes which were running when
+// autoscale stopped. It should be run after the scheduler is started.
+func ResumeRefreshes(ctx context.Context, repo Repository, status *SchedulerStatus) error {
+	log := ctxutil.LogFromContext(ctx).WithField("action", "resume-refreshes")
+
+	refreshes, err := repo.ListUnfinishedRefreshes(ctx)
+	if err != nil {
+		log.WithError(err).Error("unable to list unfinished refreshes")
+		return err
+	}
+
+	for _, r := range refreshes {
+		if !r.State.IsActive() {
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"refresh-id": r.ID,
+			"group-id":   r.GroupID,
+			"state":      r.State,
+		}).Info("resuming refresh")
+		status.Request <- RefreshGroupRequest(r.GroupID, r.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestNewRefresh(t *testing.T) {
+	cases := []struct {
+		count             int
+		minHealthyPercent int
+		batchSize         int
+	}{
+		{count: 4, minHealthyPercent: 50, batchSize: 2},
+		{count: 5, minHealthyPercent: 90, batchSize: 1},
+		{count: 5, minHealthyPercent: 100, batchSize: 1},
+		{count: 5, minHealthyPercent: 0, batchSize: 5},
+	}
+
+	for _, c := range cases {
+		r, err := NewRefresh("id", Template{ID: "new", Version: 1}, Template{ID: "old", Version: 1}, c.count, c.minHealthyPercent)
+		require.NoError(t, err)
+		assert.Equal(t, c.batchSize, r.BatchSize)
+		assert.Equal(t, RefreshRunning, r.State)
+	}
+
+	_, err := NewRefresh("id", Template{}, Template{}, 3, 101)
+	require.Error(t, err)
+}
+
+func TestRefresh_Transition(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockRepository{}
+	repo.On("UpdateRefreshState", ctx, "refresh-id", mock.AnythingOfType("autoscale.RefreshState")).Return(nil)
+
+	r, err := NewRefresh("id", Template{ID: "tmpl", Version: 2}, Template{ID: "tmpl", Version: 1}, 3, 50)
+	require.NoError(t, err)
+	r.ID = "refresh-id"
+
+	require.NoError(t, r.Transition(ctx, repo, RefreshPaused))
+	require.NoError(t, r.Transition(ctx, repo, RefreshRollingBack))
+	id, version := r.TargetTemplate()
+	require.Equal(t, "tmpl", id)
+	require.Equal(t, 1, version)
+	require.NoError(t, r.Transition(ctx, repo, RefreshRolledBack))
+
+	err = r.Transition(ctx, repo, RefreshRunning)
+	require.Equal(t, ErrInvalidRefreshTransition, err)
+	require.Equal(t, RefreshRolledBack, r.State)
+
+	r, err = NewRefresh("id", Template{ID: "tmpl", Version: 2}, Template{}, 3, 50)
+	require.NoError(t, err)
+	require.Equal(t, ErrInvalidRefreshTransition, r.Transition(ctx, repo, RefreshRollingBack))
+}
+
+func TestPreviousTemplate(t *testing.T) {
+	allocations := []ResourceAllocation{
+		{ID: "1", TemplateID: "a", TemplateVersion: 3},
+		{ID: "2", TemplateID: "a", TemplateVersion: 1},
+		{ID: "3", TemplateID: "b", TemplateVersion: 1},
+		{ID: "4", TemplateID: "b", TemplateVersion: 1},
+		{ID: "5"},
+	}
+
+	target := Template{ID: "a", Version: 3}
+
+	previous := previousTemplate(allocations, target)
+	require.Equal(t, "b", previous.ID)
+	require.Equal(t, 1, previous.Version)
+
+	previous = previousTemplate(allocations[:1], target)
+	require.Equal(t, "", previous.ID)
+}
@@ -0,0 +1,265 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/digitalocean/godo"
+)
+
+var (
+	// ErrNoRegionAvailable is returned when no region of a multi-region group can take
+	// another droplet.
+	ErrNoRegionAvailable = fmt.Errorf("no region is available")
+
+	// capacityMessages are fragments of DigitalOcean API errors which mean a region can't
+	// create the droplet right now.
+	capacityMessages = []string{"capacity", "unavailable", "not available", "disabled"}
+)
+
+// GroupRegion is a region a group spreads droplets across. Weight is the share of the
+// group the region should hold relative to the other regions and defaults to 1. Min
+// and Max bound the number of droplets in the region. A Max of 0 means no limit.
+type GroupRegion struct {
+	Region string `json:"region"`
+	Weight int    `json:"weight"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max"`
+}
+
+func (gr GroupRegion) weight() int {
+	if gr.Weight == 0 {
+		return 1
+	}
+
+	return gr.Weight
+}
+
+func (gr GroupRegion) hasRoom(count int) bool {
+	return gr.Max == 0 || count < gr.Max
+}
+
+// GroupRegions are the regions of a multi-region group. A group without regions creates
+// droplets in its template's region.
+type GroupRegions []GroupRegion
+
+// IsValid returns if the regions are valid or not.
+func (gr GroupRegions) IsValid() bool {
+	seen := map[string]bool{}
+	for _, r := range gr {
+		if r.Region == "" || seen[r.Region] {
+			return false
+		}
+
+		if r.Weight < 0 || r.Min < 0 || r.Max < 0 {
+			return false
+		}
+
+		if r.Max > 0 && r.Min > r.Max {
+			return false
+		}
+
+		seen[r.Region] = true
+	}
+
+	return true
+}
+
+// holds returns true if the regions have room for size droplets. Groups without regions
+// or with a region without a maximum have no limit.
+func (gr GroupRegions) holds(size int) bool {
+	if len(gr) == 0 {
+		return true
+	}
+
+	total := 0
+	for _, r := range gr {
+		if r.Max == 0 {
+			return true
+		}
+
+		total += r.Max
+	}
+
+	return total >= size
+}
+
+// Value converts group regions to JSON to be stored in the database.
+func (gr GroupRegions) Value() (driver.Value, error) {
+	if gr == nil {
+		gr = GroupRegions{}
+	}
+
+	return json.Marshal(gr)
+}
+
+// Scan converts a DB value back into GroupRegions.
+func (gr *GroupRegions) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, gr)
+}
+
+// grow returns the region the next droplet should be created in. Regions below their
+// minimum are filled first. Otherwise the region with the fewest droplets relative to
+// its weight, which hasn't reached its maximum, is picked. Excluded regions are skipped.
+func (gr GroupRegions) grow(counts map[string]int, exclude map[string]bool) (string, bool) {
+	for _, r := range gr {
+		if !exclude[r.Region] && counts[r.Region] < r.Min && r.hasRoom(counts[r.Region]) {
+			return r.Region, true
+		}
+	}
+
+	best := -1
+	for i, r := range gr {
+		if exclude[r.Region] || !r.hasRoom(counts[r.Region]) {
+			continue
+		}
+
+		if best == -1 {
+			best = i
+			continue
+		}
+
+		b := gr[best]
+		if (counts[r.Region]+1)*b.weight() < (counts[b.Region]+1)*r.weight() {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return "", false
+	}
+
+	return gr[best].Region, true
+}
+
+// shrink returns the region a droplet should be removed from. Droplets in regions the
+// group no longer spans are removed first. Otherwise the region with the most droplets
+// relative to its weight, which is above its minimum, is picked. Only regions in
+// candidates are considered.
+func (gr GroupRegions) shrink(counts map[string]int, candidates map[string]bool) (string, bool) {
+	known := map[string]GroupRegion{}
+	for _, r := range gr {
+		known[r.Region] = r
+	}
+
+	for region := range candidates {
+		if _, ok := known[region]; !ok {
+			return region, true
+		}
+	}
+
+	best := -1
+	for i, r := range gr {
+		if !candidates[r.Region] || counts[r.Region] <= r.Min {
+			continue
+		}
+
+		if best == -1 {
+			best = i
+			continue
+		}
+
+		b := gr[best]
+		if counts[r.Region]*b.weight() > counts[b.Region]*r.weight() {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return "", false
+	}
+
+	return gr[best].Region, true
+}
+
+// regionPlacer assigns regions to droplets as a multi-region group scales up. It is safe
+// for concurrent use.
+type regionPlacer struct {
+	mu      sync.Mutex
+	regions GroupRegions
+	counts  map[string]int
+	failed  map[string]bool
+}
+
+func newRegionPlacer(regions GroupRegions, counts map[string]int) *regionPlacer {
+	c := map[string]int{}
+	for k, v := range counts {
+		c[k] = v
+	}
+
+	return &regionPlacer{
+		regions: regions,
+		counts:  c,
+		failed:  map[string]bool{},
+	}
+}
+
+// next reserves a region for a droplet.
+func (p *regionPlacer) next() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	region, ok := p.regions.grow(p.counts, p.failed)
+	if !ok {
+		return "", ErrNoRegionAvailable
+	}
+
+	p.counts[region]++
+	return region, nil
+}
+
+// fallback releases the reservation in a region which has no capacity and reserves
+// another region. The failed region is not picked again.
+func (p *regionPlacer) fallback(region string) (string, error) {
+	p.mu.Lock()
+	p.counts[region]--
+	p.failed[region] = true
+	p.mu.Unlock()
+
+	return p.next()
+}
+
+// isCapacityError returns true if err means a region can't create droplets right now.
+func isCapacityError(err error) bool {
+	er, ok := err.(*godo.ErrorResponse)
+	if !ok || er.Response == nil {
+		return false
+	}
+
+	switch er.Response.StatusCode {
+	case http.StatusUnprocessableEntity, http.StatusServiceUnavailable:
+	default:
+		return false
+	}
+
+	msg := strings.ToLower(er.Message)
+	for _, m := range capacityMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// regionCounts returns the number of allocated resources in each region.
+func regionCounts(allocations []ResourceAllocation) map[string]int {
+	counts := map[string]int{}
+	for _, a := range allocations {
+		if a.Region != "" {
+			counts[a.Region]++
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,116 @@
+package autoscale
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupRegions_IsValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		regions GroupRegions
+		valid   bool
+	}{
+		{name: "empty", regions: GroupRegions{}, valid: true},
+		{name: "valid", regions: GroupRegions{{Region: "nyc1", Weight: 2, Min: 1, Max: 5}, {Region: "sfo2"}}, valid: true},
+		{name: "missing region", regions: GroupRegions{{Weight: 1}}},
+		{name: "duplicate region", regions: GroupRegions{{Region: "nyc1"}, {Region: "nyc1"}}},
+		{name: "negative weight", regions: GroupRegions{{Region: "nyc1", Weight: -1}}},
+		{name: "min above max", regions: GroupRegions{{Region: "nyc1", Min: 3, Max: 2}}},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.valid, c.regions.IsValid(), c.name)
+	}
+}
+
+func TestGroupRegions_Grow(t *testing.T) {
+	regions := GroupRegions{
+		{Region: "nyc1", Weight: 2},
+		{Region: "sfo2", Weight: 1},
+		{Region: "lon1", Min: 1, Max: 1},
+	}
+
+	counts := map[string]int{}
+	picked := []string{}
+	for i := 0; i < 7; i++ {
+		region, ok := regions.grow(counts, nil)
+		assert.True(t, ok)
+		counts[region]++
+		picked = append(picked, region)
+	}
+
+	assert.Equal(t, []string{"lon1", "nyc1", "nyc1", "sfo2", "nyc1", "nyc1", "sfo2"}, picked)
+	assert.Equal(t, map[string]int{"nyc1": 4, "sfo2": 2, "lon1": 1}, counts)
+
+	region, ok := regions.grow(counts, map[string]bool{"nyc1": true})
+	assert.True(t, ok)
+	assert.Equal(t, "sfo2", region)
+
+	_, ok = regions.grow(counts, map[string]bool{"nyc1": true, "sfo2": true})
+	assert.False(t, ok)
+}
+
+func TestGroupRegions_Shrink(t *testing.T) {
+	regions := GroupRegions{
+		{Region: "nyc1", Weight: 2},
+		{Region: "sfo2", Min: 1},
+	}
+
+	all := map[string]bool{"nyc1": true, "sfo2": true, "ams3": true}
+
+	region, ok := regions.shrink(map[string]int{"nyc1": 2, "sfo2": 2, "ams3": 1}, all)
+	assert.True(t, ok)
+	assert.Equal(t, "ams3", region)
+
+	delete(all, "ams3")
+	region, ok = regions.shrink(map[string]int{"nyc1": 2, "sfo2": 2}, all)
+	assert.True(t, ok)
+	assert.Equal(t, "sfo2", region)
+
+	region, ok = regions.shrink(map[string]int{"nyc1": 2, "sfo2": 1}, all)
+	assert.True(t, ok)
+	assert.Equal(t, "nyc1", region)
+
+	_, ok = regions.shrink(map[string]int{"nyc1": 0, "sfo2": 1}, all)
+	assert.False(t, ok)
+}
+
+func TestRegionPlacer_Fallback(t *testing.T) {
+	p := newRegionPlacer(GroupRegions{{Region: "nyc1"}, {Region: "sfo2"}}, map[string]int{"sfo2": 1})
+
+	region, err := p.next()
+	assert.NoError(t, err)
+	assert.Equal(t, "nyc1", region)
+
+	region, err = p.fallback(region)
+	assert.NoError(t, err)
+	assert.Equal(t, "sfo2", region)
+
+	region, err = p.next()
+	assert.NoError(t, err)
+	assert.Equal(t, "sfo2", region)
+
+	_, err = p.fallback(region)
+	assert.Equal(t, ErrNoRegionAvailable, err)
+
+	assert.Equal(t, map[string]int{"nyc1": 0, "sfo2": 2}, p.counts)
+}
+
+func TestIsCapacityError(t *testing.T) {
+	errorResponse := func(status int, message string) error {
+		return &godo.ErrorResponse{
+			Response: &http.Response{StatusCode: status},
+			Message:  message,
+		}
+	}
+
+	assert.True(t, isCapacityError(errorResponse(http.StatusUnprocessableEntity, "Droplet creation is currently unavailable in this region.")))
+	assert.True(t, isCapacityError(errorResponse(http.StatusServiceUnavailable, "Insufficient capacity")))
+	assert.False(t, isCapacityError(errorResponse(http.StatusUnprocessableEntity, "Name is invalid")))
+	assert.False(t, isCapacityError(errorResponse(http.StatusUnauthorized, "Unable to authenticate you")))
+	assert.False(t, isCapacityError(assert.AnError))
+}
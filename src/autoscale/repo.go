@@ -3,10 +3,12 @@ package autoscale
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-errors/errors"
 	"github.com/jmoiron/sqlx"
+	"github.com/satori/go.uuid"
 
 	"golang.org/x/net/context"
 )
@@ -24,7 +26,10 @@ type Repository interface {
 	CreateTemplate(ctx context.Context, t Template) (*Template, error)
 	GetTemplate(ctx context.Context, name string) (*Template, error)
 	ListTemplates(ctx context.Context) ([]Template, error)
+	UpdateTemplate(ctx context.Context, t Template) (*Template, error)
 	DeleteTemplate(ctx context.Context, name string) error
+	GetTemplateVersion(ctx context.Context, id string, version int) (*Template, error)
+	ListTemplateVersions(ctx context.Context, id string) ([]Template, error)
 
 	CreateGroup(ctx context.Context, g Group) (*Group, error)
 	GetGroup(ctx context.Context, name string) (*Group, error)
@@ -37,6 +42,22 @@ type Repository interface {
 	GetGroupStatus(ctx context.Context, groupID string) (*GroupStatus, error)
 	GetGroupHistory(ctx context.Context, groupID string, tr TimeRange) ([]GroupStatus, error)
 
+	CreateOperation(ctx context.Context, op Operation) (*Operation, error)
+	SaveOperation(ctx context.Context, op Operation) error
+	GetOperation(ctx context.Context, id string) (*Operation, error)
+	ListUnfinishedOperations(ctx context.Context) ([]Operation, error)
+
+	CreateRefresh(ctx context.Context, r Refresh) (*Refresh, error)
+	GetRefresh(ctx context.Context, id string) (*Refresh, error)
+	UpdateRefreshState(ctx context.Context, id string, state RefreshState) error
+	SaveRefreshProgress(ctx context.Context, r Refresh) error
+	ListUnfinishedRefreshes(ctx context.Context) ([]Refresh, error)
+
+	AddDeadLetter(ctx context.Context, d DeadLetter) error
+
+	AddEvent(ctx context.Context, n Notification) error
+	ListEvents(ctx context.Context, q EventQuery) ([]Notification, error)
+
 	Close() error
 }
 
@@ -55,7 +76,7 @@ func NewRepository(db *sql.DB) (Repository, error) {
 }
 
 func (r *pgRepo) CreateTemplate(ctx context.Context, t Template) (*Template, error) {
-	if !t.IsValid() {
+	if !t.IsValid() || !validUserData(t.UserData) {
 		return nil, errors.New(ValidationErr)
 	}
 
@@ -67,21 +88,101 @@ func (r *pgRepo) CreateTemplate(ctx context.Context, t Template) (*Template, err
 	}
 
 	err = sqlx.Get(tx, &id, sqlSaveTemplate,
-		t.Name, t.Region, t.Size, t.Image, t.SSHKeys, t.UserData)
+		t.Name, t.Region, t.Size, t.Image, t.SSHKeys, t.UserData,
+		t.PrivateNetworking, t.IPv6, t.Monitoring, t.Backups, t.Tags, t.Volumes, t.Agent, t.FallbackSizes)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
+	t.ID = id
+	t.Version = 1
+
+	if err := createTemplateVersion(tx, t); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
 
-	t.ID = id
 	return &t, nil
 }
 
+// UpdateTemplate saves t as a new version of the template.
+func (r *pgRepo) UpdateTemplate(ctx context.Context, t Template) (*Template, error) {
+	if !t.IsValid() || !validUserData(t.UserData) {
+		return nil, errors.New(ValidationErr)
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	err = sqlx.Get(tx, &version, sqlUpdateTemplate,
+		t.Name, t.Region, t.Size, t.Image, t.SSHKeys, t.UserData,
+		t.PrivateNetworking, t.IPv6, t.Monitoring, t.Backups, t.Tags, t.Volumes, t.Agent, t.FallbackSizes, t.ID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, ObjectMissingErr
+		}
+
+		return nil, err
+	}
+
+	t.Version = version
+
+	if err := createTemplateVersion(tx, t); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func createTemplateVersion(tx *sqlx.Tx, t Template) error {
+	_, err := tx.Exec(sqlCreateTemplateVersion,
+		t.ID, t.Version, t.Region, t.Size, t.Image, t.SSHKeys, t.UserData,
+		t.PrivateNetworking, t.IPv6, t.Monitoring, t.Backups, t.Tags, t.Volumes, t.Agent, t.FallbackSizes, time.Now().UTC())
+	return err
+}
+
+// GetTemplateVersion retrieves a version of a template. Version 0 is the latest version.
+func (r *pgRepo) GetTemplateVersion(ctx context.Context, id string, version int) (*Template, error) {
+	if version == 0 {
+		return r.GetTemplate(ctx, id)
+	}
+
+	var t Template
+	if err := r.db.Get(&t, sqlGetTemplateVersion, id, version); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ObjectMissingErr
+		}
+
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *pgRepo) ListTemplateVersions(ctx context.Context, id string) ([]Template, error) {
+	ts := []Template{}
+	if err := r.db.Select(&ts, sqlListTemplateVersions, id); err != nil {
+		return nil, err
+	}
+
+	return ts, nil
+}
+
 func (r *pgRepo) GetTemplate(ctx context.Context, id string) (*Template, error) {
 	var t Template
 	if err := r.db.Get(&t, sqlGetTemplate, id); err != nil {
@@ -110,6 +211,17 @@ func (r *pgRepo) DeleteTemplate(ctx context.Context, id string) error {
 		return err
 	}
 
+	var groupCount int
+	if err := sqlx.Get(tx, &groupCount, sqlCountTemplateGroups, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if groupCount > 0 {
+		tx.Rollback()
+		return ErrTemplateInUse
+	}
+
 	_, err = tx.Exec(sqlDeleteTemplate, id)
 	if err != nil {
 		tx.Rollback()
@@ -132,7 +244,7 @@ func (r *pgRepo) CreateGroup(ctx context.Context, g Group) (*Group, error) {
 	}
 
 	err = sqlx.Get(tx, &id, sqlCreateGroup,
-		g.Name, g.BaseName, g.TemplateID, g.MetricType, g.Metric, g.PolicyType, g.Policy)
+		g.Name, g.BaseName, g.TemplateID, g.TemplateVersion, g.MetricType, g.Metric, g.PolicyType, g.Policy, g.Vars, g.UserData, g.Regions, g.LoadBalancerID, g.FloatingIP, g.DNS, g.NotificationSinks)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -158,7 +270,7 @@ func (r *pgRepo) SaveGroup(ctx context.Context, g Group) error {
 		return err
 	}
 
-	_, err = tx.Exec(sqlUpdateGroup, g.Metric, g.Policy, g.ID)
+	_, err = tx.Exec(sqlUpdateGroup, g.Metric, g.Policy, g.TemplateID, g.TemplateVersion, g.Vars, g.UserData, g.Regions, g.LoadBalancerID, g.FloatingIP, g.DNS, g.NotificationSinks, g.ID)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -171,9 +283,16 @@ func (r *pgRepo) GetGroup(ctx context.Context, id string) (*Group, error) {
 	row := r.db.QueryRowx(sqlGetGroup, id)
 
 	var name, baseName, templateName, metricType, policyType string
+	var templateVersion int
 	var metric, policy interface{}
-
-	if err := row.Scan(&name, &baseName, &templateName, &metricType, &metric, &policyType, &policy); err != nil {
+	var vars GroupVars
+	var userData string
+	var regions GroupRegions
+	var loadBalancerID, floatingIP string
+	var dns GroupDNS
+	var sinks SinkConfigs
+
+	if err := row.Scan(&name, &baseName, &templateName, &templateVersion, &metricType, &metric, &policyType, &policy, &vars, &userData, &regions, &loadBalancerID, &floatingIP, &dns, &sinks); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ObjectMissingErr
 		}
@@ -182,12 +301,20 @@ func (r *pgRepo) GetGroup(ctx context.Context, id string) (*Group, error) {
 	}
 
 	g := Group{
-		ID:         id,
-		Name:       name,
-		BaseName:   baseName,
-		TemplateID: templateName,
-		MetricType: metricType,
-		PolicyType: policyType,
+		ID:                id,
+		Name:              name,
+		BaseName:          baseName,
+		TemplateID:        templateName,
+		TemplateVersion:   templateVersion,
+		Vars:              vars,
+		UserData:          userData,
+		Regions:           regions,
+		LoadBalancerID:    loadBalancerID,
+		FloatingIP:        floatingIP,
+		DNS:               dns,
+		NotificationSinks: sinks,
+		MetricType:        metricType,
+		PolicyType:        policyType,
 	}
 
 	if err := g.LoadPolicy(policy); err != nil {
@@ -220,19 +347,34 @@ func (r *pgRepo) ListGroups(ctx context.Context) ([]Group, error) {
 
 	for rows.Next() {
 		var id, name, baseName, templateID, metricType, policyType string
+		var templateVersion int
 		var metric, policy interface{}
-
-		if err := rows.Scan(&id, &name, &baseName, &templateID, &metricType, &metric, &policyType, &policy); err != nil {
+		var vars GroupVars
+		var userData string
+		var regions GroupRegions
+		var loadBalancerID, floatingIP string
+		var dns GroupDNS
+		var sinks SinkConfigs
+
+		if err := rows.Scan(&id, &name, &baseName, &templateID, &templateVersion, &metricType, &metric, &policyType, &policy, &vars, &userData, &regions, &loadBalancerID, &floatingIP, &dns, &sinks); err != nil {
 			return nil, err
 		}
 
 		g := Group{
-			ID:         id,
-			Name:       name,
-			BaseName:   baseName,
-			TemplateID: templateID,
-			MetricType: metricType,
-			PolicyType: policyType,
+			ID:                id,
+			Name:              name,
+			BaseName:          baseName,
+			TemplateID:        templateID,
+			TemplateVersion:   templateVersion,
+			Vars:              vars,
+			UserData:          userData,
+			Regions:           regions,
+			LoadBalancerID:    loadBalancerID,
+			FloatingIP:        floatingIP,
+			DNS:               dns,
+			NotificationSinks: sinks,
+			MetricType:        metricType,
+			PolicyType:        policyType,
 		}
 
 		if err := g.LoadPolicy(policy); err != nil {
@@ -278,7 +420,7 @@ func (r *pgRepo) AddGroupStatus(ctx context.Context, g GroupStatus) error {
 	}
 
 	_, err = tx.Exec(sqlCreateGroupStatus,
-		g.GroupID, g.Delta, g.Total, g.CreatedAt)
+		g.GroupID, g.Kind, g.Delta, g.Total, g.Error, g.Decision, g.CreatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -322,8 +464,10 @@ func (r *pgRepo) GetGroupHistory(ctx context.Context, groupID string, tr TimeRan
 	if len(groupStatuses) > 0 {
 		last := groupStatuses[len(groupStatuses)-1]
 		last.CreatedAt = now
+		last.Decision = nil
 		first := groupStatuses[0]
 		first.CreatedAt = then
+		first.Decision = nil
 		groupStatuses = append([]GroupStatus{first}, groupStatuses...)
 		groupStatuses = append(groupStatuses, last)
 
@@ -346,6 +490,258 @@ func (r *pgRepo) GetGroupHistory(ctx context.Context, groupID string, tr TimeRan
 	return groupStatuses, nil
 }
 
+func (r *pgRepo) CreateOperation(ctx context.Context, op Operation) (*Operation, error) {
+	var id string
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	err = sqlx.Get(tx, &id, sqlCreateOperation,
+		op.GroupID, op.State, op.RequestedDelta, op.ActualDelta, op.DropletIDs, op.Errors, op.HealthPasses, op.CreatedAt, op.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	op.ID = id
+	return &op, nil
+}
+
+func (r *pgRepo) SaveOperation(ctx context.Context, op Operation) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlUpdateOperation,
+		op.State, op.ActualDelta, op.DropletIDs, op.Errors, op.HealthPasses, op.UpdatedAt, op.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pgRepo) GetOperation(ctx context.Context, id string) (*Operation, error) {
+	var op Operation
+	if err := r.db.Get(&op, sqlGetOperation, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ObjectMissingErr
+		}
+
+		return nil, err
+	}
+
+	return &op, nil
+}
+
+func (r *pgRepo) ListUnfinishedOperations(ctx context.Context) ([]Operation, error) {
+	ops := []Operation{}
+	if err := r.db.Select(&ops, sqlListUnfinishedOperations, OperationDone, OperationFailed); err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+func (r *pgRepo) CreateRefresh(ctx context.Context, refresh Refresh) (*Refresh, error) {
+	var id string
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	err = sqlx.Get(tx, &id, sqlCreateRefresh,
+		refresh.GroupID, refresh.State, refresh.TemplateID, refresh.TemplateVersion,
+		refresh.PreviousTemplateID, refresh.PreviousTemplateVersion, refresh.MinHealthyPercent, refresh.BatchSize, refresh.ReplacedIDs, refresh.CreatedIDs,
+		refresh.Errors, refresh.CreatedAt, refresh.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	refresh.ID = id
+	return &refresh, nil
+}
+
+func (r *pgRepo) GetRefresh(ctx context.Context, id string) (*Refresh, error) {
+	var refresh Refresh
+	if err := r.db.Get(&refresh, sqlGetRefresh, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ObjectMissingErr
+		}
+
+		return nil, err
+	}
+
+	return &refresh, nil
+}
+
+func (r *pgRepo) UpdateRefreshState(ctx context.Context, id string, state RefreshState) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlUpdateRefreshState, state, time.Now().UTC(), id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pgRepo) SaveRefreshProgress(ctx context.Context, refresh Refresh) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlUpdateRefreshProgress,
+		refresh.ReplacedIDs, refresh.CreatedIDs, refresh.Errors, time.Now().UTC(), refresh.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pgRepo) ListUnfinishedRefreshes(ctx context.Context) ([]Refresh, error) {
+	refreshes := []Refresh{}
+	err := r.db.Select(&refreshes, sqlListUnfinishedRefreshes,
+		RefreshRunning, RefreshPaused, RefreshRollingBack)
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshes, nil
+}
+
+func (r *pgRepo) AddDeadLetter(ctx context.Context, d DeadLetter) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlCreateDeadLetter,
+		d.Sink, d.GroupID, d.NotificationID, []byte(d.Payload), d.Error, d.Attempts, d.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pgRepo) AddEvent(ctx context.Context, n Notification) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlCreateEvent,
+		n.ID, n.GroupID, n.Name, n.Event, n.Kind, n.Delta, n.Count, n.Message, n.IsError, n.Decision, n.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pgRepo) ListEvents(ctx context.Context, q EventQuery) ([]Notification, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.GroupID != "" {
+		conditions = append(conditions, "group_id = "+arg(q.GroupID))
+	}
+
+	if len(q.Events) > 0 {
+		placeholders := []string{}
+		for _, e := range q.Events {
+			placeholders = append(placeholders, arg(e))
+		}
+		conditions = append(conditions, fmt.Sprintf("event IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	if !q.Since.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(q.Since))
+	}
+
+	if !q.Until.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(q.Until))
+	}
+
+	order := "DESC"
+	if q.After != "" {
+		seq, err := r.eventSeq(q.After)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, "seq > "+arg(seq))
+		order = "ASC"
+	} else if q.Before != "" {
+		seq, err := r.eventSeq(q.Before)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, "seq < "+arg(seq))
+	}
+
+	query := sqlListEvents
+	if len(conditions) > 0 {
+		query += "\n  WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf("\n  ORDER BY seq %s LIMIT %s", order, arg(q.limit()))
+
+	events := []Notification{}
+	if err := r.db.Select(&events, query, args...); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// eventSeq returns the position of the event identified by id, for paging from it.
+func (r *pgRepo) eventSeq(id string) (int64, error) {
+	if _, err := uuid.FromString(id); err != nil {
+		return 0, ErrInvalidEventCursor
+	}
+
+	var seq int64
+	if err := r.db.Get(&seq, sqlGetEventSeq, id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrUnknownEventCursor
+		}
+
+		return 0, err
+	}
+
+	return seq, nil
+}
+
 func (r *pgRepo) Close() error {
 	return r.db.Close()
 }
@@ -353,8 +749,9 @@ func (r *pgRepo) Close() error {
 var (
 	sqlSaveTemplate = `
   INSERT into templates
-  (name, region, size, image, ssh_keys, user_data)
-  VALUES ($1, $2, $3, $4, $5, $6)
+  (name, region, size, image, ssh_keys, user_data,
+    private_networking, ipv6, monitoring, backups, tags, volumes, agent, fallback_sizes)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
   RETURNING id`
 
 	sqlGetTemplate = `
@@ -366,17 +763,53 @@ var (
 	sqlDeleteTemplate = `
   DELETE from templates WHERE id = $1`
 
+	sqlUpdateTemplate = `
+  UPDATE templates
+  set name = $1, region = $2, size = $3, image = $4, ssh_keys = $5, user_data = $6,
+    private_networking = $7, ipv6 = $8, monitoring = $9, backups = $10, tags = $11, volumes = $12,
+    agent = $13, fallback_sizes = $14, version = version + 1
+  WHERE id = $15
+  RETURNING version`
+
+	sqlCountTemplateGroups = `
+  SELECT count(*) from groups WHERE template_id = $1 AND deleted_at is null`
+
+	sqlCreateTemplateVersion = `
+  INSERT into template_versions
+  (template_id, version, region, size, image, ssh_keys, user_data,
+    private_networking, ipv6, monitoring, backups, tags, volumes, agent, fallback_sizes, created_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	sqlGetTemplateVersion = `
+  SELECT t.id, t.name, v.version, v.region, v.size, v.image, v.ssh_keys, v.user_data,
+    v.private_networking, v.ipv6, v.monitoring, v.backups, v.tags, v.volumes, v.agent, v.fallback_sizes
+  FROM template_versions v
+  JOIN templates t ON t.id = v.template_id
+  WHERE v.template_id = $1 AND v.version = $2`
+
+	sqlListTemplateVersions = `
+  SELECT t.id, t.name, v.version, v.region, v.size, v.image, v.ssh_keys, v.user_data,
+    v.private_networking, v.ipv6, v.monitoring, v.backups, v.tags, v.volumes, v.agent, v.fallback_sizes
+  FROM template_versions v
+  JOIN templates t ON t.id = v.template_id
+  WHERE v.template_id = $1
+  ORDER BY v.version desc`
+
 	sqlCreateGroup = `
   INSERT into groups
-  (name, base_name, template_id, metric_type, metric, policy_type, policy)
-  VALUES ($1, $2, $3, $4, $5, $6, $7)
+  (name, base_name, template_id, template_version, metric_type, metric, policy_type, policy, vars, user_data, regions,
+    load_balancer_id, floating_ip, dns, notification_sinks)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
   RETURNING id`
 
 	sqlGetGroup = `
-  SELECT name, base_name, template_id, metric_type, metric, policy_type, policy from groups where id=$1`
+  SELECT name, base_name, template_id, template_version, metric_type, metric, policy_type, policy, vars, user_data, regions,
+    load_balancer_id, floating_ip, dns, notification_sinks
+  from groups where id=$1`
 
 	sqlListGroups = `
-  SELECT id, name, base_name, template_id, metric_type, metric, policy_type, policy
+  SELECT id, name, base_name, template_id, template_version, metric_type, metric, policy_type, policy, vars, user_data, regions,
+    load_balancer_id, floating_ip, dns, notification_sinks
   from groups
   where deleted_at is null`
 
@@ -384,12 +817,34 @@ var (
   UPDATE groups set deleted_at = now() where id = $1`
 
 	sqlUpdateGroup = `
-  UPDATE groups set metric = $1, policy = $2 WHERE id = $3`
+  UPDATE groups
+  set metric = $1, policy = $2, template_id = COALESCE(NULLIF($3, '')::uuid, template_id),
+    template_version = CASE WHEN $3 = '' THEN template_version ELSE $4 END,
+    vars = $5, user_data = $6, regions = $7, load_balancer_id = $8,
+    floating_ip = $9, dns = $10, notification_sinks = $11
+  WHERE id = $12`
+
+	sqlCreateDeadLetter = `
+  INSERT into dead_letters
+  (sink, group_id, notification_id, payload, error, attempts, created_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	sqlCreateEvent = `
+  INSERT into events
+  (id, group_id, name, event, kind, delta, count, message, is_error, decision, created_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	sqlListEvents = `
+  SELECT id, group_id, name, event, kind, delta, count, message, is_error, decision, created_at
+  FROM events`
+
+	sqlGetEventSeq = `
+  SELECT seq FROM events WHERE id = $1`
 
 	sqlCreateGroupStatus = `
   INSERT into group_status
-  (group_id, delta, total, created_at)
-  VALUES ($1, $2, $3, $4)`
+  (group_id, kind, delta, total, error, decision, created_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	sqlListGroupStatus = `
   SELECT distinct on (group_id) * from group_status order by group_id,created_at desc`
@@ -400,9 +855,64 @@ var (
   order by group_id,created_at desc`
 
 	sqlGetGroupHistory = `
-  SELECT group_id, delta, total, created_at
+  SELECT group_id, kind, delta, total, error, decision, created_at
   FROM group_status
   WHERE group_id = $1
   AND created_at BETWEEN $2 AND $3
+  ORDER BY created_at asc`
+
+	sqlCreateOperation = `
+  INSERT into operations
+  (group_id, state, requested_delta, actual_delta, droplet_ids, errors, health_passes, created_at, updated_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+  RETURNING id`
+
+	sqlUpdateOperation = `
+  UPDATE operations
+  set state = $1, actual_delta = $2, droplet_ids = $3, errors = $4, health_passes = $5, updated_at = $6
+  WHERE id = $7`
+
+	sqlGetOperation = `
+  SELECT id, group_id, state, requested_delta, actual_delta, droplet_ids, errors, health_passes, created_at, updated_at
+  FROM operations
+  WHERE id = $1`
+
+	sqlListUnfinishedOperations = `
+  SELECT id, group_id, state, requested_delta, actual_delta, droplet_ids, errors, health_passes, created_at, updated_at
+  FROM operations
+  WHERE state NOT IN ($1, $2)
+  ORDER BY created_at asc`
+
+	sqlCreateRefresh = `
+  INSERT into refreshes
+  (group_id, state, template_id, template_version, previous_template_id,
+   previous_template_version, min_healthy_percent, batch_size, replaced_ids, created_ids,
+   errors, created_at, updated_at)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+  RETURNING id`
+
+	sqlGetRefresh = `
+  SELECT id, group_id, state, template_id, template_version, previous_template_id,
+    previous_template_version, min_healthy_percent, batch_size, replaced_ids, created_ids,
+    errors, created_at, updated_at
+  FROM refreshes
+  WHERE id = $1`
+
+	sqlUpdateRefreshState = `
+  UPDATE refreshes
+  set state = $1, updated_at = $2
+  WHERE id = $3`
+
+	sqlUpdateRefreshProgress = `
+  UPDATE refreshes
+  set replaced_ids = $1, created_ids = $2, errors = $3, updated_at = $4
+  WHERE id = $5`
+
+	sqlListUnfinishedRefreshes = `
+  SELECT id, group_id, state, template_id, template_version, previous_template_id,
+    previous_template_version, min_healthy_percent, batch_size, replaced_ids, created_ids,
+    errors, created_at, updated_at
+  FROM refreshes
+  WHERE state IN ($1, $2, $3)
   ORDER BY created_at asc`
 )
@@ -38,8 +38,13 @@ func TestCreateTemplate(t *testing.T) {
 		sshJSON := `[{"id":1,"fingerprint":""},{"id":2,"fingerprint":""}]`
 		mock.ExpectQuery("INSERT into templates (.+) RETURNING id").
 			WithArgs("id").
-			WithArgs("a-template", "dev0", "512mb", "ubuntu-14-04-x64", []uint8(sshJSON), "userdata").
+			WithArgs("a-template", "dev0", "512mb", "ubuntu-14-04-x64", []uint8(sshJSON), "userdata",
+				true, false, true, false, []uint8(`["web"]`), []uint8(`[{"sizeGigabytes":10}]`), []uint8(`{"type":"systemd","port":9200}`), []uint8(`["1gb"]`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("id"))
+		mock.ExpectExec("INSERT into template_versions").
+			WithArgs("id", 1, "dev0", "512mb", "ubuntu-14-04-x64", []uint8(sshJSON), "userdata",
+				true, false, true, false, []uint8(`["web"]`), []uint8(`[{"sizeGigabytes":10}]`), []uint8(`{"type":"systemd","port":9200}`), []uint8(`["1gb"]`), anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		in := Template{
@@ -51,7 +56,13 @@ func TestCreateTemplate(t *testing.T) {
 				{ID: 1},
 				{ID: 2},
 			},
-			UserData: "userdata",
+			UserData:          "userdata",
+			PrivateNetworking: true,
+			Monitoring:        true,
+			Tags:              TemplateTags{"web"},
+			Volumes:           TemplateVolumes{{SizeGigabytes: 10}},
+			Agent:             Agent{Type: AgentSystemd, Port: 9200},
+			FallbackSizes:     TemplateSizes{"1gb"},
 		}
 
 		expected := &Template{
@@ -64,7 +75,14 @@ func TestCreateTemplate(t *testing.T) {
 				{ID: 1},
 				{ID: 2},
 			},
-			UserData: "userdata",
+			UserData:          "userdata",
+			Version:           1,
+			PrivateNetworking: true,
+			Monitoring:        true,
+			Tags:              TemplateTags{"web"},
+			Volumes:           TemplateVolumes{{SizeGigabytes: 10}},
+			Agent:             Agent{Type: AgentSystemd, Port: 9200},
+			FallbackSizes:     TemplateSizes{"1gb"},
 		}
 
 		tmpl, err := repo.CreateTemplate(ctx, in)
@@ -142,6 +160,8 @@ func TestListTemplates(t *testing.T) {
 func TestDeleteTemplate(t *testing.T) {
 	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
 		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count(.+) from groups").WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 		mock.ExpectExec("DELETE from templates").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
@@ -168,18 +188,27 @@ func TestCreateGroup(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT into groups (.+) RETURNING id").
 			WithArgs("id").
-			WithArgs("group", "as", "a-template", "load", []uint8(metricJSON), "value", []uint8(vpJSON)).
+			WithArgs("group", "as", "a-template", 0, "load", []uint8(metricJSON), "value", []uint8(vpJSON), []uint8(`{"service":"web"}`), "#cloud-config\n", []uint8(`[{"region":"nyc1","weight":2,"min":1,"max":0}]`), "lb-1", "10.0.0.1", []uint8(`{"domain":"example.com","name":"www","mode":"round-robin"}`), []uint8(`[{"type":"webhook","url":"https://example.com/hook","events":["scale-up"]}]`)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("abcdefg"))
 		mock.ExpectCommit()
 
 		group := Group{
-			Name:       "group",
-			BaseName:   "as",
-			TemplateID: "a-template",
-			MetricType: "load",
-			Metric:     m,
-			PolicyType: "value",
-			Policy:     vp,
+			Name:           "group",
+			BaseName:       "as",
+			TemplateID:     "a-template",
+			MetricType:     "load",
+			Metric:         m,
+			PolicyType:     "value",
+			Policy:         vp,
+			Vars:           GroupVars{"service": "web"},
+			UserData:       "#cloud-config\n",
+			Regions:        GroupRegions{{Region: "nyc1", Weight: 2, Min: 1}},
+			LoadBalancerID: "lb-1",
+			FloatingIP:     "10.0.0.1",
+			DNS:            GroupDNS{Domain: "example.com", Name: "www", Mode: DNSModeRoundRobin},
+			NotificationSinks: SinkConfigs{
+				{Type: SinkWebhook, URL: "https://example.com/hook", Events: []string{EventScaleUp}},
+			},
 		}
 
 		g, err := repo.CreateGroup(ctx, group)
@@ -216,7 +245,7 @@ func TestCreateGroup_InvalidName(t *testing.T) {
 
 func TestGetGroup(t *testing.T) {
 	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
-		groupColumns := []string{"name", "base_name", "template_id", "metric_type", "metric", "policy_type", "policy"}
+		groupColumns := []string{"name", "base_name", "template_id", "template_version", "metric_type", "metric", "policy_type", "policy", "vars", "user_data", "regions", "load_balancer_id", "floating_ip", "dns", "notification_sinks"}
 
 		statusColumns := []string{"group_id", "delta", "total", "created_at"}
 
@@ -232,7 +261,7 @@ func TestGetGroup(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) from groups (.+)").
 			WithArgs("abc").
 			WillReturnRows(sqlmock.NewRows(groupColumns).
-				AddRow("group-1", "as", "template-1", "load", []uint8(mJSON), "value", []uint8(pJSON)))
+				AddRow("group-1", "as", "template-1", 0, "load", []uint8(mJSON), "value", []uint8(pJSON), []uint8(`{"service":"web"}`), "#cloud-config\n", []uint8(`[{"region":"nyc1","weight":2,"min":1,"max":0}]`), "lb-1", "10.0.0.1", []uint8(`{"domain":"example.com","name":"www","mode":"round-robin"}`), []uint8(`[{"type":"webhook","url":"https://example.com/hook","events":["scale-up"]}]`)))
 
 		mock.ExpectQuery("SELECT (.+) FROM group_status").
 			WithArgs("abc", anyTime{}, anyTime{}).
@@ -242,13 +271,20 @@ func TestGetGroup(t *testing.T) {
 		group, err := repo.GetGroup(ctx, "abc")
 		require.NoError(t, err)
 		require.Equal(t, "group-1", group.Name)
+		require.Equal(t, GroupVars{"service": "web"}, group.Vars)
+		require.Equal(t, "#cloud-config\n", group.UserData)
+		require.Equal(t, GroupRegions{{Region: "nyc1", Weight: 2, Min: 1}}, group.Regions)
+		require.Equal(t, "lb-1", group.LoadBalancerID)
+		require.Equal(t, "10.0.0.1", group.FloatingIP)
+		require.Equal(t, GroupDNS{Domain: "example.com", Name: "www", Mode: DNSModeRoundRobin}, group.DNS)
+		require.Equal(t, SinkConfigs{{Type: SinkWebhook, URL: "https://example.com/hook", Events: []string{EventScaleUp}}}, group.NotificationSinks)
 
 	})
 }
 
 func TestListGroups(t *testing.T) {
 	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
-		groupColumns := []string{"id", "name", "base_name", "template_id", "metric_type", "metric", "policy_type", "policy"}
+		groupColumns := []string{"id", "name", "base_name", "template_id", "template_version", "metric_type", "metric", "policy_type", "policy", "vars", "user_data", "regions", "load_balancer_id", "floating_ip", "dns", "notification_sinks"}
 
 		statusColumns := []string{"group_id", "delta", "total", "created_at"}
 
@@ -263,8 +299,8 @@ func TestListGroups(t *testing.T) {
 
 		mock.ExpectQuery("SELECT (.+) from groups").
 			WillReturnRows(sqlmock.NewRows(groupColumns).
-				AddRow("abc", "group1", "as", "template-1", "load", []uint8(mJSON), "value", []uint8(pJSON)).
-				AddRow("def", "group2", "as", "template-1", "load", []uint8(mJSON), "value", []uint8(pJSON)))
+				AddRow("abc", "group1", "as", "template-1", 0, "load", []uint8(mJSON), "value", []uint8(pJSON), []uint8(`{}`), "", []uint8(`[]`), "", "", []uint8(`{}`), []uint8(`[]`)).
+				AddRow("def", "group2", "as", "template-1", 0, "load", []uint8(mJSON), "value", []uint8(pJSON), []uint8(`{}`), "", []uint8(`[]`), "", "", []uint8(`{}`), []uint8(`[]`)))
 
 		mock.ExpectQuery("SELECT (.+) FROM group_status").
 			WithArgs("abc", anyTime{}, anyTime{}).
@@ -303,7 +339,7 @@ func TestUpdateGroup(t *testing.T) {
 		p.vpd = defaultValuePolicy
 
 		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE groups").WithArgs(&m, &p, "abc").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE groups").WithArgs(&m, &p, "a-template", 0, []uint8(`{}`), "", []uint8(`[]`), "", "", []uint8(`{"domain":"","name":"","mode":""}`), []uint8(`[]`), "abc").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		g := Group{
@@ -332,21 +368,60 @@ func (a anyTime) Match(v driver.Value) bool {
 
 func TestGetGroupHistory(t *testing.T) {
 	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
-		columns := []string{"group_id", "delta", "total", "created_at"}
+		columns := []string{"group_id", "kind", "delta", "total", "error", "decision", "created_at"}
 
 		now := time.Now().UTC()
 		createdAt := now.Add(-1 * time.Hour)
 		createdAt2 := createdAt.Add(5 * time.Minute)
 
+		decision := []byte(`{"reason":"policy","metric":"load","metricValue":0.9,"threshold":0.8,"comparison":">=","created":[1,2,3,4],"duration":5000000000}`)
+
 		mock.ExpectQuery("SELECT (.*) FROM group_status").
 			WithArgs("1", anyTime{}, anyTime{}).
 			WillReturnRows(sqlmock.NewRows(columns).
-				AddRow("1", 1, 1, createdAt).
-				AddRow("1", 4, 5, createdAt2))
+				AddRow("1", "scaled", 1, 1, "", nil, createdAt).
+				AddRow("1", "scaled", 4, 5, "", decision, createdAt2))
 
 		history, err := repo.GetGroupHistory(ctx, "1", RangeQuarterDay)
 		require.NoError(t, err)
 		require.Len(t, history, 4)
+
+		require.Nil(t, history[1].Decision)
+		require.Equal(t, ActivityScaled, history[1].Kind)
+		require.NotNil(t, history[2].Decision)
+		require.Equal(t, []int{1, 2, 3, 4}, history[2].Decision.Created)
+		require.Equal(t, 5*time.Second, history[2].Decision.Duration)
+		require.Nil(t, history[3].Decision, "padding entries don't repeat decisions")
+	})
+}
+
+func TestAddGroupStatus(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		d := &Decision{Reason: DecisionResize, RequestedSize: 5, Created: []int{7}}
+		value, err := d.Value()
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT into group_status").
+			WithArgs("1", ActivityScaled, 2, 5, "", value, anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err = repo.AddGroupStatus(ctx, GroupStatus{GroupID: "1", Kind: ActivityScaled, Delta: 2, Total: 5, Decision: d, CreatedAt: time.Now()})
+		require.NoError(t, err)
+	})
+}
+
+func TestAddGroupStatus_Failed(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT into group_status").
+			WithArgs("1", ActivityFailed, 0, 3, "unable to create droplet", nil, anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := repo.AddGroupStatus(ctx, GroupStatus{GroupID: "1", Kind: ActivityFailed, Total: 3, Error: "unable to create droplet", CreatedAt: time.Now()})
+		require.NoError(t, err)
 	})
 }
 
@@ -392,3 +467,296 @@ func TestGetGroupHistory_NoHistory(t *testing.T) {
 		require.Len(t, history, 2)
 	})
 }
+
+func TestCreateOperation(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		op := NewOperation("group-id", 2)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT into operations (.+) RETURNING id").
+			WithArgs("group-id", OperationPlanned, 2, 0, []uint8("[]"), []uint8("[]"), []uint8("{}"), anyTime{}, anyTime{}).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("op-id"))
+		mock.ExpectCommit()
+
+		newOp, err := repo.CreateOperation(ctx, *op)
+		require.NoError(t, err)
+		require.Equal(t, "op-id", newOp.ID)
+	})
+}
+
+func TestSaveOperation(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		op := NewOperation("group-id", 2)
+		op.ID = "op-id"
+		op.State = OperationTagging
+		op.AddDropletID(1)
+		op.AddDropletID(2)
+		op.HealthPasses[2] = 1
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE operations").
+			WithArgs(OperationTagging, 0, []uint8("[1,2]"), []uint8("[]"), []uint8(`{"2":1}`), anyTime{}, "op-id").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := repo.SaveOperation(ctx, *op)
+		require.NoError(t, err)
+	})
+}
+
+func TestListUnfinishedOperations(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		columns := []string{"id", "group_id", "state", "requested_delta", "actual_delta",
+			"droplet_ids", "errors", "health_passes", "created_at", "updated_at"}
+
+		now := time.Now()
+		mock.ExpectQuery("SELECT (.+) FROM operations").
+			WithArgs(OperationDone, OperationFailed).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "group-id", "creating", 2, 0, []uint8("[1]"), []uint8("[]"), []uint8("{}"), now, now).
+				AddRow("2", "group-id", "registering", 1, 1, []uint8("[2]"), []uint8(`["boom"]`), []uint8(`{"2":1}`), now, now))
+
+		ops, err := repo.ListUnfinishedOperations(ctx)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, OperationCreating, ops[0].State)
+		require.Equal(t, DropletIDs{1}, ops[0].DropletIDs)
+		require.Equal(t, OperationErrors{"boom"}, ops[1].Errors)
+		require.Equal(t, HealthPasses{2: 1}, ops[1].HealthPasses)
+	})
+}
+
+func TestCreateRefresh(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		refresh, err := NewRefresh("group-id", Template{ID: "new", Version: 2}, Template{ID: "old", Version: 1}, 4, 50)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT into refreshes (.+) RETURNING id").
+			WithArgs("group-id", RefreshRunning, "new", 2, "old", 1, 50, 2,
+				[]uint8("[]"), []uint8("[]"), []uint8("[]"), anyTime{}, anyTime{}).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("refresh-id"))
+		mock.ExpectCommit()
+
+		newRefresh, err := repo.CreateRefresh(ctx, *refresh)
+		require.NoError(t, err)
+		require.Equal(t, "refresh-id", newRefresh.ID)
+	})
+}
+
+func TestUpdateRefreshState(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE refreshes").
+			WithArgs(RefreshPaused, anyTime{}, "refresh-id").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := repo.UpdateRefreshState(ctx, "refresh-id", RefreshPaused)
+		require.NoError(t, err)
+	})
+}
+
+func TestAddDeadLetter(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT into dead_letters").
+			WithArgs("webhook https://example.com/hook", "group-1", "notif-1", []byte(`{}`), "webhook returned status 500", 5, anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		d := DeadLetter{
+			Sink:           "webhook https://example.com/hook",
+			GroupID:        "group-1",
+			NotificationID: "notif-1",
+			Payload:        []byte(`{}`),
+			Error:          "webhook returned status 500",
+			Attempts:       5,
+			CreatedAt:      time.Now(),
+		}
+
+		err := repo.AddDeadLetter(ctx, d)
+		require.NoError(t, err)
+	})
+}
+
+func TestAddEvent(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT into events").
+			WithArgs("event-1", "group-1", "web", EventError, "failed", 0, 0, "boom", true, nil, anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		n := Notification{
+			ID:        "event-1",
+			GroupID:   "group-1",
+			Name:      "web",
+			Event:     EventError,
+			Kind:      "failed",
+			Message:   "boom",
+			IsError:   true,
+			CreatedAt: time.Now(),
+		}
+
+		err := repo.AddEvent(ctx, n)
+		require.NoError(t, err)
+	})
+}
+
+func TestListEvents(t *testing.T) {
+	columns := []string{"id", "group_id", "name", "event", "kind", "delta", "count", "message", "is_error", "decision", "created_at"}
+	since := time.Now().Add(-time.Hour)
+	cursor := "0b0b6b2e-5d46-4b7c-9d2b-0c3b1f0e4a11"
+
+	cases := []struct {
+		name   string
+		q      EventQuery
+		cursor bool
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name:  "defaults",
+			q:     EventQuery{},
+			query: `FROM events\s+ORDER BY seq DESC LIMIT \$1`,
+			args:  []driver.Value{DefaultEventLimit},
+		},
+		{
+			name:   "filters and cursor",
+			q:      EventQuery{GroupID: "group-1", Events: []string{EventError, EventDisabled}, Since: since, Before: cursor, Limit: 10},
+			cursor: true,
+			query:  `FROM events\s+WHERE group_id = \$1 AND event IN \(\$2, \$3\) AND created_at >= \$4 AND seq < \$5\s+ORDER BY seq DESC LIMIT \$6`,
+			args:   []driver.Value{"group-1", EventError, EventDisabled, since, 9, 10},
+		},
+		{
+			name:   "replay",
+			q:      EventQuery{After: cursor, Limit: 1000},
+			cursor: true,
+			query:  `FROM events\s+WHERE seq > \$1\s+ORDER BY seq ASC LIMIT \$2`,
+			args:   []driver.Value{9, MaxEventLimit},
+		},
+	}
+
+	for _, c := range cases {
+		withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+			if c.cursor {
+				mock.ExpectQuery(`SELECT seq FROM events WHERE id = \$1`).
+					WithArgs(cursor).
+					WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(9))
+			}
+
+			mock.ExpectQuery(c.query).
+				WithArgs(c.args...).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("event-10", "group-1", "web", EventError, "", 0, 0, "boom", true, nil, time.Now()))
+
+			events, err := repo.ListEvents(ctx, c.q)
+			require.NoError(t, err, c.name)
+			require.Len(t, events, 1, c.name)
+			require.Equal(t, "event-10", events[0].ID, c.name)
+			require.True(t, events[0].IsError, c.name)
+		})
+	}
+}
+
+func TestListEvents_Cursors(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		_, err := repo.ListEvents(ctx, EventQuery{Before: "event-9"})
+		require.Equal(t, ErrInvalidEventCursor, err)
+
+		cursor := "0b0b6b2e-5d46-4b7c-9d2b-0c3b1f0e4a11"
+		mock.ExpectQuery(`SELECT seq FROM events WHERE id = \$1`).
+			WithArgs(cursor).
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+
+		_, err = repo.ListEvents(ctx, EventQuery{After: cursor})
+		require.Equal(t, ErrUnknownEventCursor, err)
+	})
+}
+
+func TestListUnfinishedRefreshes(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		columns := []string{"id", "group_id", "state", "template_id", "template_version",
+			"previous_template_id", "previous_template_version", "min_healthy_percent", "batch_size",
+			"replaced_ids", "created_ids", "errors", "created_at", "updated_at"}
+
+		now := time.Now()
+		mock.ExpectQuery("SELECT (.+) FROM refreshes").
+			WithArgs(RefreshRunning, RefreshPaused, RefreshRollingBack).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "group-id", "running", "new", 2, "old", 1, 50, 2,
+					[]uint8(`["1"]`), []uint8(`["3"]`), []uint8("[]"), now, now))
+
+		refreshes, err := repo.ListUnfinishedRefreshes(ctx)
+		require.NoError(t, err)
+		require.Len(t, refreshes, 1)
+		require.Equal(t, RefreshRunning, refreshes[0].State)
+		require.Equal(t, ResourceIDs{"1"}, refreshes[0].ReplacedIDs)
+	})
+}
+
+func TestDeleteTemplate_InUse(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count(.+) from groups").WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectRollback()
+
+		err := repo.DeleteTemplate(ctx, "1")
+		require.Equal(t, ErrTemplateInUse, err)
+	})
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE templates (.+) RETURNING version").
+			WithArgs("a-template", "dev0", "1gb", "ubuntu-14-04-x64", []uint8("[]"), "",
+				false, true, false, true, []uint8("[]"), []uint8("[]"), []uint8("{}"), []uint8("[]"), "1").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectExec("INSERT into template_versions").
+			WithArgs("1", 3, "dev0", "1gb", "ubuntu-14-04-x64", []uint8("[]"), "",
+				false, true, false, true, []uint8("[]"), []uint8("[]"), []uint8("{}"), []uint8("[]"), anyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		in := Template{
+			ID:      "1",
+			Name:    "a-template",
+			Region:  "dev0",
+			Size:    "1gb",
+			Image:   "ubuntu-14-04-x64",
+			SSHKeys: SSHKeys{},
+			IPv6:    true,
+			Backups: true,
+		}
+
+		tmpl, err := repo.UpdateTemplate(ctx, in)
+		require.NoError(t, err)
+		require.Equal(t, 3, tmpl.Version)
+	})
+}
+
+func TestGetTemplateVersion(t *testing.T) {
+	withDBMock(t, func(ctx context.Context, repo Repository, mock sqlmock.Sqlmock) {
+		columns := []string{"id", "name", "version", "region", "size", "image", "ssh_keys", "user_data",
+			"private_networking", "ipv6", "monitoring", "backups", "tags", "volumes", "agent", "fallback_sizes"}
+
+		mock.ExpectQuery("SELECT (.+) FROM template_versions (.+)").
+			WithArgs("1", 2).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "a-template", 2, "dev0", "512mb", "ubuntu-14-04-x64", []uint8(`[]`), "userdata",
+					true, false, false, false, []uint8(`["web"]`), []uint8(`[{"sizeGigabytes":10}]`), []uint8(`{"type":"none"}`), []uint8(`["1gb","2gb"]`)))
+
+		tmpl, err := repo.GetTemplateVersion(ctx, "1", 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, tmpl.Version)
+		require.Equal(t, "512mb", tmpl.Size)
+		require.True(t, tmpl.PrivateNetworking)
+		require.Equal(t, TemplateTags{"web"}, tmpl.Tags)
+		require.Equal(t, TemplateVolumes{{SizeGigabytes: 10}}, tmpl.Volumes)
+		require.Equal(t, AgentNone, tmpl.Agent.Type)
+		require.Equal(t, TemplateSizes{"1gb", "2gb"}, tmpl.FallbackSizes)
+	})
+}
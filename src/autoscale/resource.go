@@ -13,15 +13,30 @@ type ResourceHistory struct {
 
 // ResourceAllocation is information about an allocated resource.
 type ResourceAllocation struct {
-	Name      string            `json:"name"`
-	Address   string            `json:"address"`
-	CreatedAt time.Time         `json:"createdAt"`
-	History   []ResourceHistory `json:"history"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Address         string            `json:"address"`
+	Protected       bool              `json:"protected"`
+	TemplateID      string            `json:"templateID"`
+	TemplateVersion int               `json:"templateVersion"`
+	AgentPort       int               `json:"agentPort"`
+	Region          string            `json:"region"`
+	Size            string            `json:"size"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	History         []ResourceHistory `json:"history"`
 }
 
 // ResourceManager is a watched resource interface.
 type ResourceManager interface {
 	Count() (int, error)
-	Scale(ctx context.Context, g Group, byN int, repo Repository) (bool, error)
+	Scale(ctx context.Context, g Group, op *Operation, repo Repository) (bool, error)
+	Resume(ctx context.Context, g Group, op *Operation, repo Repository) error
 	Allocated() ([]ResourceAllocation, error)
+	Protect(ctx context.Context, id string, protected bool) error
+	Attach(ctx context.Context, id string) error
+	Detach(ctx context.Context, id string) error
+	Remove(ctx context.Context, ids []string) error
+	Healthy(ctx context.Context, ids []string) (bool, error)
+	ReconcileFloatingIP(ctx context.Context) ([]FloatingIPChange, error)
+	ReconcileDNS(ctx context.Context) error
 }
@@ -1,7 +1,9 @@
 package autoscale
 
 import (
+	"fmt"
 	"pkg/ctxutil"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -12,20 +14,90 @@ type GroupActionFn func(ctx context.Context, groupID string) *ActionStatus
 
 type GroupAction interface {
 	Scale(ctx context.Context, groupID string) *ActionStatus
+	Resize(ctx context.Context, groupID string, size int) *ActionStatus
+	Attach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus
+	Detach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus
+	Refresh(ctx context.Context, groupID, refreshID string) *ActionStatus
 	Disable(ctx context.Context, groupID string) *ActionStatus
 }
 
+// GroupRequest is a request to run an action for a group outside of the regular schedule.
+type GroupRequest struct {
+	ID  string
+	Run func(ctx context.Context, ga GroupAction) *ActionStatus
+}
+
+// CheckGroupRequest creates a GroupRequest which evaluates a group immediately.
+func CheckGroupRequest(groupID string) GroupRequest {
+	return GroupRequest{
+		ID: groupID,
+		Run: func(ctx context.Context, ga GroupAction) *ActionStatus {
+			return ga.Scale(ctx, groupID)
+		},
+	}
+}
+
+// ResizeGroupRequest creates a GroupRequest which resizes a group to size.
+func ResizeGroupRequest(groupID string, size int) GroupRequest {
+	return GroupRequest{
+		ID: groupID,
+		Run: func(ctx context.Context, ga GroupAction) *ActionStatus {
+			return ga.Resize(ctx, groupID, size)
+		},
+	}
+}
+
+// AttachGroupRequest creates a GroupRequest which adopts an existing resource into a group.
+func AttachGroupRequest(groupID, resourceID string, adjustCount bool) GroupRequest {
+	return GroupRequest{
+		ID: groupID,
+		Run: func(ctx context.Context, ga GroupAction) *ActionStatus {
+			return ga.Attach(ctx, groupID, resourceID, adjustCount)
+		},
+	}
+}
+
+// DetachGroupRequest creates a GroupRequest which removes a resource from a group
+// without deleting it.
+func DetachGroupRequest(groupID, resourceID string, adjustCount bool) GroupRequest {
+	return GroupRequest{
+		ID: groupID,
+		Run: func(ctx context.Context, ga GroupAction) *ActionStatus {
+			return ga.Detach(ctx, groupID, resourceID, adjustCount)
+		},
+	}
+}
+
+// RefreshGroupRequest creates a GroupRequest which runs a refresh for a group.
+func RefreshGroupRequest(groupID, refreshID string) GroupRequest {
+	return GroupRequest{
+		ID: groupID,
+		Run: func(ctx context.Context, ga GroupAction) *ActionStatus {
+			return ga.Refresh(ctx, groupID, refreshID)
+		},
+	}
+}
+
 type SchedulerActivity struct {
 	ID    string
+	Kind  ActivityKind
 	Err   error
 	Delta int
 	Count int
+
+	// Message describes the activity for kinds other than a change in the group's size,
+	// such as ActivityFloatingIPReassigned.
+	Message string
+
+	// Decision records why the group's size changed.
+	Decision *Decision
 }
 
 type SchedulerStatus struct {
 	EnableGroup  chan string
 	DisableGroup chan string
 	Schedule     chan string
+	Request      chan GroupRequest
 	Activity     chan SchedulerActivity
 }
 
@@ -34,9 +106,13 @@ type Scheduler struct {
 	enableGroupChan  chan string
 	disableGroupChan chan string
 	scheduleChan     chan string
+	requestChan      chan GroupRequest
 	activityChan     chan SchedulerActivity
 	groupAction      GroupAction
 	disabledIDs      map[string]bool
+
+	mu         sync.Mutex
+	runningIDs map[string]bool
 }
 
 func NewScheduler(ctx context.Context, ga GroupAction) *Scheduler {
@@ -45,9 +121,11 @@ func NewScheduler(ctx context.Context, ga GroupAction) *Scheduler {
 		enableGroupChan:  make(chan string, 1),
 		disableGroupChan: make(chan string, 1),
 		scheduleChan:     make(chan string, 1),
+		requestChan:      make(chan GroupRequest, 1),
 		activityChan:     make(chan SchedulerActivity, 1),
 		groupAction:      ga,
 		disabledIDs:      map[string]bool{},
+		runningIDs:       map[string]bool{},
 	}
 }
 
@@ -56,6 +134,7 @@ func (s *Scheduler) Status() *SchedulerStatus {
 		EnableGroup:  s.enableGroupChan,
 		DisableGroup: s.disableGroupChan,
 		Schedule:     s.scheduleChan,
+		Request:      s.requestChan,
 		Activity:     s.activityChan,
 	}
 }
@@ -65,6 +144,13 @@ type ActionStatus struct {
 	Err   error
 	Delta int
 	Count int
+
+	// Decision records why the action changed the group's size.
+	Decision *Decision
+
+	// Activities are recorded by the action as it runs and sent before the action's own
+	// activity.
+	Activities []SchedulerActivity
 }
 
 func (s *Scheduler) Start() {
@@ -76,13 +162,22 @@ func (s *Scheduler) Start() {
 			if _, ok := s.disabledIDs[id]; ok {
 				s.log().WithField("group-id", id).Warn("will not schedule group as it is disabled")
 				s.activityChan <- SchedulerActivity{
-					ID:  id,
-					Err: ErrDisabledGroup,
+					ID:   id,
+					Kind: ActivityFailed,
+					Err:  ErrDisabledGroup,
 				}
 				continue
 			}
 
+			if !s.startRunning(id) {
+				s.log().WithField("group-id", id).Info("group has an action running; will try again later")
+				s.reenqueue(id)
+				continue
+			}
+
 			go func() {
+				defer s.stopRunning(id)
+
 				actionStatus := s.groupAction.Scale(s.ctx, id)
 				err := handleActionStatus(s.ctx, actionStatus)
 				if err != nil {
@@ -90,21 +185,69 @@ func (s *Scheduler) Start() {
 					s.disableGroup(id)
 				}
 
+				s.sendActivities(actionStatus, err)
+				s.activityChan <- actionActivity(id, actionStatus, err)
+
+				if err != nil {
+					s.activityChan <- SchedulerActivity{
+						ID:      id,
+						Kind:    ActivityDisabled,
+						Message: fmt.Sprintf("group was disabled because it could not be scaled: %v", err),
+						Count:   actionStatus.Count,
+					}
+				}
+
+				s.reenqueue(id)
+			}()
+
+		case req := <-s.requestChan:
+			id := req.ID
+			s.log().WithField("group-id", id).Info("running requested action for group")
+
+			if _, ok := s.disabledIDs[id]; ok {
+				s.log().WithField("group-id", id).Warn("will not run requested action as group is disabled")
+				s.activityChan <- SchedulerActivity{
+					ID:   id,
+					Kind: ActivityFailed,
+					Err:  ErrDisabledGroup,
+				}
+				continue
+			}
+
+			if !s.startRunning(id) {
+				s.log().WithField("group-id", id).Warn("will not run requested action as group is busy")
 				s.activityChan <- SchedulerActivity{
-					ID:    id,
-					Err:   err,
-					Delta: actionStatus.Delta,
-					Count: actionStatus.Count,
+					ID:   id,
+					Kind: ActivityFailed,
+					Err:  ErrGroupBusy,
 				}
+				continue
+			}
 
-				time.AfterFunc(ScheduleReenqueueTimeout, func() {
-					s.scheduleChan <- id
-				})
+			go func() {
+				defer s.stopRunning(id)
+
+				actionStatus := req.Run(s.ctx, s.groupAction)
+				err := handleActionStatus(s.ctx, actionStatus)
+				if err != nil {
+					s.log().WithError(err).Error("requested action did not run with success")
+				}
+
+				s.sendActivities(actionStatus, err)
+				s.activityChan <- actionActivity(id, actionStatus, err)
 			}()
 
 		case id := <-s.enableGroupChan:
 			s.log().WithField("group-id", id).Info("enabling group")
-			delete(s.disabledIDs, id)
+
+			if s.disabledIDs[id] {
+				delete(s.disabledIDs, id)
+				s.activityChan <- SchedulerActivity{
+					ID:      id,
+					Kind:    ActivityEnabled,
+					Message: "group was enabled",
+				}
+			}
 
 		case id := <-s.disableGroupChan:
 			s.log().WithField("group-id", id).Info("disabling group")
@@ -117,12 +260,14 @@ func (s *Scheduler) Start() {
 						s.log().WithError(err).Error("action did not run with success")
 					}
 
-					s.activityChan <- SchedulerActivity{
-						ID:    id,
-						Err:   err,
-						Delta: actionStatus.Delta,
-						Count: actionStatus.Count,
+					s.sendActivities(actionStatus, err)
+
+					activity := actionActivity(id, actionStatus, err)
+					if err == nil {
+						activity.Kind = ActivityDisabled
+						activity.Message = "group was disabled"
 					}
+					s.activityChan <- activity
 				}(id)
 			}
 
@@ -132,10 +277,72 @@ func (s *Scheduler) Start() {
 	}
 }
 
+// sendActivities sends the activities an action recorded. They are skipped if the
+// action timed out since it may still be running.
+func (s *Scheduler) sendActivities(as *ActionStatus, err error) {
+	if err == ErrActionTimedOut {
+		return
+	}
+
+	for _, activity := range as.Activities {
+		s.activityChan <- activity
+	}
+}
+
+// actionActivity creates the activity for an action which ran for a group.
+func actionActivity(id string, as *ActionStatus, err error) SchedulerActivity {
+	activity := SchedulerActivity{
+		ID:       id,
+		Kind:     ActivityEvaluated,
+		Err:      err,
+		Delta:    as.Delta,
+		Count:    as.Count,
+		Decision: as.Decision,
+	}
+
+	switch {
+	case err == ErrActionTimedOut:
+		activity.Kind = ActivityTimedOut
+	case err != nil:
+		activity.Kind = ActivityFailed
+	case as.Delta != 0:
+		activity.Kind = ActivityScaled
+	}
+
+	return activity
+}
+
 func (s *Scheduler) disableGroup(id string) {
 	s.disabledIDs[id] = true
 }
 
+func (s *Scheduler) reenqueue(id string) {
+	time.AfterFunc(ScheduleReenqueueTimeout, func() {
+		s.scheduleChan <- id
+	})
+}
+
+// startRunning marks a group as having an action in progress. It returns false if
+// the group already has an action in progress.
+func (s *Scheduler) startRunning(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runningIDs[id] {
+		return false
+	}
+
+	s.runningIDs[id] = true
+	return true
+}
+
+func (s *Scheduler) stopRunning(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.runningIDs, id)
+}
+
 func (s *Scheduler) log() *logrus.Entry {
 	return ctxutil.LogFromContext(s.ctx).WithField("action", "schedule")
 }
@@ -1,6 +1,7 @@
 package autoscale
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -9,6 +10,10 @@ import (
 
 type testCheck struct {
 	ScaleFn   GroupActionFn
+	ResizeFn  func(ctx context.Context, groupID string, size int) *ActionStatus
+	AttachFn  func(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus
+	DetachFn  func(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus
+	RefreshFn func(ctx context.Context, groupID, refreshID string) *ActionStatus
 	DisableFn GroupActionFn
 }
 
@@ -16,6 +21,22 @@ func (tc *testCheck) Scale(ctx context.Context, groupID string) *ActionStatus {
 	return tc.ScaleFn(ctx, groupID)
 }
 
+func (tc *testCheck) Resize(ctx context.Context, groupID string, size int) *ActionStatus {
+	return tc.ResizeFn(ctx, groupID, size)
+}
+
+func (tc *testCheck) Attach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus {
+	return tc.AttachFn(ctx, groupID, resourceID, adjustCount)
+}
+
+func (tc *testCheck) Detach(ctx context.Context, groupID, resourceID string, adjustCount bool) *ActionStatus {
+	return tc.DetachFn(ctx, groupID, resourceID, adjustCount)
+}
+
+func (tc *testCheck) Refresh(ctx context.Context, groupID, refreshID string) *ActionStatus {
+	return tc.RefreshFn(ctx, groupID, refreshID)
+}
+
 func (tc *testCheck) Disable(ctx context.Context, groupID string) *ActionStatus {
 	return tc.DisableFn(ctx, groupID)
 }
@@ -55,3 +76,153 @@ func TestSchedule(t *testing.T) {
 	require.Equal(t, expectedID, activity.ID)
 	require.NoError(t, activity.Err)
 }
+
+func TestSchedule_FailureDisablesGroup(t *testing.T) {
+	ctx := context.Background()
+
+	expectedID := "id"
+
+	tc := &testCheck{
+		ScaleFn: func(ctx context.Context, groupID string) *ActionStatus {
+			as := &ActionStatus{
+				Done:  make(chan bool, 1),
+				Err:   fmt.Errorf("no template"),
+				Count: 3,
+			}
+			as.Done <- true
+
+			return as
+		},
+	}
+
+	s := NewScheduler(ctx, tc)
+	status := s.Status()
+	go s.Start()
+
+	status.EnableGroup <- expectedID
+	status.Schedule <- expectedID
+
+	failed := <-status.Activity
+	require.Equal(t, ActivityFailed, failed.Kind)
+
+	disabled := <-status.Activity
+	require.Equal(t, expectedID, disabled.ID)
+	require.Equal(t, ActivityDisabled, disabled.Kind)
+	require.Equal(t, 3, disabled.Count)
+	require.Contains(t, disabled.Message, "no template")
+}
+
+func TestSchedule_Request(t *testing.T) {
+	ctx := context.Background()
+
+	expectedID := "id"
+	resizedTo := 0
+
+	tc := &testCheck{
+		ResizeFn: func(ctx context.Context, groupID string, size int) *ActionStatus {
+			resizedTo = size
+
+			as := &ActionStatus{
+				Done:  make(chan bool, 1),
+				Delta: 2,
+				Count: size,
+			}
+			as.Done <- true
+
+			return as
+		},
+	}
+
+	s := NewScheduler(ctx, tc)
+	status := s.Status()
+	go s.Start()
+
+	status.Request <- ResizeGroupRequest(expectedID, 5)
+
+	activity := <-status.Activity
+
+	require.Equal(t, 5, resizedTo)
+	require.Equal(t, expectedID, activity.ID)
+	require.NoError(t, activity.Err)
+	require.Equal(t, 2, activity.Delta)
+	require.Equal(t, 5, activity.Count)
+}
+
+func TestSchedule_RequestDisabledGroup(t *testing.T) {
+	ctx := context.Background()
+
+	tc := &testCheck{
+		DisableFn: func(ctx context.Context, groupID string) *ActionStatus {
+			as := &ActionStatus{Done: make(chan bool, 1)}
+			as.Done <- true
+			return as
+		},
+	}
+
+	s := NewScheduler(ctx, tc)
+	status := s.Status()
+	go s.Start()
+
+	status.DisableGroup <- "id"
+	<-status.Activity
+
+	status.Request <- CheckGroupRequest("id")
+	activity := <-status.Activity
+
+	require.Equal(t, ErrDisabledGroup, activity.Err)
+}
+
+func TestSchedule_RequestActivities(t *testing.T) {
+	ctx := context.Background()
+
+	tc := &testCheck{
+		ScaleFn: func(ctx context.Context, groupID string) *ActionStatus {
+			as := &ActionStatus{
+				Done:  make(chan bool, 1),
+				Count: 2,
+				Activities: []SchedulerActivity{
+					{ID: groupID, Kind: ActivityFloatingIPReassigned, Message: "moved"},
+				},
+			}
+			as.Done <- true
+
+			return as
+		},
+	}
+
+	s := NewScheduler(ctx, tc)
+	status := s.Status()
+	go s.Start()
+
+	status.Request <- CheckGroupRequest("id")
+
+	activity := <-status.Activity
+	require.Equal(t, ActivityFloatingIPReassigned, activity.Kind)
+	require.Equal(t, "moved", activity.Message)
+
+	activity = <-status.Activity
+	require.Equal(t, ActivityEvaluated, activity.Kind)
+	require.Equal(t, 2, activity.Count)
+}
+
+func TestActionActivity(t *testing.T) {
+	boom := fmt.Errorf("boom")
+
+	cases := []struct {
+		as   *ActionStatus
+		err  error
+		kind ActivityKind
+	}{
+		{as: &ActionStatus{}, kind: ActivityEvaluated},
+		{as: &ActionStatus{Delta: 2, Count: 5}, kind: ActivityScaled},
+		{as: &ActionStatus{}, err: boom, kind: ActivityFailed},
+		{as: &ActionStatus{Delta: 1, Count: 3}, err: boom, kind: ActivityFailed},
+		{as: &ActionStatus{}, err: ErrActionTimedOut, kind: ActivityTimedOut},
+	}
+
+	for _, c := range cases {
+		activity := actionActivity("g-1", c.as, c.err)
+		require.Equal(t, c.kind, activity.Kind, "%#v %v", c.as, c.err)
+		require.Equal(t, c.err, activity.Err)
+	}
+}
@@ -0,0 +1,455 @@
+package autoscale
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"pkg/ctxutil"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+const (
+	// EventScaleUp is the event of notifications for groups which grew.
+	EventScaleUp = "scale-up"
+
+	// EventScaleDown is the event of notifications for groups which shrank.
+	EventScaleDown = "scale-down"
+
+	// EventError is the event of notifications for actions which failed.
+	EventError = "error"
+
+	// EventDisabled is the event of notifications for groups which were disabled.
+	EventDisabled = "disabled"
+
+	// EventEnabled is the event of notifications for groups which were enabled again.
+	EventEnabled = "enabled"
+
+	// EventTimedOut is the event of notifications for actions which didn't finish in
+	// time.
+	EventTimedOut = "timed-out"
+
+	// SinkWebhook is the type of sinks which post notifications to an HTTP endpoint.
+	SinkWebhook = "webhook"
+
+	// SinkSlack is the type of sinks which post messages to a Slack incoming webhook.
+	SinkSlack = "slack"
+
+	// SinkEmail is the type of sinks which send email through an SMTP server.
+	SinkEmail = "email"
+
+	// redactedSecret replaces secrets in sink configs returned by the API.
+	redactedSecret = "REDACTED"
+)
+
+var (
+	// notificationEvents are the events sinks can filter on.
+	notificationEvents = map[string]bool{
+		EventScaleUp:                         true,
+		EventScaleDown:                       true,
+		EventError:                           true,
+		EventDisabled:                        true,
+		EventEnabled:                         true,
+		EventTimedOut:                        true,
+		string(ActivityFloatingIPReassigned): true,
+	}
+
+	// sinkAttempts is the amount of times delivering a notification to a sink is
+	// attempted before it is recorded as a dead letter.
+	sinkAttempts = 5
+
+	// sinkRetryDelay is the delay before the first retry of a delivery. It doubles
+	// after each attempt.
+	sinkRetryDelay = 2 * time.Second
+)
+
+// Sink delivers notifications outside of the dashboard.
+type Sink interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// DigestSink is a sink which can deliver several notifications as one message.
+type DigestSink interface {
+	Sink
+	SendDigest(ctx context.Context, ns []Notification) error
+}
+
+// SinkConfig configures a notification sink. A sink without events receives every
+// notification. Email sinks use an smtp:// URL for their server and send from From to
+// To. Sinks with a throttle collapse the notifications which arrive within Throttle
+// seconds of each other into one message.
+type SinkConfig struct {
+	Type     string   `json:"type"`
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret,omitempty"`
+	Events   []string `json:"events,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+	Throttle int      `json:"throttle,omitempty"`
+}
+
+// IsValid returns if the sink config is valid or not.
+func (sc SinkConfig) IsValid() bool {
+	u, err := url.Parse(sc.URL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	switch sc.Type {
+	case SinkWebhook:
+		if sc.Throttle != 0 {
+			return false
+		}
+		fallthrough
+	case SinkSlack:
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return false
+		}
+	case SinkEmail:
+		if u.Scheme != "smtp" || sc.From == "" || len(sc.To) == 0 {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if sc.Throttle < 0 {
+		return false
+	}
+
+	for _, e := range sc.Events {
+		if !notificationEvents[e] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Accepts returns true if the sink receives notifications for event.
+func (sc SinkConfig) Accepts(event string) bool {
+	if len(sc.Events) == 0 {
+		return true
+	}
+
+	for _, e := range sc.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String identifies the sink in logs and dead letters. Secrets and passwords are left
+// out.
+func (sc SinkConfig) String() string {
+	u, err := url.Parse(sc.URL)
+	if err != nil {
+		return sc.Type
+	}
+
+	return fmt.Sprintf("%s %s", sc.Type, u.Redacted())
+}
+
+// Redacted returns the config with its secret and the credentials in its URL replaced,
+// so it can be returned by the API. Slack webhook URLs are credentials themselves, so
+// only their host is kept.
+func (sc SinkConfig) Redacted() SinkConfig {
+	if sc.Secret != "" {
+		sc.Secret = redactedSecret
+	}
+
+	u, err := url.Parse(sc.URL)
+	if err != nil {
+		sc.URL = redactedSecret
+		return sc
+	}
+
+	if sc.Type == SinkSlack {
+		sc.URL = fmt.Sprintf("%s://%s/%s", u.Scheme, u.Host, redactedSecret)
+		return sc
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redactedSecret)
+	}
+
+	sc.URL = u.String()
+
+	return sc
+}
+
+func (sc SinkConfig) throttle() time.Duration {
+	return time.Duration(sc.Throttle) * time.Second
+}
+
+// SinkConfigs are the notification sinks of a group, or the global sinks which receive
+// notifications for every group.
+type SinkConfigs []SinkConfig
+
+// ParseSinkConfigs parses sink configs from JSON.
+func ParseSinkConfigs(s string) (SinkConfigs, error) {
+	if s == "" {
+		return SinkConfigs{}, nil
+	}
+
+	var scs SinkConfigs
+	if err := json.Unmarshal([]byte(s), &scs); err != nil {
+		return nil, err
+	}
+
+	if !scs.IsValid() {
+		return nil, fmt.Errorf("invalid notification sinks")
+	}
+
+	return scs, nil
+}
+
+// IsValid returns if the sink configs are valid or not.
+func (scs SinkConfigs) IsValid() bool {
+	for _, sc := range scs {
+		if !sc.IsValid() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Redacted returns the configs with their secrets and credentials replaced.
+func (scs SinkConfigs) Redacted() SinkConfigs {
+	if scs == nil {
+		return nil
+	}
+
+	redacted := make(SinkConfigs, len(scs))
+	for i, sc := range scs {
+		redacted[i] = sc.Redacted()
+	}
+
+	return redacted
+}
+
+// Restore puts back the secrets and URLs of configs which are unchanged from their
+// redacted form in stored, so updates which send back the redacted configs keep the
+// stored credentials. Configs are matched by position and type.
+func (scs SinkConfigs) Restore(stored SinkConfigs) SinkConfigs {
+	if scs == nil {
+		return nil
+	}
+
+	restored := make(SinkConfigs, len(scs))
+	for i, sc := range scs {
+		if i < len(stored) && stored[i].Type == sc.Type {
+			redacted := stored[i].Redacted()
+			if sc.Secret == redacted.Secret {
+				sc.Secret = stored[i].Secret
+			}
+			if sc.URL == redacted.URL {
+				sc.URL = stored[i].URL
+			}
+		}
+
+		restored[i] = sc
+	}
+
+	return restored
+}
+
+// Value converts sink configs to JSON to be stored in the database.
+func (scs SinkConfigs) Value() (driver.Value, error) {
+	if scs == nil {
+		scs = SinkConfigs{}
+	}
+
+	return json.Marshal(scs)
+}
+
+// Scan converts a DB value back into SinkConfigs.
+func (scs *SinkConfigs) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, scs)
+}
+
+// NewSink creates the sink described by a config.
+func NewSink(sc SinkConfig) (Sink, error) {
+	switch sc.Type {
+	case SinkWebhook:
+		return NewWebhookSink(sc.URL, sc.Secret), nil
+	case SinkSlack:
+		return NewSlackSink(sc.URL), nil
+	case SinkEmail:
+		return NewEmailSink(sc.URL, sc.From, sc.To)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sc.Type)
+	}
+}
+
+// permanentSinkError is returned by sinks when retrying a delivery won't help.
+type permanentSinkError struct {
+	err error
+}
+
+func (e *permanentSinkError) Error() string {
+	return e.err.Error()
+}
+
+// DeadLetter is a notification a sink could not deliver.
+type DeadLetter struct {
+	ID             string          `json:"id" db:"id"`
+	Sink           string          `json:"sink" db:"sink"`
+	GroupID        string          `json:"groupID" db:"group_id"`
+	NotificationID string          `json:"notificationID" db:"notification_id"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Error          string          `json:"error" db:"error"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// notifySink is a sink and the config it was created from. Notifications for throttled
+// sinks are held while the throttle window is open.
+type notifySink struct {
+	config SinkConfig
+	sink   Sink
+
+	mu      sync.Mutex
+	open    bool
+	pending []Notification
+}
+
+func newNotifySink(sc SinkConfig) (*notifySink, error) {
+	s, err := NewSink(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifySink{config: sc, sink: s}, nil
+}
+
+// enqueue delivers a notification to the sink. The first notification of a burst is
+// delivered right away. The ones which follow it within the throttle window are
+// delivered together when the window closes.
+func (ns *notifySink) enqueue(ctx context.Context, repo Repository, notif Notification) {
+	throttle := ns.config.throttle()
+	if throttle == 0 {
+		go deliver(ctx, repo, ns, notif)
+		return
+	}
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	if ns.open {
+		ns.pending = append(ns.pending, notif)
+		return
+	}
+
+	ns.open = true
+	go deliver(ctx, repo, ns, notif)
+	time.AfterFunc(throttle, func() { ns.flush(ctx, repo) })
+}
+
+// flush delivers the notifications held during a throttle window. The window stays open
+// for another period if there were any, so a burst which goes on keeps being collapsed.
+func (ns *notifySink) flush(ctx context.Context, repo Repository) {
+	ns.mu.Lock()
+
+	pending := ns.pending
+	ns.pending = nil
+
+	if len(pending) == 0 {
+		ns.open = false
+		ns.mu.Unlock()
+		return
+	}
+
+	time.AfterFunc(ns.config.throttle(), func() { ns.flush(ctx, repo) })
+	ns.mu.Unlock()
+
+	deliver(ctx, repo, ns, pending...)
+}
+
+// send sends notifications to the sink as one message if it supports digests.
+func (ns *notifySink) send(ctx context.Context, notifs []Notification) error {
+	if len(notifs) == 1 {
+		return ns.sink.Send(ctx, notifs[0])
+	}
+
+	if ds, ok := ns.sink.(DigestSink); ok {
+		return ds.SendDigest(ctx, notifs)
+	}
+
+	for _, notif := range notifs {
+		if err := ns.sink.Send(ctx, notif); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends notifications to a sink. Failed deliveries are retried with
+// exponential backoff unless the sink reports they can't succeed. Notifications which
+// aren't delivered are recorded as dead letters.
+func deliver(ctx context.Context, repo Repository, ns *notifySink, notifs ...Notification) {
+	log := ctxutil.LogFromContext(ctx).WithFields(logrus.Fields{
+		"sink":          ns.config.String(),
+		"notifications": len(notifs),
+	})
+
+	delay := sinkRetryDelay
+	attempts := 0
+
+	var err error
+	for attempts < sinkAttempts {
+		if attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		attempts++
+		if err = ns.send(ctx, notifs); err == nil {
+			return
+		}
+
+		log.WithError(err).WithField("attempt", attempts).Warn("unable to deliver notifications")
+
+		if _, ok := err.(*permanentSinkError); ok {
+			break
+		}
+	}
+
+	log.WithError(err).Error("recording undelivered notifications as dead letters")
+
+	for _, notif := range notifs {
+		payload, merr := json.Marshal(notif)
+		if merr != nil {
+			log.WithError(merr).Error("unable to encode dead letter")
+			continue
+		}
+
+		dl := DeadLetter{
+			Sink:           ns.config.String(),
+			GroupID:        notif.GroupID,
+			NotificationID: notif.ID,
+			Payload:        payload,
+			Error:          err.Error(),
+			Attempts:       attempts,
+			CreatedAt:      time.Now(),
+		}
+
+		if err := repo.AddDeadLetter(ctx, dl); err != nil {
+			log.WithError(err).WithField("notification-id", notif.ID).Error("unable to record dead letter")
+		}
+	}
+}
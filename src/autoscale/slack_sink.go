@@ -0,0 +1,58 @@
+package autoscale
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// SlackSink posts notifications as messages to a Slack incoming webhook.
+type SlackSink struct {
+	url    string
+	client *http.Client
+}
+
+var _ DigestSink = (*SlackSink)(nil)
+
+// NewSlackSink creates an instance of SlackSink.
+func NewSlackSink(url string) *SlackSink {
+	return &SlackSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Send posts a notification to Slack.
+func (s *SlackSink) Send(ctx context.Context, n Notification) error {
+	return s.post(n.Text())
+}
+
+// SendDigest posts several notifications to Slack as one message.
+func (s *SlackSink) SendDigest(ctx context.Context, ns []Notification) error {
+	return s.post(digestText(ns))
+}
+
+func (s *SlackSink) post(text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return &permanentSinkError{err: err}
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentSinkError{err: err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "do-autoscale")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return responseError("slack", resp.StatusCode)
+}
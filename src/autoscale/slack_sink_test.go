@@ -0,0 +1,58 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestSlackSink_Send(t *testing.T) {
+	ts := newTestWebhookServer(http.StatusOK)
+	defer ts.Close()
+
+	s := NewSlackSink(ts.URL)
+	require.NoError(t, s.Send(context.Background(), Notification{Name: "web", Event: EventScaleDown, Kind: string(ActivityScaled), Delta: -2, Count: 3}))
+
+	require.Equal(t, 1, ts.count())
+	assert.Equal(t, "application/json", ts.requests[0].Header.Get("Content-Type"))
+
+	var msg map[string]string
+	require.NoError(t, json.Unmarshal(ts.bodies[0], &msg))
+	assert.Equal(t, map[string]string{"text": "group web scaled 5→3"}, msg)
+}
+
+func TestSlackSink_SendDigest(t *testing.T) {
+	ts := newTestWebhookServer(http.StatusOK)
+	defer ts.Close()
+
+	notifs := []Notification{
+		{Name: "web", Event: EventScaleUp, Kind: string(ActivityScaled), Delta: 2, Count: 5},
+		{Name: "worker", Event: EventDisabled, Kind: string(ActivityDisabled), Message: "group was disabled"},
+	}
+
+	s := NewSlackSink(ts.URL)
+	require.NoError(t, s.SendDigest(context.Background(), notifs))
+
+	require.Equal(t, 1, ts.count())
+
+	var msg map[string]string
+	require.NoError(t, json.Unmarshal(ts.bodies[0], &msg))
+	assert.Equal(t, "2 autoscale notifications:\n• group web scaled 3→5\n• group worker was disabled", msg["text"])
+}
+
+func TestSlackSink_SendErrors(t *testing.T) {
+	ts := newTestWebhookServer(http.StatusForbidden)
+	defer ts.Close()
+
+	err := NewSlackSink(ts.URL).Send(context.Background(), Notification{Name: "web"})
+	require.Error(t, err)
+
+	_, permanent := err.(*permanentSinkError)
+	assert.True(t, permanent)
+	assert.Contains(t, err.Error(), "slack returned status 403")
+}
@@ -1,12 +1,21 @@
 package autoscale
 
 import (
+	"database/sql"
 	"pkg/ctxutil"
 	"time"
 
 	"golang.org/x/net/context"
 )
 
+// StatusActivityKinds are the kinds of activity Status should be registered for.
+var StatusActivityKinds = []ActivityKind{
+	ActivityScaled,
+	ActivityFailed,
+	ActivityDisabled,
+	ActivityTimedOut,
+}
+
 // Status manages status updates for groups.
 type Status struct {
 	ctx              context.Context
@@ -23,20 +32,47 @@ func NewStatus(ctx context.Context, repo Repository) *Status {
 	}
 }
 
+// Start records activities in the history of their group.
 func (s *Status) Start() {
 	for msg := range s.ActivityListener {
-		if msg.Err == nil && msg.Delta != 0 {
-			gs := GroupStatus{
-				GroupID:   msg.ID,
-				Delta:     msg.Delta,
-				Total:     msg.Count,
-				CreatedAt: time.Now(),
-			}
+		log := ctxutil.LogFromContext(s.ctx).WithField("group-id", msg.ID)
 
-			if err := s.repo.AddGroupStatus(s.ctx, gs); err != nil {
-				log := ctxutil.LogFromContext(s.ctx)
-				log.WithError(err).Error("unable to add group status")
+		gs := GroupStatus{
+			GroupID:   msg.ID,
+			Kind:      msg.Kind,
+			Delta:     msg.Delta,
+			Total:     msg.Count,
+			Decision:  msg.Decision,
+			CreatedAt: time.Now(),
+		}
+
+		if msg.Err != nil {
+			gs.Error = msg.Err.Error()
+
+			total, err := s.lastTotal(msg.ID)
+			if err != nil {
+				log.WithError(err).Error("unable to load group status")
 			}
+			gs.Total = total + msg.Delta
+		}
+
+		if err := s.repo.AddGroupStatus(s.ctx, gs); err != nil {
+			log.WithError(err).Error("unable to add group status")
 		}
 	}
 }
+
+// lastTotal returns the group's size as of its last status. Failed actions don't know
+// the size of their group.
+func (s *Status) lastTotal(groupID string) (int, error) {
+	gs, err := s.repo.GetGroupStatus(s.ctx, groupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return gs.Total, nil
+}
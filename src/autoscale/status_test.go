@@ -0,0 +1,52 @@
+package autoscale
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func TestStatus_RecordsFailures(t *testing.T) {
+	repo := &MockRepository{}
+	repo.On("GetGroupStatus", mock.Anything, "g-1").Return(&GroupStatus{GroupID: "g-1", Total: 3}, nil)
+	repo.On("GetGroupStatus", mock.Anything, "g-2").Return(nil, sql.ErrNoRows)
+	repo.On("AddGroupStatus", mock.Anything, mock.AnythingOfType("GroupStatus")).Return(nil)
+
+	s := NewStatus(context.Background(), repo)
+	done := make(chan bool)
+	go func() {
+		s.Start()
+		done <- true
+	}()
+
+	s.ActivityListener <- SchedulerActivity{ID: "g-1", Kind: ActivityScaled, Delta: 2, Count: 3}
+	s.ActivityListener <- SchedulerActivity{ID: "g-1", Kind: ActivityFailed, Err: fmt.Errorf("unable to create droplet")}
+	s.ActivityListener <- SchedulerActivity{ID: "g-2", Kind: ActivityTimedOut, Err: ErrActionTimedOut}
+	close(s.ActivityListener)
+	<-done
+
+	stored := []GroupStatus{}
+	for _, call := range repo.Calls {
+		if call.Method == "AddGroupStatus" {
+			stored = append(stored, call.Arguments.Get(1).(GroupStatus))
+		}
+	}
+
+	require.Len(t, stored, 3)
+	require.Equal(t, ActivityScaled, stored[0].Kind)
+	require.Equal(t, 3, stored[0].Total)
+	require.Empty(t, stored[0].Error)
+
+	require.Equal(t, ActivityFailed, stored[1].Kind)
+	require.Equal(t, 3, stored[1].Total)
+	require.Equal(t, "unable to create droplet", stored[1].Error)
+
+	require.Equal(t, ActivityTimedOut, stored[2].Kind)
+	require.Equal(t, 0, stored[2].Total)
+	require.Equal(t, ErrActionTimedOut.Error(), stored[2].Error)
+}
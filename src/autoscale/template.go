@@ -3,18 +3,45 @@ package autoscale
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"pkg/do"
+	"pkg/doclient"
+	"regexp"
 	"strconv"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	"golang.org/x/net/context"
+)
+
+const (
+	maxTagLength           = 255
+	maxTemplateVolumes     = 7
+	maxVolumeSizeGigabytes = 16384
 )
 
+var tagRe = regexp.MustCompile(`^[a-zA-Z0-9_\-:]+$`)
+
 // Template is a template that will be autoscaled.
 type Template struct {
 	ID       string  `json:"id" db:"id"`
 	Name     string  `json:"name" db:"name"`
 	Region   string  `json:"region" db:"region"`
 	Size     string  `json:"size" db:"size"`
-	Image    string  `json:"image" db:"image"`
+	Image    string  `json:"image" db:"image"` // image slug or id
 	SSHKeys  SSHKeys `json:"sshKeys" db:"ssh_keys"`
 	UserData string  `json:"userData" db:"user_data"`
+	Version  int     `json:"version" db:"version"`
+
+	PrivateNetworking bool            `json:"privateNetworking" db:"private_networking"`
+	IPv6              bool            `json:"ipv6" db:"ipv6"`
+	Monitoring        bool            `json:"monitoring" db:"monitoring"`
+	Backups           bool            `json:"backups" db:"backups"`
+	Tags              TemplateTags    `json:"tags" db:"tags"`
+	Volumes           TemplateVolumes `json:"volumes" db:"volumes"`
+	Agent             Agent           `json:"agent" db:"agent"`
+	FallbackSizes     TemplateSizes   `json:"fallbackSizes" db:"fallback_sizes"`
 }
 
 // IsValid returns if the template is valid or not.
@@ -26,6 +53,192 @@ func (t *Template) IsValid() bool {
 	return true
 }
 
+// ImageID returns the id of the template's image. It returns 0 if the image is referenced
+// by slug.
+func (t *Template) ImageID() int {
+	id, err := strconv.Atoi(t.Image)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+// dropletCreateImage returns the image droplets built from the template are created with.
+func (t *Template) dropletCreateImage() godo.DropletCreateImage {
+	if id := t.ImageID(); id > 0 {
+		return godo.DropletCreateImage{ID: id}
+	}
+
+	return godo.DropletCreateImage{Slug: t.Image}
+}
+
+// TemplateFieldError is a template field which isn't valid.
+type TemplateFieldError struct {
+	Field  string
+	Detail string
+}
+
+func (e TemplateFieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Detail)
+}
+
+// Validate checks the template against the regions, sizes, and keys available in uc.
+// image is the template's image, or nil if it doesn't exist.
+func (t *Template) Validate(uc *UserConfig, image *do.Image) []TemplateFieldError {
+	errs := []TemplateFieldError{}
+
+	if !t.IsValid() {
+		errs = append(errs, TemplateFieldError{Field: "name", Detail: "must be alphanumeric and may contain dashes"})
+	}
+
+	var region *do.Region
+	for i := range uc.Regions {
+		if uc.Regions[i].Slug == t.Region {
+			region = &uc.Regions[i]
+			break
+		}
+	}
+
+	switch {
+	case region == nil:
+		errs = append(errs, TemplateFieldError{Field: "region", Detail: fmt.Sprintf("%q does not exist", t.Region)})
+	case !region.Available:
+		errs = append(errs, TemplateFieldError{Field: "region", Detail: fmt.Sprintf("%q is not available", t.Region)})
+	}
+
+	validateSize := func(field, slug string) {
+		var size *do.Size
+		for i := range uc.Sizes {
+			if uc.Sizes[i].Slug == slug {
+				size = &uc.Sizes[i]
+				break
+			}
+		}
+
+		switch {
+		case size == nil:
+			errs = append(errs, TemplateFieldError{Field: field, Detail: fmt.Sprintf("%q does not exist", slug)})
+		case !size.Available:
+			errs = append(errs, TemplateFieldError{Field: field, Detail: fmt.Sprintf("%q is not available", slug)})
+		case region != nil && !containsString(region.Sizes, slug):
+			errs = append(errs, TemplateFieldError{Field: field, Detail: fmt.Sprintf("%q is not available in %q", slug, t.Region)})
+		}
+	}
+
+	validateSize("size", t.Size)
+
+	seen := map[string]bool{t.Size: true}
+	for _, slug := range t.FallbackSizes {
+		if seen[slug] {
+			errs = append(errs, TemplateFieldError{Field: "fallbackSizes", Detail: fmt.Sprintf("%q is listed more than once", slug)})
+			continue
+		}
+
+		seen[slug] = true
+		validateSize("fallbackSizes", slug)
+	}
+
+	switch {
+	case image == nil:
+		errs = append(errs, TemplateFieldError{Field: "image", Detail: fmt.Sprintf("%q does not exist", t.Image)})
+	case region != nil && !containsString(image.Regions, t.Region):
+		errs = append(errs, TemplateFieldError{Field: "image", Detail: fmt.Sprintf("%q is not available in %q", t.Image, t.Region)})
+	}
+
+	if region != nil {
+		features := []struct {
+			enabled bool
+			field   string
+			feature string
+		}{
+			{t.PrivateNetworking, "privateNetworking", "private_networking"},
+			{t.IPv6, "ipv6", "ipv6"},
+			{t.Backups, "backups", "backups"},
+			{t.Monitoring, "monitoring", "install_agent"},
+			{len(t.Volumes) > 0, "volumes", "storage"},
+		}
+
+		for _, f := range features {
+			if f.enabled && !containsString(region.Features, f.feature) {
+				errs = append(errs, TemplateFieldError{Field: f.field, Detail: fmt.Sprintf("is not supported in %q", t.Region)})
+			}
+		}
+	}
+
+	for _, tag := range t.Tags {
+		switch {
+		case !tagRe.MatchString(tag) || len(tag) > maxTagLength:
+			errs = append(errs, TemplateFieldError{Field: "tags", Detail: fmt.Sprintf("%q is not a valid tag", tag)})
+		case tag == BaseTag || strings.HasPrefix(tag, BaseTag+":"):
+			errs = append(errs, TemplateFieldError{Field: "tags", Detail: fmt.Sprintf("%q is reserved for autoscale", tag)})
+		}
+	}
+
+	if len(t.Volumes) > maxTemplateVolumes {
+		errs = append(errs, TemplateFieldError{Field: "volumes", Detail: fmt.Sprintf("can not contain more than %d volumes", maxTemplateVolumes)})
+	}
+
+	for _, v := range t.Volumes {
+		if v.SizeGigabytes < 1 || v.SizeGigabytes > maxVolumeSizeGigabytes {
+			errs = append(errs, TemplateFieldError{Field: "volumes", Detail: fmt.Sprintf("size must be between 1 and %d gigabytes", maxVolumeSizeGigabytes)})
+		}
+	}
+
+	errs = append(errs, t.Agent.Validate()...)
+
+	if _, err := parseUserData(t.UserData); err != nil {
+		errs = append(errs, TemplateFieldError{Field: "userData", Detail: fmt.Sprintf("is not a valid template: %v", err)})
+	}
+
+	keys := map[int]bool{}
+	for _, k := range uc.Keys {
+		keys[k.ID] = true
+	}
+
+	for _, k := range t.SSHKeys {
+		if !keys[k.ID] {
+			errs = append(errs, TemplateFieldError{Field: "sshKeys", Detail: fmt.Sprintf("key %d does not exist", k.ID)})
+		}
+	}
+
+	return errs
+}
+
+// ValidateTemplate fetches the DigitalOcean catalog using dc and validates t against it.
+func ValidateTemplate(ctx context.Context, dc *doclient.Client, t Template) ([]TemplateFieldError, error) {
+	uc, err := NewUserConfig(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+
+	var image *do.Image
+	if id := t.ImageID(); id > 0 {
+		image, err = dc.ImagesService.GetByID(id)
+	} else {
+		image, err = dc.ImagesService.GetBySlug(t.Image)
+	}
+	if err != nil {
+		if er, ok := err.(*godo.ErrorResponse); !ok || er.Response == nil || er.Response.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+
+		image = nil
+	}
+
+	return t.Validate(uc, image), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (t *Template) GetName() string {
 	return "template"
 }
@@ -63,3 +276,91 @@ func (s *SSHKeys) Scan(src interface{}) error {
 
 	return json.Unmarshal(b, s)
 }
+
+// TemplateTags are extra tags applied to droplets built from a template.
+type TemplateTags []string
+
+// Value converts template tags to JSON to be stored in the database.
+func (tt TemplateTags) Value() (driver.Value, error) {
+	if tt == nil {
+		tt = TemplateTags{}
+	}
+
+	return json.Marshal(tt)
+}
+
+// Scan converts a DB value back into TemplateTags.
+func (tt *TemplateTags) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, tt)
+}
+
+// TemplateSizes are droplet size slugs.
+type TemplateSizes []string
+
+// Value converts template sizes to JSON to be stored in the database.
+func (ts TemplateSizes) Value() (driver.Value, error) {
+	if ts == nil {
+		ts = TemplateSizes{}
+	}
+
+	return json.Marshal(ts)
+}
+
+// Scan converts a DB value back into TemplateSizes.
+func (ts *TemplateSizes) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, ts)
+}
+
+// sizes returns the sizes a droplet can be created with in priority order: the
+// template's size followed by its fallback sizes.
+func (t *Template) sizes() []string {
+	sizes := []string{t.Size}
+	for _, s := range t.FallbackSizes {
+		if !containsString(sizes, s) {
+			sizes = append(sizes, s)
+		}
+	}
+
+	return sizes
+}
+
+// TemplateVolume is a block storage volume which is created and attached to each
+// droplet built from a template.
+type TemplateVolume struct {
+	SizeGigabytes int `json:"sizeGigabytes"`
+}
+
+// TemplateVolumes is a slice of template volumes.
+type TemplateVolumes []TemplateVolume
+
+// Value converts template volumes to JSON to be stored in the database.
+func (tv TemplateVolumes) Value() (driver.Value, error) {
+	if tv == nil {
+		tv = TemplateVolumes{}
+	}
+
+	return json.Marshal(tv)
+}
+
+// Scan converts a DB value back into TemplateVolumes.
+func (tv *TemplateVolumes) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	b := json.RawMessage(src.([]uint8))
+
+	return json.Unmarshal(b, tv)
+}
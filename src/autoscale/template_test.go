@@ -1,9 +1,16 @@
 package autoscale
 
 import (
+	"net/http"
+	"pkg/do"
+	"pkg/do/mocks"
+	"pkg/doclient"
 	"testing"
 
+	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
 )
 
 func TestTemplate_IsValid(t *testing.T) {
@@ -24,3 +31,202 @@ func TestTemplate_IsValid(t *testing.T) {
 		assert.Equal(t, c.IsValid, tmpl.IsValid())
 	}
 }
+
+func testUserConfig() *UserConfig {
+	return &UserConfig{
+		Regions: do.Regions{
+			{Region: &godo.Region{Slug: "nyc1", Sizes: []string{"512mb", "1gb"}, Available: true,
+				Features: []string{"private_networking", "ipv6", "backups", "install_agent", "storage"}}},
+			{Region: &godo.Region{Slug: "sfo1", Sizes: []string{"512mb"}, Available: false}},
+			{Region: &godo.Region{Slug: "ams1", Sizes: []string{"1gb"}, Available: true}},
+		},
+		Sizes: do.Sizes{
+			{Size: &godo.Size{Slug: "512mb", Available: true}},
+			{Size: &godo.Size{Slug: "1gb", Available: true}},
+			{Size: &godo.Size{Slug: "2gb", Available: true}},
+			{Size: &godo.Size{Slug: "4gb", Available: false}},
+		},
+		Keys: do.SSHKeys{
+			{Key: &godo.Key{ID: 1}},
+		},
+	}
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	image := &do.Image{Image: &godo.Image{Slug: "ubuntu-14-04-x64", Regions: []string{"nyc1", "sfo1"}}}
+
+	cases := []struct {
+		name   string
+		tmpl   Template
+		image  *do.Image
+		fields []string
+	}{
+		{
+			name:  "valid",
+			tmpl:  Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", SSHKeys: SSHKeys{{ID: 1}}},
+			image: image,
+		},
+		{
+			name:   "invalid name",
+			tmpl:   Template{Name: "-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"name"},
+		},
+		{
+			name:   "unknown region",
+			tmpl:   Template{Name: "a-template", Region: "xxx1", Size: "1gb", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"region"},
+		},
+		{
+			name:   "unavailable region",
+			tmpl:   Template{Name: "a-template", Region: "sfo1", Size: "512mb", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"region"},
+		},
+		{
+			name:   "unknown size",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "xl", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"size"},
+		},
+		{
+			name:   "unavailable size",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "4gb", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"size"},
+		},
+		{
+			name:   "size not in region",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "2gb", Image: "ubuntu-14-04-x64"},
+			image:  image,
+			fields: []string{"size"},
+		},
+		{
+			name:  "fallback sizes",
+			tmpl:  Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", FallbackSizes: TemplateSizes{"512mb"}},
+			image: image,
+		},
+		{
+			name:   "invalid fallback sizes",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", FallbackSizes: TemplateSizes{"1gb", "2gb", "xl"}},
+			image:  image,
+			fields: []string{"fallbackSizes", "fallbackSizes", "fallbackSizes"},
+		},
+		{
+			name:   "unknown image",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "missing"},
+			fields: []string{"image"},
+		},
+		{
+			name:   "image not in region",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64"},
+			image:  &do.Image{Image: &godo.Image{Slug: "ubuntu-14-04-x64", Regions: []string{"sfo1"}}},
+			fields: []string{"image"},
+		},
+		{
+			name: "options",
+			tmpl: Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64",
+				PrivateNetworking: true, IPv6: true, Backups: true, Monitoring: true,
+				Tags: TemplateTags{"web", "env:prod"}, Volumes: TemplateVolumes{{SizeGigabytes: 100}}},
+			image: image,
+		},
+		{
+			name: "unsupported options",
+			tmpl: Template{Name: "a-template", Region: "ams1", Size: "1gb", Image: "ubuntu-14-04-x64",
+				PrivateNetworking: true, Monitoring: true, Volumes: TemplateVolumes{{SizeGigabytes: 100}}},
+			image:  &do.Image{Image: &godo.Image{Slug: "ubuntu-14-04-x64", Regions: []string{"ams1"}}},
+			fields: []string{"privateNetworking", "monitoring", "volumes"},
+		},
+		{
+			name:   "invalid tags",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", Tags: TemplateTags{"a tag", "autoscale:protected"}},
+			image:  image,
+			fields: []string{"tags", "tags"},
+		},
+		{
+			name:   "invalid volume size",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", Volumes: TemplateVolumes{{SizeGigabytes: 0}}},
+			image:  image,
+			fields: []string{"volumes"},
+		},
+		{
+			name:   "invalid user data template",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", UserData: "## template: go\n{{.GroupName"},
+			image:  image,
+			fields: []string{"userData"},
+		},
+		{
+			name:  "user data which isn't a template",
+			tmpl:  Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", UserData: "{{.GroupName"},
+			image: image,
+		},
+		{
+			name:   "unknown key",
+			tmpl:   Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "ubuntu-14-04-x64", SSHKeys: SSHKeys{{ID: 1}, {ID: 2}}},
+			image:  image,
+			fields: []string{"sshKeys"},
+		},
+	}
+
+	for _, c := range cases {
+		errs := c.tmpl.Validate(testUserConfig(), c.image)
+
+		fields := []string{}
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+
+		if c.fields == nil {
+			c.fields = []string{}
+		}
+		assert.Equal(t, c.fields, fields, c.name)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	rs := &mocks.RegionsService{}
+	rs.On("List").Return(testUserConfig().Regions, nil)
+	ss := &mocks.SizesService{}
+	ss.On("List").Return(testUserConfig().Sizes, nil)
+	ks := &mocks.KeysService{}
+	ks.On("List").Return(testUserConfig().Keys, nil)
+	as := &mocks.AccountService{}
+	as.On("Get").Return(&do.Account{Account: &godo.Account{UUID: "1"}}, nil)
+
+	notFound := &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	is := &mocks.ImagesService{}
+	is.On("ListUser", false).Return(do.Images{}, nil)
+	is.On("GetBySlug", "missing").Return(nil, notFound)
+	is.On("GetByID", 1234).Return(&do.Image{Image: &godo.Image{ID: 1234, Regions: []string{"nyc1"}}}, nil)
+
+	dc := &doclient.Client{
+		RegionsService:  rs,
+		SizesService:    ss,
+		KeysService:     ks,
+		AccountsService: as,
+		ImagesService:   is,
+	}
+
+	tmpl := Template{Name: "a-template", Region: "nyc1", Size: "1gb", Image: "missing"}
+	errs, err := ValidateTemplate(context.Background(), dc, tmpl)
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "image", errs[0].Field)
+
+	tmpl.Image = "1234"
+	errs, err = ValidateTemplate(context.Background(), dc, tmpl)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	assert.True(t, is.AssertExpectations(t))
+}
+
+func TestTemplate_DropletCreateImage(t *testing.T) {
+	tmpl := Template{Image: "ubuntu-14-04-x64"}
+	assert.Equal(t, godo.DropletCreateImage{Slug: "ubuntu-14-04-x64"}, tmpl.dropletCreateImage())
+
+	tmpl.Image = "1234"
+	assert.Equal(t, 1234, tmpl.ImageID())
+	assert.Equal(t, godo.DropletCreateImage{ID: 1234}, tmpl.dropletCreateImage())
+}
@@ -18,10 +18,11 @@ type UserConfigResponse struct {
 
 // UserConfig is the DO configuration for a user.
 type UserConfig struct {
-	ID      string     `json:"id"`
-	Regions do.Regions `json:"regions"`
-	Sizes   do.Sizes   `json:"sizes"`
-	Keys    do.SSHKeys `json:"keys"`
+	ID        string     `json:"id"`
+	Regions   do.Regions `json:"regions"`
+	Sizes     do.Sizes   `json:"sizes"`
+	Keys      do.SSHKeys `json:"keys"`
+	Snapshots do.Images  `json:"snapshots"`
 }
 
 var _ jsonapi.MarshalIdentifier = (*UserConfig)(nil)
@@ -33,28 +34,24 @@ func NewUserConfig(ctx context.Context, dc *doclient.Client) (*UserConfig, error
 	uc := &UserConfig{}
 	errs := []error{}
 
+	var mu sync.Mutex
 	wg := sync.WaitGroup{}
-	wg.Add(4)
 
-	go func() {
-		defer wg.Done()
-		getRegions(log, dc, uc)
-	}()
-
-	go func() {
-		defer wg.Done()
-		getSizes(log, dc, uc)
-	}()
-
-	go func() {
-		defer wg.Done()
-		getKeys(log, dc, uc)
-	}()
-
-	go func() {
-		defer wg.Done()
-		getID(log, dc, uc)
-	}()
+	fetchers := []func(*logrus.Entry, *doclient.Client, *UserConfig) error{
+		getRegions, getSizes, getKeys, getSnapshots, getID,
+	}
+	wg.Add(len(fetchers))
+
+	for _, fetch := range fetchers {
+		go func(fetch func(*logrus.Entry, *doclient.Client, *UserConfig) error) {
+			defer wg.Done()
+			if err := fetch(log, dc, uc); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(fetch)
+	}
 
 	wg.Wait()
 
@@ -100,6 +97,16 @@ func getKeys(log *logrus.Entry, dc *doclient.Client, uc *UserConfig) error {
 	return nil
 }
 
+func getSnapshots(log *logrus.Entry, dc *doclient.Client, uc *UserConfig) error {
+	snapshots, err := dc.ImagesService.ListUser(false)
+	if err != nil {
+		return err
+	}
+
+	uc.Snapshots = snapshots
+	return nil
+}
+
 func getID(log *logrus.Entry, dc *doclient.Client, uc *UserConfig) error {
 	a, err := dc.AccountsService.Get()
 	if err != nil {
@@ -32,6 +32,12 @@ func TestUserConfig(t *testing.T) {
 	}
 	ks.On("List").Return(keys, nil)
 
+	is := &mocks.ImagesService{}
+	snapshots := do.Images{
+		{},
+	}
+	is.On("ListUser", false).Return(snapshots, nil)
+
 	as := &mocks.AccountService{}
 	a := &do.Account{
 		Account: &godo.Account{UUID: "1"},
@@ -42,6 +48,7 @@ func TestUserConfig(t *testing.T) {
 		RegionsService:  rs,
 		SizesService:    ss,
 		KeysService:     ks,
+		ImagesService:   is,
 		AccountsService: as,
 	}
 
@@ -53,9 +60,11 @@ func TestUserConfig(t *testing.T) {
 	assert.Len(t, uc.Regions, 2)
 	assert.Len(t, uc.Sizes, 2)
 	assert.Len(t, uc.Keys, 2)
+	assert.Len(t, uc.Snapshots, 1)
 
 	assert.True(t, rs.AssertExpectations(t))
 	assert.True(t, ss.AssertExpectations(t))
 	assert.True(t, ks.AssertExpectations(t))
+	assert.True(t, is.AssertExpectations(t))
 	assert.True(t, as.AssertExpectations(t))
 }
@@ -0,0 +1,128 @@
+package autoscale
+
+import (
+	"bytes"
+	"fmt"
+	"pkg/cloudinit"
+	"strings"
+	"text/template"
+)
+
+// UserDataVars are the values available when user data which starts with
+// userDataTemplateHeader is rendered for a droplet. Index is the droplet's position in
+// the scale up which created it, so it starts at 0 for every scale up and isn't unique
+// within a group. Use DropletName to tell droplets apart.
+type UserDataVars struct {
+	GroupID         string
+	GroupName       string
+	DropletName     string
+	Index           int
+	Region          string
+	TemplateID      string
+	TemplateVersion int
+	Vars            GroupVars
+}
+
+// userDataTemplateHeader is the first line of user data which is rendered as a Go
+// template. Other user data is used as it is, so scripts which contain {{ }} for other
+// tools keep working.
+const userDataTemplateHeader = "## template: go"
+
+// userDataTemplate returns user data without its template header, and true if it has
+// one.
+func userDataTemplate(userData string) (string, bool) {
+	line, rest := userData, ""
+	if i := strings.Index(userData, "\n"); i >= 0 {
+		line, rest = userData[:i], userData[i+1:]
+	}
+
+	if strings.TrimSpace(line) != userDataTemplateHeader {
+		return "", false
+	}
+
+	return rest, true
+}
+
+// parseUserData parses user data with a template header as a Go template. It returns nil
+// for user data without a header. Referencing a group variable which doesn't exist is an
+// error when the user data is rendered.
+func parseUserData(userData string) (*template.Template, error) {
+	body, ok := userDataTemplate(userData)
+	if !ok {
+		return nil, nil
+	}
+
+	return template.New("userData").Option("missingkey=error").Parse(body)
+}
+
+// validUserData returns true if user data either isn't a template or parses as one.
+func validUserData(userData string) bool {
+	_, err := parseUserData(userData)
+	return err == nil
+}
+
+// RenderUserData renders the template's user data for a droplet.
+func (t *Template) RenderUserData(vars UserDataVars) (string, error) {
+	return renderUserData(t.UserData, vars)
+}
+
+// RenderUserData renders the group's user data for a droplet.
+func (g *Group) RenderUserData(vars UserDataVars) (string, error) {
+	return renderUserData(g.UserData, vars)
+}
+
+// renderUserData renders user data for a droplet. User data without a template header is
+// returned as it is.
+func renderUserData(userData string, vars UserDataVars) (string, error) {
+	tmpl, err := parseUserData(userData)
+	if err != nil {
+		return "", err
+	}
+
+	if tmpl == nil {
+		return userData, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// buildUserData composes the cloud-init payload for a droplet. The agent bootstrap is
+// the system layer, followed by the template and group layers. Cloud-config documents
+// from each layer are merged, with later layers taking precedence.
+func buildUserData(agent Agent, templateUserData, groupUserData string) (string, error) {
+	bootstrap, err := agent.Bootstrap()
+	if err != nil {
+		return "", fmt.Errorf("unable to build agent bootstrap: %v", err)
+	}
+
+	layers := []struct {
+		name     string
+		userData string
+	}{
+		{name: "system", userData: bootstrap},
+		{name: "template", userData: templateUserData},
+		{name: "group", userData: groupUserData},
+	}
+
+	ci := cloudinit.New()
+	for i, l := range layers {
+		if len(l.userData) == 0 {
+			continue
+		}
+
+		if err := ci.AddUserData(fmt.Sprintf("ud%d.txt", i+1), l.userData); err != nil {
+			return "", fmt.Errorf("invalid %s user data: %v", l.name, err)
+		}
+	}
+
+	if err := ci.Close(); err != nil {
+		return "", err
+	}
+
+	return ci.String(), nil
+}
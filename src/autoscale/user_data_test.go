@@ -0,0 +1,81 @@
+package autoscale
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_RenderUserData(t *testing.T) {
+	tmpl := Template{
+		UserData: "## template: go\n" + `register {{.Vars.service}} {{.DropletName}} {{.Index}} {{.GroupName}} {{.Region}} {{.TemplateVersion}}`,
+	}
+
+	vars := UserDataVars{
+		GroupID:         "group-id",
+		GroupName:       "web",
+		DropletName:     "web-abcde",
+		Index:           2,
+		Region:          "nyc1",
+		TemplateID:      "tmpl",
+		TemplateVersion: 3,
+		Vars:            GroupVars{"service": "frontend"},
+	}
+
+	userData, err := tmpl.RenderUserData(vars)
+	require.NoError(t, err)
+	assert.Equal(t, "register frontend web-abcde 2 web nyc1 3", userData)
+}
+
+func TestTemplate_RenderUserData_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		userData string
+	}{
+		{name: "invalid syntax", userData: "## template: go\n{{.GroupName"},
+		{name: "missing variable", userData: "## template: go\n{{.Vars.missing}}"},
+		{name: "unknown field", userData: "## template: go\n{{.Unknown}}"},
+	}
+
+	for _, c := range cases {
+		tmpl := Template{UserData: c.userData}
+		_, err := tmpl.RenderUserData(UserDataVars{Vars: GroupVars{}})
+		assert.Error(t, err, c.name)
+	}
+}
+
+func TestTemplate_RenderUserData_NotTemplate(t *testing.T) {
+	tmpl := Template{UserData: "#!/bin/sh\ndocker inspect --format '{{.State.Running}}' web"}
+
+	userData, err := tmpl.RenderUserData(UserDataVars{})
+	require.NoError(t, err)
+	assert.Equal(t, tmpl.UserData, userData)
+}
+
+func TestBuildUserData(t *testing.T) {
+	templateUserData := "#cloud-config\npackages:\n  - nginx\n"
+	groupUserData := "#cloud-config\npackages:\n  - redis-server\n"
+
+	userData, err := buildUserData(Agent{Type: AgentNone}, templateUserData, groupUserData)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(userData, "Content-Type: text/cloud-config"))
+	assert.Contains(t, userData, "- nginx\n- redis-server\n")
+	assert.NotContains(t, userData, "text/x-shellscript")
+}
+
+func TestBuildUserData_Layers(t *testing.T) {
+	userData, err := buildUserData(Agent{}.WithDefaults(), "#!/bin/bash\necho template\n", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, strings.Count(userData, "text/x-shellscript"))
+	assert.Contains(t, userData, "echo template")
+}
+
+func TestBuildUserData_InvalidCloudConfig(t *testing.T) {
+	_, err := buildUserData(Agent{Type: AgentNone}, "", "#cloud-config\npackages: [nginx\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid group user data")
+}
@@ -0,0 +1,105 @@
+package autoscale
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// WebhookSignatureHeader holds the hex encoded HMAC-SHA256 of a webhook's body,
+	// prefixed with "sha256=". It is only sent when the webhook has a secret.
+	WebhookSignatureHeader = "X-Autoscale-Signature"
+
+	// WebhookEventHeader holds the event of a webhook's notification.
+	WebhookEventHeader = "X-Autoscale-Event"
+
+	// WebhookDeliveryHeader holds the ID of a webhook's notification. It is the same
+	// for every attempt to deliver the notification.
+	WebhookDeliveryHeader = "X-Autoscale-Delivery"
+)
+
+// webhookTimeout is how long a webhook endpoint has to respond.
+var webhookTimeout = 10 * time.Second
+
+// WebhookSink posts notifications as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+var _ Sink = (*WebhookSink)(nil)
+
+// NewWebhookSink creates an instance of WebhookSink. If secret isn't empty, payloads are
+// signed with it.
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Send posts a notification to the webhook. Responses other than 2xx are errors. Client
+// errors, except for timeouts and rate limits, won't succeed when retried.
+func (s *WebhookSink) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return &permanentSinkError{err: err}
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentSinkError{err: err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "do-autoscale")
+	req.Header.Set(WebhookEventHeader, n.Event)
+	req.Header.Set(WebhookDeliveryHeader, n.ID)
+	if s.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return responseError("webhook", resp.StatusCode)
+}
+
+// responseError returns the error for an HTTP sink's response status, or nil if it was
+// successful. Client errors, except for timeouts and rate limits, are permanent.
+func responseError(name string, status int) error {
+	if status >= 200 && status < 300 {
+		return nil
+	}
+
+	err := fmt.Errorf("%s returned status %d", name, status)
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return err
+	case status >= 400 && status < 500:
+		return &permanentSinkError{err: err}
+	default:
+		return err
+	}
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of body using secret. Receivers can
+// compare it with the signature header to verify a webhook.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
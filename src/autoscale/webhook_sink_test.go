@@ -0,0 +1,180 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+// testWebhookServer records the requests it receives and responds with the statuses in
+// order, repeating the last one.
+type testWebhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newTestWebhookServer(statuses ...int) *testWebhookServer {
+	ts := &testWebhookServer{statuses: statuses}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+
+		ts.requests = append(ts.requests, r)
+		ts.bodies = append(ts.bodies, body)
+
+		status := ts.statuses[0]
+		if len(ts.statuses) > 1 {
+			ts.statuses = ts.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+	}))
+
+	return ts
+}
+
+func (ts *testWebhookServer) count() int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return len(ts.requests)
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	ts := newTestWebhookServer(http.StatusOK)
+	defer ts.Close()
+
+	notif := Notification{ID: "n-1", GroupID: "g-1", Name: "web", Event: EventScaleUp, Delta: 2, Count: 5}
+
+	s := NewWebhookSink(ts.URL, "secret")
+	require.NoError(t, s.Send(context.Background(), notif))
+
+	require.Equal(t, 1, ts.count())
+	req, body := ts.requests[0], ts.bodies[0]
+
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, EventScaleUp, req.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "n-1", req.Header.Get(WebhookDeliveryHeader))
+	assert.Equal(t, "sha256="+SignWebhook("secret", body), req.Header.Get(WebhookSignatureHeader))
+
+	var got Notification
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, notif, got)
+}
+
+func TestWebhookSink_SendUnsigned(t *testing.T) {
+	ts := newTestWebhookServer(http.StatusNoContent)
+	defer ts.Close()
+
+	s := NewWebhookSink(ts.URL, "")
+	require.NoError(t, s.Send(context.Background(), Notification{ID: "n-1"}))
+	assert.Empty(t, ts.requests[0].Header.Get(WebhookSignatureHeader))
+}
+
+func TestWebhookSink_SendErrors(t *testing.T) {
+	cases := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: http.StatusInternalServerError, permanent: false},
+		{status: http.StatusTooManyRequests, permanent: false},
+		{status: http.StatusBadRequest, permanent: true},
+		{status: http.StatusNotFound, permanent: true},
+	}
+
+	for _, c := range cases {
+		ts := newTestWebhookServer(c.status)
+
+		err := NewWebhookSink(ts.URL, "").Send(context.Background(), Notification{})
+		require.Error(t, err)
+
+		_, permanent := err.(*permanentSinkError)
+		assert.Equal(t, c.permanent, permanent, "status %d", c.status)
+
+		ts.Close()
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n 'payload' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4", SignWebhook("secret", []byte("payload")))
+}
+
+func TestDeliver_Retries(t *testing.T) {
+	delay := sinkRetryDelay
+	sinkRetryDelay = time.Millisecond
+	defer func() { sinkRetryDelay = delay }()
+
+	ts := newTestWebhookServer(http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+	defer ts.Close()
+
+	ns, err := newNotifySink(SinkConfig{Type: SinkWebhook, URL: ts.URL})
+	require.NoError(t, err)
+
+	repo := &MockRepository{}
+
+	deliver(context.Background(), repo, ns, Notification{ID: "n-1"})
+
+	assert.Equal(t, 3, ts.count())
+	repo.AssertNotCalled(t, "AddDeadLetter", mock.Anything, mock.Anything)
+}
+
+func TestDeliver_DeadLetter(t *testing.T) {
+	delay, attempts := sinkRetryDelay, sinkAttempts
+	sinkRetryDelay, sinkAttempts = time.Millisecond, 3
+	defer func() { sinkRetryDelay, sinkAttempts = delay, attempts }()
+
+	cases := []struct {
+		status   int
+		attempts int
+	}{
+		{status: http.StatusInternalServerError, attempts: 3},
+		{status: http.StatusGone, attempts: 1},
+	}
+
+	for _, c := range cases {
+		ts := newTestWebhookServer(c.status)
+
+		sc := SinkConfig{Type: SinkWebhook, URL: ts.URL, Secret: "secret"}
+		ns, err := newNotifySink(sc)
+		require.NoError(t, err)
+
+		var dl DeadLetter
+		repo := &MockRepository{}
+		repo.On("AddDeadLetter", mock.Anything, mock.AnythingOfType("DeadLetter")).Return(nil).Run(func(args mock.Arguments) {
+			dl = args.Get(1).(DeadLetter)
+		})
+
+		notif := Notification{ID: "n-1", GroupID: "g-1", Event: EventError}
+		deliver(context.Background(), repo, ns, notif)
+
+		assert.Equal(t, c.attempts, ts.count())
+		assert.Equal(t, "webhook "+ts.URL, dl.Sink)
+		assert.Equal(t, "g-1", dl.GroupID)
+		assert.Equal(t, "n-1", dl.NotificationID)
+		assert.Equal(t, c.attempts, dl.Attempts)
+		assert.Contains(t, dl.Error, "webhook returned status")
+
+		var payload Notification
+		require.NoError(t, json.Unmarshal(dl.Payload, &payload))
+		assert.Equal(t, notif, payload)
+
+		ts.Close()
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"io"
 	"mime/multipart"
 	"net/textproto"
+	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 // MIMEType represents a MIME type.
@@ -15,15 +18,28 @@ const (
 	// MIMETypeShellScript is a shell script MIME type.
 	MIMETypeShellScript MIMEType = "text/x-shellscript"
 
+	// MIMETypeCloudConfig is a cloud-config YAML document MIME type.
+	MIMETypeCloudConfig MIMEType = "text/cloud-config"
+
+	// MIMETypeCloudBoothook is a cloud boothook MIME type.
+	MIMETypeCloudBoothook MIMEType = "text/cloud-boothook"
+
+	// MIMETypeIncludeURL is an include file MIME type. Each line is a URL to include.
+	MIMETypeIncludeURL MIMEType = "text/x-include-url"
+
 	// MIMETypeUnknown is an unknown MIME type.
 	MIMETypeUnknown MIMEType = ""
 )
 
+// CloudConfig is a parsed cloud-config document.
+type CloudConfig map[interface{}]interface{}
+
 // CloudInit represents a cloud init multipart data structure
 type CloudInit struct {
-	buf      *bytes.Buffer
-	envelope *multipart.Writer
-	boundary string
+	buf          *bytes.Buffer
+	envelope     *multipart.Writer
+	boundary     string
+	cloudConfigs []CloudConfig
 }
 
 // New creates an instance of CloudInit
@@ -38,6 +54,23 @@ func New() *CloudInit {
 	}
 }
 
+// DetectMIMEType returns the MIME type of user data using the same start markers as
+// cloud-init.
+func DetectMIMEType(contents string) MIMEType {
+	switch {
+	case strings.HasPrefix(contents, "#cloud-config"):
+		return MIMETypeCloudConfig
+	case strings.HasPrefix(contents, "#cloud-boothook"):
+		return MIMETypeCloudBoothook
+	case strings.HasPrefix(contents, "#include"):
+		return MIMETypeIncludeURL
+	case strings.HasPrefix(contents, "#!"):
+		return MIMETypeShellScript
+	default:
+		return MIMETypeUnknown
+	}
+}
+
 // AddPart adds a part to the cloud init payload.
 func (c *CloudInit) AddPart(mimeType MIMEType, name, contents string) error {
 	mh := textproto.MIMEHeader{}
@@ -60,8 +93,38 @@ func (c *CloudInit) AddPart(mimeType MIMEType, name, contents string) error {
 	return nil
 }
 
-// Close closes the envelope.
+// AddUserData adds user data to the cloud init payload using its detected MIME type.
+// Cloud-config documents are validated and merged in the order they were added into
+// a single part when the payload is closed.
+func (c *CloudInit) AddUserData(name, contents string) error {
+	mimeType := DetectMIMEType(contents)
+	if mimeType != MIMETypeCloudConfig {
+		return c.AddPart(mimeType, name, contents)
+	}
+
+	cc, err := ParseCloudConfig(contents)
+	if err != nil {
+		return fmt.Errorf("%s is not valid cloud-config: %v", name, err)
+	}
+
+	c.cloudConfigs = append(c.cloudConfigs, cc)
+	return nil
+}
+
+// Close adds the merged cloud-config part and closes the envelope.
 func (c *CloudInit) Close() error {
+	if len(c.cloudConfigs) > 0 {
+		merged := MergeCloudConfigs(c.cloudConfigs...)
+		out, err := merged.String()
+		if err != nil {
+			return err
+		}
+
+		if err := c.AddPart(MIMETypeCloudConfig, "cloud-config.txt", out); err != nil {
+			return err
+		}
+	}
+
 	return c.envelope.Close()
 }
 
@@ -78,3 +141,62 @@ func (c *CloudInit) String() string {
 	out.Write(c.buf.Bytes())
 	return out.String()
 }
+
+// ParseCloudConfig parses a cloud-config document.
+func ParseCloudConfig(contents string) (CloudConfig, error) {
+	// nested mappings are decoded with the type of the outer mapping, so decode into an
+	// unnamed map to keep them comparable with mappings built while merging.
+	m := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(contents), &m); err != nil {
+		return nil, err
+	}
+
+	return CloudConfig(m), nil
+}
+
+// MergeCloudConfigs merges cloud-config documents. Later documents take precedence:
+// mappings are merged, lists are appended to, and other values are replaced.
+func MergeCloudConfigs(configs ...CloudConfig) CloudConfig {
+	merged := CloudConfig{}
+	for _, cc := range configs {
+		mergeMaps(merged, cc)
+	}
+
+	return merged
+}
+
+func mergeMaps(dst, src map[interface{}]interface{}) {
+	for k, v := range src {
+		switch sv := v.(type) {
+		case map[interface{}]interface{}:
+			if dv, ok := dst[k].(map[interface{}]interface{}); ok {
+				mergeMaps(dv, sv)
+				continue
+			}
+
+			// copy so later merges don't modify src
+			m := map[interface{}]interface{}{}
+			mergeMaps(m, sv)
+			dst[k] = m
+			continue
+
+		case []interface{}:
+			if dv, ok := dst[k].([]interface{}); ok {
+				dst[k] = append(append([]interface{}{}, dv...), sv...)
+				continue
+			}
+		}
+
+		dst[k] = v
+	}
+}
+
+// String returns the cloud-config document.
+func (cc CloudConfig) String() (string, error) {
+	b, err := yaml.Marshal(map[interface{}]interface{}(cc))
+	if err != nil {
+		return "", err
+	}
+
+	return "#cloud-config\n" + string(b), nil
+}
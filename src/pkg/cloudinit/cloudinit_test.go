@@ -1,9 +1,14 @@
 package cloudinit
 
 import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCloudInit(t *testing.T) {
@@ -18,3 +23,124 @@ func TestCloudInit(t *testing.T) {
 	assert.NotEmpty(t, out)
 
 }
+
+type part struct {
+	contentType string
+	filename    string
+	body        string
+}
+
+func readParts(t *testing.T, out string) []part {
+	header, body := splitMessage(out)
+	mediaType, params, err := mime.ParseMediaType(header)
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := []part{}
+	mr := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+
+		b, err := ioutil.ReadAll(p)
+		require.NoError(t, err)
+
+		parts = append(parts, part{
+			contentType: p.Header.Get("Content-Type"),
+			filename:    p.FileName(),
+			body:        string(b),
+		})
+	}
+
+	return parts
+}
+
+func splitMessage(out string) (string, string) {
+	lines := strings.SplitN(out, "\n\n", 2)
+	contentType := strings.TrimPrefix(strings.SplitN(lines[0], "\n", 2)[0], "Content-Type: ")
+	return contentType, lines[1]
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	cases := []struct {
+		contents string
+		expected MIMEType
+	}{
+		{contents: "#cloud-config\npackages: [nginx]", expected: MIMETypeCloudConfig},
+		{contents: "#!/bin/bash\necho hi", expected: MIMETypeShellScript},
+		{contents: "#cloud-boothook\necho hi", expected: MIMETypeCloudBoothook},
+		{contents: "#include\nhttps://example.com/ud", expected: MIMETypeIncludeURL},
+		{contents: "hello", expected: MIMETypeUnknown},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, DetectMIMEType(c.contents), c.contents)
+	}
+}
+
+func TestCloudInit_AddUserData(t *testing.T) {
+	ci := New()
+	require.NoError(t, ci.AddUserData("agent.txt", "#!/bin/bash\necho agent"))
+	require.NoError(t, ci.AddUserData("system.txt", "#cloud-config\npackages: [curl]\nwrite_files:\n- path: /etc/a\n  content: a\n"))
+	require.NoError(t, ci.AddUserData("boothook.txt", "#cloud-boothook\necho boot"))
+	require.NoError(t, ci.AddUserData("include.txt", "#include\nhttps://example.com/ud"))
+	require.NoError(t, ci.AddUserData("template.txt", "#cloud-config\npackages: [nginx]\ntimezone: UTC\n"))
+	require.NoError(t, ci.AddUserData("group.txt", "#cloud-config\ntimezone: America/New_York\n"))
+	require.NoError(t, ci.Close())
+
+	parts := readParts(t, ci.String())
+	require.Len(t, parts, 4)
+
+	assert.Equal(t, part{contentType: string(MIMETypeShellScript), filename: "agent.txt", body: "#!/bin/bash\necho agent"}, parts[0])
+	assert.Equal(t, part{contentType: string(MIMETypeCloudBoothook), filename: "boothook.txt", body: "#cloud-boothook\necho boot"}, parts[1])
+	assert.Equal(t, part{contentType: string(MIMETypeIncludeURL), filename: "include.txt", body: "#include\nhttps://example.com/ud"}, parts[2])
+
+	cc := parts[3]
+	assert.Equal(t, string(MIMETypeCloudConfig), cc.contentType)
+	assert.Equal(t, "cloud-config.txt", cc.filename)
+	assert.Equal(t, `#cloud-config
+packages:
+- curl
+- nginx
+timezone: America/New_York
+write_files:
+- content: a
+  path: /etc/a
+`, cc.body)
+}
+
+func TestCloudInit_AddUserData_InvalidCloudConfig(t *testing.T) {
+	ci := New()
+	err := ci.AddUserData("template.txt", "#cloud-config\npackages: [nginx\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template.txt")
+
+	err = ci.AddUserData("template.txt", "#cloud-config\n- a list\n")
+	require.Error(t, err)
+}
+
+func TestMergeCloudConfigs(t *testing.T) {
+	a, err := ParseCloudConfig("#cloud-config\nusers:\n  web: {shell: /bin/sh}\nruncmd: [a]\n")
+	require.NoError(t, err)
+	b, err := ParseCloudConfig("#cloud-config\nusers:\n  web: {groups: sudo}\nruncmd: [b]\n")
+	require.NoError(t, err)
+
+	merged := MergeCloudConfigs(a, b)
+	out, err := merged.String()
+	require.NoError(t, err)
+	assert.Equal(t, `#cloud-config
+runcmd:
+- a
+- b
+users:
+  web:
+    groups: sudo
+    shell: /bin/sh
+`, out)
+
+	// merging doesn't modify the source documents
+	assert.Equal(t, []interface{}{"a"}, a["runcmd"])
+	assert.Len(t, a["users"].(map[interface{}]interface{})["web"], 1)
+}
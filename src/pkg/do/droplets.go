@@ -53,6 +53,7 @@ type DropletsService interface {
 	ListByTag(string) (Droplets, error)
 	Get(int) (*Droplet, error)
 	Create(*godo.DropletCreateRequest, bool) (*Droplet, error)
+	CreateWithOptions(*DropletCreateRequest, bool) (*Droplet, error)
 	CreateMultiple(*godo.DropletMultiCreateRequest) (Droplets, error)
 	Delete(int) error
 	DeleteByTag(string) error
@@ -150,22 +151,64 @@ func (ds *dropletsService) Create(dcr *godo.DropletCreateRequest, wait bool) (*D
 	}
 
 	if wait {
-		var action *godo.LinkAction
-		for _, a := range resp.Links.Actions {
-			if a.Rel == "create" {
-				action = &a
-				break
-			}
+		return ds.waitForCreate(d, resp.Links)
+	}
+
+	return &Droplet{Droplet: d}, nil
+}
+
+// DropletCreateRequest is a request to create a droplet. It adds options to
+// godo.DropletCreateRequest which godo doesn't support yet.
+type DropletCreateRequest struct {
+	godo.DropletCreateRequest
+	Monitoring bool     `json:"monitoring"`
+	Tags       []string `json:"tags,omitempty"`
+	Volumes    []string `json:"volumes,omitempty"`
+}
+
+type dropletRoot struct {
+	Droplet *godo.Droplet `json:"droplet"`
+	Links   *godo.Links   `json:"links,omitempty"`
+}
+
+func (ds *dropletsService) CreateWithOptions(dcr *DropletCreateRequest, wait bool) (*Droplet, error) {
+	req, err := ds.client.NewRequest("POST", "v2/droplets", dcr)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(dropletRoot)
+	if _, err := ds.client.Do(req, root); err != nil {
+		return nil, err
+	}
+
+	if wait {
+		return ds.waitForCreate(root.Droplet, root.Links)
+	}
+
+	return &Droplet{Droplet: root.Droplet}, nil
+}
+
+func (ds *dropletsService) waitForCreate(d *godo.Droplet, links *godo.Links) (*Droplet, error) {
+	if links == nil {
+		return &Droplet{Droplet: d}, nil
+	}
+
+	var action *godo.LinkAction
+	for _, a := range links.Actions {
+		if a.Rel == "create" {
+			action = &a
+			break
 		}
+	}
 
-		if action != nil {
-			_ = util.WaitForActive(ds.client, action.HREF)
-			doDroplet, err := ds.Get(d.ID)
-			if err != nil {
-				return nil, err
-			}
-			d = doDroplet.Droplet
+	if action != nil {
+		_ = util.WaitForActive(ds.client, action.HREF)
+		doDroplet, err := ds.Get(d.ID)
+		if err != nil {
+			return nil, err
 		}
+		d = doDroplet.Droplet
 	}
 
 	return &Droplet{Droplet: d}, nil
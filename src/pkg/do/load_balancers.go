@@ -0,0 +1,145 @@
+package do
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+// HealthCheck is the health check a load balancer runs against its droplets.
+type HealthCheck struct {
+	Protocol               string `json:"protocol"`
+	Port                   int    `json:"port"`
+	Path                   string `json:"path"`
+	CheckIntervalSeconds   int    `json:"check_interval_seconds"`
+	ResponseTimeoutSeconds int    `json:"response_timeout_seconds"`
+	HealthyThreshold       int    `json:"healthy_threshold"`
+	UnhealthyThreshold     int    `json:"unhealthy_threshold"`
+}
+
+// LoadBalancer is a DigitalOcean load balancer.
+type LoadBalancer struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	IP          string       `json:"ip"`
+	Status      string       `json:"status"`
+	Region      *godo.Region `json:"region"`
+	HealthCheck *HealthCheck `json:"health_check"`
+	DropletIDs  []int        `json:"droplet_ids"`
+	Tag         string       `json:"tag"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// LoadBalancers is a slice of LoadBalancer.
+type LoadBalancers []LoadBalancer
+
+// LoadBalancersService is an interface for interacting with DigitalOcean's load balancer api.
+type LoadBalancersService interface {
+	List() (LoadBalancers, error)
+	Get(string) (*LoadBalancer, error)
+	AddDroplets(string, []int) error
+	RemoveDroplets(string, []int) error
+}
+
+type loadBalancersService struct {
+	client *godo.Client
+}
+
+var _ LoadBalancersService = (*loadBalancersService)(nil)
+
+// NewLoadBalancersService builds a LoadBalancersService instance. godo doesn't support
+// load balancers yet, so requests are built with the godo client directly.
+func NewLoadBalancersService(godoClient *godo.Client) LoadBalancersService {
+	return &loadBalancersService{
+		client: godoClient,
+	}
+}
+
+type loadBalancerRoot struct {
+	LoadBalancer *LoadBalancer `json:"load_balancer"`
+}
+
+type loadBalancersRoot struct {
+	LoadBalancers []LoadBalancer `json:"load_balancers"`
+	Links         *godo.Links    `json:"links"`
+}
+
+type loadBalancerDropletsRequest struct {
+	DropletIDs []int `json:"droplet_ids"`
+}
+
+func (lbs *loadBalancersService) List() (LoadBalancers, error) {
+	f := func(opt *godo.ListOptions) ([]interface{}, *godo.Response, error) {
+		v := url.Values{}
+		v.Set("page", fmt.Sprint(opt.Page))
+		v.Set("per_page", fmt.Sprint(opt.PerPage))
+
+		req, err := lbs.client.NewRequest("GET", "v2/load_balancers?"+v.Encode(), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		root := new(loadBalancersRoot)
+		resp, err := lbs.client.Do(req, root)
+		if err != nil {
+			return nil, nil, err
+		}
+		if l := root.Links; l != nil {
+			resp.Links = l
+		}
+
+		si := make([]interface{}, len(root.LoadBalancers))
+		for i := range root.LoadBalancers {
+			si[i] = root.LoadBalancers[i]
+		}
+
+		return si, resp, err
+	}
+
+	si, err := PaginateResp(f)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(LoadBalancers, len(si))
+	for i := range si {
+		list[i] = si[i].(LoadBalancer)
+	}
+
+	return list, nil
+}
+
+func (lbs *loadBalancersService) Get(id string) (*LoadBalancer, error) {
+	req, err := lbs.client.NewRequest("GET", "v2/load_balancers/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(loadBalancerRoot)
+	if _, err := lbs.client.Do(req, root); err != nil {
+		return nil, err
+	}
+
+	return root.LoadBalancer, nil
+}
+
+func (lbs *loadBalancersService) AddDroplets(id string, dropletIDs []int) error {
+	return lbs.droplets("POST", id, dropletIDs)
+}
+
+func (lbs *loadBalancersService) RemoveDroplets(id string, dropletIDs []int) error {
+	return lbs.droplets("DELETE", id, dropletIDs)
+}
+
+func (lbs *loadBalancersService) droplets(method, id string, dropletIDs []int) error {
+	body := &loadBalancerDropletsRequest{DropletIDs: dropletIDs}
+	req, err := lbs.client.NewRequest(method, "v2/load_balancers/"+id+"/droplets", body)
+	if err != nil {
+		return err
+	}
+
+	_, err = lbs.client.Do(req, nil)
+	return err
+}
@@ -45,6 +45,27 @@ func (_m *DropletsService) List() (do.Droplets, error) {
 	return r0, r1
 }
 
+// ListByTag provides a mock function with given fields: _a0
+func (_m *DropletsService) ListByTag(_a0 string) (do.Droplets, error) {
+	ret := _m.Called(_a0)
+
+	var r0 do.Droplets
+	if rf, ok := ret.Get(0).(func(string) do.Droplets); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(do.Droplets)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: _a0
 func (_m *DropletsService) Get(_a0 int) (*do.Droplet, error) {
 	ret := _m.Called(_a0)
@@ -91,6 +112,29 @@ func (_m *DropletsService) Create(_a0 *godo.DropletCreateRequest, _a1 bool) (*do
 	return r0, r1
 }
 
+// CreateWithOptions provides a mock function with given fields: _a0, _a1
+func (_m *DropletsService) CreateWithOptions(_a0 *do.DropletCreateRequest, _a1 bool) (*do.Droplet, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *do.Droplet
+	if rf, ok := ret.Get(0).(func(*do.DropletCreateRequest, bool) *do.Droplet); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*do.Droplet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*do.DropletCreateRequest, bool) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateMultiple provides a mock function with given fields: _a0
 func (_m *DropletsService) CreateMultiple(_a0 *godo.DropletMultiCreateRequest) (do.Droplets, error) {
 	ret := _m.Called(_a0)
@@ -126,6 +170,20 @@ func (_m *DropletsService) Delete(_a0 int) error {
 	return r0
 }
 
+// DeleteByTag provides a mock function with given fields: _a0
+func (_m *DropletsService) DeleteByTag(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Kernels provides a mock function with given fields: _a0
 func (_m *DropletsService) Kernels(_a0 int) (do.Kernels, error) {
 	ret := _m.Called(_a0)
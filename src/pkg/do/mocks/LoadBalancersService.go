@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mocks
+
+import (
+	"pkg/do"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoadBalancersService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields:
+func (_m *LoadBalancersService) List() (do.LoadBalancers, error) {
+	ret := _m.Called()
+
+	var r0 do.LoadBalancers
+	if rf, ok := ret.Get(0).(func() do.LoadBalancers); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(do.LoadBalancers)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: _a0
+func (_m *LoadBalancersService) Get(_a0 string) (*do.LoadBalancer, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *do.LoadBalancer
+	if rf, ok := ret.Get(0).(func(string) *do.LoadBalancer); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*do.LoadBalancer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddDroplets provides a mock function with given fields: _a0, _a1
+func (_m *LoadBalancersService) AddDroplets(_a0 string, _a1 []int) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveDroplets provides a mock function with given fields: _a0, _a1
+func (_m *LoadBalancersService) RemoveDroplets(_a0 string, _a1 []int) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
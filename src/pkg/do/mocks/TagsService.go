@@ -0,0 +1,148 @@
+/*
+Copyright 2016 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mocks
+
+import (
+	"pkg/do"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/mock"
+)
+
+type TagsService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields:
+func (_m *TagsService) List() (do.Tags, error) {
+	ret := _m.Called()
+
+	var r0 do.Tags
+	if rf, ok := ret.Get(0).(func() do.Tags); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(do.Tags)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: name
+func (_m *TagsService) Get(name string) (*do.Tag, error) {
+	ret := _m.Called(name)
+
+	var r0 *do.Tag
+	if rf, ok := ret.Get(0).(func(string) *do.Tag); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*do.Tag)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: tcr
+func (_m *TagsService) Create(tcr *godo.TagCreateRequest) (*do.Tag, error) {
+	ret := _m.Called(tcr)
+
+	var r0 *do.Tag
+	if rf, ok := ret.Get(0).(func(*godo.TagCreateRequest) *do.Tag); ok {
+		r0 = rf(tcr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*do.Tag)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*godo.TagCreateRequest) error); ok {
+		r1 = rf(tcr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: name, tur
+func (_m *TagsService) Update(name string, tur *godo.TagUpdateRequest) error {
+	ret := _m.Called(name, tur)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *godo.TagUpdateRequest) error); ok {
+		r0 = rf(name, tur)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: name
+func (_m *TagsService) Delete(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TagResources provides a mock function with given fields: name, trr
+func (_m *TagsService) TagResources(name string, trr *godo.TagResourcesRequest) error {
+	ret := _m.Called(name, trr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *godo.TagResourcesRequest) error); ok {
+		r0 = rf(name, trr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UntagResources provides a mock function with given fields: name, urr
+func (_m *TagsService) UntagResources(name string, urr *godo.UntagResourcesRequest) error {
+	ret := _m.Called(name, urr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *godo.UntagResourcesRequest) error); ok {
+		r0 = rf(name, urr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
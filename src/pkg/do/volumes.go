@@ -0,0 +1,141 @@
+package do
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+// Volume is a DigitalOcean block storage volume.
+type Volume struct {
+	ID            string       `json:"id"`
+	Region        *godo.Region `json:"region"`
+	Name          string       `json:"name"`
+	SizeGigabytes int64        `json:"size_gigabytes"`
+	Description   string       `json:"description"`
+	DropletIDs    []int        `json:"droplet_ids"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// Volumes is a slice of Volume.
+type Volumes []Volume
+
+// VolumeCreateRequest is a request to create a block storage volume.
+type VolumeCreateRequest struct {
+	Region        string `json:"region"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	SizeGigabytes int64  `json:"size_gigabytes"`
+}
+
+// VolumesService is an interface for interacting with DigitalOcean's block storage api.
+type VolumesService interface {
+	List() (Volumes, error)
+	Get(string) (*Volume, error)
+	Create(*VolumeCreateRequest) (*Volume, error)
+	Delete(string) error
+}
+
+type volumesService struct {
+	client *godo.Client
+}
+
+var _ VolumesService = (*volumesService)(nil)
+
+// NewVolumesService builds a VolumesService instance. godo doesn't support block
+// storage yet, so requests are built with the godo client directly.
+func NewVolumesService(godoClient *godo.Client) VolumesService {
+	return &volumesService{
+		client: godoClient,
+	}
+}
+
+type volumeRoot struct {
+	Volume *Volume `json:"volume"`
+}
+
+type volumesRoot struct {
+	Volumes []Volume    `json:"volumes"`
+	Links   *godo.Links `json:"links"`
+}
+
+func (vs *volumesService) List() (Volumes, error) {
+	f := func(opt *godo.ListOptions) ([]interface{}, *godo.Response, error) {
+		v := url.Values{}
+		v.Set("page", fmt.Sprint(opt.Page))
+		v.Set("per_page", fmt.Sprint(opt.PerPage))
+
+		req, err := vs.client.NewRequest("GET", "v2/volumes?"+v.Encode(), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		root := new(volumesRoot)
+		resp, err := vs.client.Do(req, root)
+		if err != nil {
+			return nil, nil, err
+		}
+		if l := root.Links; l != nil {
+			resp.Links = l
+		}
+
+		si := make([]interface{}, len(root.Volumes))
+		for i := range root.Volumes {
+			si[i] = root.Volumes[i]
+		}
+
+		return si, resp, err
+	}
+
+	si, err := PaginateResp(f)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(Volumes, len(si))
+	for i := range si {
+		list[i] = si[i].(Volume)
+	}
+
+	return list, nil
+}
+
+func (vs *volumesService) Get(id string) (*Volume, error) {
+	req, err := vs.client.NewRequest("GET", "v2/volumes/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(volumeRoot)
+	if _, err := vs.client.Do(req, root); err != nil {
+		return nil, err
+	}
+
+	return root.Volume, nil
+}
+
+func (vs *volumesService) Create(vcr *VolumeCreateRequest) (*Volume, error) {
+	req, err := vs.client.NewRequest("POST", "v2/volumes", vcr)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(volumeRoot)
+	if _, err := vs.client.Do(req, root); err != nil {
+		return nil, err
+	}
+
+	return root.Volume, nil
+}
+
+func (vs *volumesService) Delete(id string) error {
+	req, err := vs.client.NewRequest("DELETE", "v2/volumes/"+id, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = vs.client.Do(req, nil)
+	return err
+}
@@ -9,12 +9,18 @@ import (
 
 // Client is our interface to digitalocean.
 type Client struct {
-	TagsService     do.TagsService
-	DropletsService do.DropletsService
-	SizesService    do.SizesService
-	RegionsService  do.RegionsService
-	KeysService     do.KeysService
-	AccountsService do.AccountService
+	TagsService              do.TagsService
+	DropletsService          do.DropletsService
+	LoadBalancersService     do.LoadBalancersService
+	FloatingIPsService       do.FloatingIPsService
+	FloatingIPActionsService do.FloatingIPActionsService
+	DomainsService           do.DomainsService
+	SizesService             do.SizesService
+	RegionsService           do.RegionsService
+	KeysService              do.KeysService
+	ImagesService            do.ImagesService
+	VolumesService           do.VolumesService
+	AccountsService          do.AccountService
 }
 
 type tokenSource struct {
@@ -40,12 +46,18 @@ func New(pat string) *Client {
 	godoClient := godo.NewClient(oc)
 
 	dc := &Client{
-		DropletsService: do.NewDropletsService(godoClient),
-		TagsService:     do.NewTagsService(godoClient),
-		SizesService:    do.NewSizesService(godoClient),
-		RegionsService:  do.NewRegionsService(godoClient),
-		KeysService:     do.NewKeysService(godoClient),
-		AccountsService: do.NewAccountService(godoClient),
+		DropletsService:          do.NewDropletsService(godoClient),
+		LoadBalancersService:     do.NewLoadBalancersService(godoClient),
+		FloatingIPsService:       do.NewFloatingIPsService(godoClient),
+		FloatingIPActionsService: do.NewFloatingIPActionsService(godoClient),
+		DomainsService:           do.NewDomainsService(godoClient),
+		TagsService:              do.NewTagsService(godoClient),
+		SizesService:             do.NewSizesService(godoClient),
+		RegionsService:           do.NewRegionsService(godoClient),
+		KeysService:              do.NewKeysService(godoClient),
+		ImagesService:            do.NewImagesService(godoClient),
+		VolumesService:           do.NewVolumesService(godoClient),
+		AccountsService:          do.NewAccountService(godoClient),
 	}
 
 	return dc